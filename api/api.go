@@ -337,18 +337,35 @@ func (a *API) ResolveURI(ctx context.Context, uri *URI, credentials string) (sto
 // to resolve basePath to content using FileStore retrieve
 // it returns a section reader, mimeType, status, the key of the actual content and an error
 func (a *API) Get(ctx context.Context, decrypt DecryptFunc, manifestAddr storage.Address, path string) (reader storage.LazySectionReader, mimeType string, status int, contentAddr storage.Address, err error) {
+	reader, entry, status, contentAddr, err := a.GetEntry(ctx, decrypt, manifestAddr, path)
+	if entry != nil {
+		mimeType = entry.ContentType
+	}
+	return reader, mimeType, status, contentAddr, err
+}
+
+// GetEntry works like Get, but returns the manifest entry the content was
+// resolved from instead of only its mime type, so that callers have access
+// to entry metadata like the modification time.
+// The returned entry is nil if the content could not be resolved.
+// If the content was resolved through a feed, the Feed field of the returned
+// entry is set to that feed, as the content changes with feed updates.
+func (a *API) GetEntry(ctx context.Context, decrypt DecryptFunc, manifestAddr storage.Address, path string) (reader storage.LazySectionReader, manifestEntry *ManifestEntry, status int, contentAddr storage.Address, err error) {
 	log.Debug("api.get", "key", manifestAddr, "path", path)
 	apiGetCount.Inc(1)
 	trie, err := loadManifest(ctx, a.fileStore, manifestAddr, nil, decrypt)
 	if err != nil {
 		apiGetNotFound.Inc(1)
 		status = http.StatusNotFound
-		return nil, "", http.StatusNotFound, nil, err
+		return nil, nil, http.StatusNotFound, nil, err
 	}
 
 	log.Debug("trie getting entry", "key", manifestAddr, "path", path)
 	entry, _ := trie.getEntry(path)
 
+	// feed the content was resolved through, if any
+	var resolvedFeed *feed.Feed
+
 	if entry != nil {
 		log.Debug("trie got entry", "key", manifestAddr, "path", path, "entry.Hash", entry.Hash)
 
@@ -356,22 +373,22 @@ func (a *API) Get(ctx context.Context, decrypt DecryptFunc, manifestAddr storage
 			log.Debug("entry is manifest", "key", manifestAddr, "new key", entry.Hash)
 			adr, err := hex.DecodeString(entry.Hash)
 			if err != nil {
-				return nil, "", 0, nil, err
+				return nil, nil, 0, nil, err
 			}
-			return a.Get(ctx, decrypt, adr, entry.Path)
+			return a.GetEntry(ctx, decrypt, adr, entry.Path)
 		}
 
 		// we need to do some extra work if this is a Swarm feed manifest
 		if entry.ContentType == FeedContentType {
 			if entry.Feed == nil {
-				return reader, mimeType, status, nil, fmt.Errorf("Cannot decode Feed in manifest")
+				return reader, nil, status, nil, fmt.Errorf("Cannot decode Feed in manifest")
 			}
 			_, err := a.feed.Lookup(ctx, feed.NewQueryLatest(entry.Feed, lookup.NoClue))
 			if err != nil {
 				apiGetNotFound.Inc(1)
 				status = http.StatusNotFound
 				log.Debug(fmt.Sprintf("get feed update content error: %v", err))
-				return reader, mimeType, status, nil, err
+				return reader, nil, status, nil, err
 			}
 			// get the data of the update
 			_, contentAddr, err := a.feed.GetContent(entry.Feed)
@@ -379,7 +396,7 @@ func (a *API) Get(ctx context.Context, decrypt DecryptFunc, manifestAddr storage
 				apiGetNotFound.Inc(1)
 				status = http.StatusNotFound
 				log.Warn(fmt.Sprintf("get feed update content error: %v", err))
-				return reader, mimeType, status, nil, err
+				return reader, nil, status, nil, err
 			}
 
 			// extract content hash
@@ -388,8 +405,9 @@ func (a *API) Get(ctx context.Context, decrypt DecryptFunc, manifestAddr storage
 				status = http.StatusUnprocessableEntity
 				errorMessage := fmt.Sprintf("invalid swarm hash in feed update. Expected %d bytes. Got %d", storage.AddressLength, len(contentAddr))
 				log.Warn(errorMessage)
-				return reader, mimeType, status, nil, errors.New(errorMessage)
+				return reader, nil, status, nil, errors.New(errorMessage)
 			}
+			resolvedFeed = entry.Feed
 			manifestAddr = storage.Address(contentAddr)
 			log.Trace("feed update contains swarm hash", "key", manifestAddr)
 
@@ -399,7 +417,7 @@ func (a *API) Get(ctx context.Context, decrypt DecryptFunc, manifestAddr storage
 				apiGetNotFound.Inc(1)
 				status = http.StatusNotFound
 				log.Warn(fmt.Sprintf("loadManifestTrie (feed update) error: %v", err))
-				return reader, mimeType, status, nil, err
+				return reader, nil, status, nil, err
 			}
 
 			// finally, get the manifest entry
@@ -410,7 +428,7 @@ func (a *API) Get(ctx context.Context, decrypt DecryptFunc, manifestAddr storage
 				apiGetNotFound.Inc(1)
 				err = fmt.Errorf("manifest (feed update) entry for '%s' not found", path)
 				log.Trace("manifest (feed update) entry not found", "key", manifestAddr, "path", path)
-				return reader, mimeType, status, nil, err
+				return reader, nil, status, nil, err
 			}
 		}
 
@@ -418,12 +436,17 @@ func (a *API) Get(ctx context.Context, decrypt DecryptFunc, manifestAddr storage
 		// get the key the manifest entry points to and serve it if it's unambiguous
 		contentAddr = common.Hex2Bytes(entry.Hash)
 		status = entry.Status
+		// copy the entry so that setting the feed does not modify the manifest
+		e := entry.ManifestEntry
+		if resolvedFeed != nil {
+			e.Feed = resolvedFeed
+		}
+		manifestEntry = &e
 		if status == http.StatusMultipleChoices {
 			apiGetHTTP300.Inc(1)
-			return nil, entry.ContentType, status, contentAddr, err
+			return nil, manifestEntry, status, contentAddr, err
 		}
-		mimeType = entry.ContentType
-		log.Debug("content lookup key", "key", contentAddr, "mimetype", mimeType)
+		log.Debug("content lookup key", "key", contentAddr, "mimetype", entry.ContentType)
 		reader, _ = a.fileStore.Retrieve(ctx, contentAddr)
 	} else {
 		// no entry found
@@ -930,6 +953,13 @@ func (a *API) FeedsLookup(ctx context.Context, query *feed.Query) ([]byte, error
 	return data, nil
 }
 
+// FeedsHistory returns updates of the feed with times between from and to,
+// ordered from the oldest to the newest, at most limit newest ones if limit
+// is greater than 0
+func (a *API) FeedsHistory(ctx context.Context, fd *feed.Feed, from, to uint64, limit int) ([]*feed.HistoryEntry, error) {
+	return a.feed.History(ctx, fd, from, to, limit)
+}
+
 // FeedsNewRequest creates a Request object to update a specific feed
 func (a *API) FeedsNewRequest(ctx context.Context, feed *feed.Feed) (*feed.Request, error) {
 	return a.feed.NewRequest(ctx, feed)
@@ -0,0 +1,54 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethersphere/swarm/storage"
+)
+
+var (
+	apiAuditProofCount  = metrics.NewRegisteredCounter("api/audit/proof/count", nil)
+	apiAuditProofFail   = metrics.NewRegisteredCounter("api/audit/proof/fail", nil)
+	apiAuditVerifyCount = metrics.NewRegisteredCounter("api/audit/verify/count", nil)
+	apiAuditVerifyFail  = metrics.NewRegisteredCounter("api/audit/verify/fail", nil)
+)
+
+// AuditProof returns the proof that the content with the root address is
+// stored locally, for a data chunk selected by the challenge.
+func (a *API) AuditProof(ctx context.Context, root storage.Address, challenge []byte) (*storage.AuditProof, error) {
+	apiAuditProofCount.Inc(1)
+	p, err := storage.NewAuditProof(ctx, a.fileStore.ChunkStore, root, challenge)
+	if err != nil {
+		apiAuditProofFail.Inc(1)
+		return nil, err
+	}
+	return p, nil
+}
+
+// VerifyAuditProof checks the proof, constructed by this or another node,
+// for the content with the root address.
+func (a *API) VerifyAuditProof(root storage.Address, p *storage.AuditProof) error {
+	apiAuditVerifyCount.Inc(1)
+	if err := storage.VerifyAuditProof(root, p); err != nil {
+		apiAuditVerifyFail.Inc(1)
+		return err
+	}
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -38,6 +39,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethersphere/swarm/api"
@@ -45,6 +47,7 @@ import (
 	"github.com/ethersphere/swarm/chunk"
 	"github.com/ethersphere/swarm/spancontext"
 	"github.com/ethersphere/swarm/storage/feed"
+	"github.com/ethersphere/swarm/storage/soc"
 	"github.com/pborman/uuid"
 )
 
@@ -215,6 +218,28 @@ func (c *Client) UploadDirectory(dir, defaultPath, manifest string, toEncrypt, t
 	return c.TarUpload(manifest, &DirectoryUploader{dir}, defaultPath, toEncrypt, toPin, anonymous)
 }
 
+// Delete removes the file with the given path from the swarm manifest with
+// the given hash, returning the resulting manifest hash
+func (c *Client) Delete(hash, path string) (string, error) {
+	req, err := http.NewRequest("DELETE", c.Gateway+"/bzz:/"+hash+"/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected HTTP status: %s: %s", res.Status, strings.TrimSpace(string(data)))
+	}
+	return string(data), nil
+}
+
 // DownloadDirectory downloads the files contained in a swarm manifest under
 // the given path into a local directory (existing files will be overwritten)
 func (c *Client) DownloadDirectory(hash, path, destDir, credentials string) error {
@@ -694,6 +719,86 @@ func (c *Client) TagByHash(hash string) (*chunk.Tag, error) {
 	return tag, err
 }
 
+// Prefetch starts retrieving all chunks of the content with the given hash
+// into the local store of the Swarm node and returns the uid of the tag that
+// reports the progress.
+func (c *Client) Prefetch(hash string) (uint32, error) {
+	res, err := c.httpClient.Post(c.Gateway+"/bzz-prefetch:/"+hash, "", nil)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusAccepted {
+		return 0, fmt.Errorf("unexpected HTTP status: %s", res.Status)
+	}
+	uid, err := strconv.ParseUint(res.Header.Get(swarmhttp.TagHeaderName), 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(uid), nil
+}
+
+// Export downloads a tar archive with all chunks of the content with the
+// given hash. The archive can be uploaded to a Swarm node with Import.
+// Reading the archive returns an error if the export is interrupted on
+// the node.
+func (c *Client) Export(hash string) (io.ReadCloser, error) {
+	res, err := c.httpClient.Get(c.Gateway + "/bzz-export:/" + hash)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("unexpected HTTP status: %s", res.Status)
+	}
+	return &exportReader{res: res}, nil
+}
+
+// exportReader reads the exported archive from the response body and
+// returns an error instead of io.EOF if the export was interrupted.
+type exportReader struct {
+	res *http.Response
+}
+
+func (r *exportReader) Read(p []byte) (n int, err error) {
+	n, err = r.res.Body.Read(p)
+	if err == io.EOF {
+		// trailers are available when the body is read
+		if e := r.res.Trailer.Get(swarmhttp.ExportErrorTrailerName); e != "" {
+			return n, fmt.Errorf("export interrupted: %s", e)
+		}
+	}
+	return n, err
+}
+
+func (r *exportReader) Close() error {
+	return r.res.Body.Close()
+}
+
+// Import uploads a tar archive created by Export to the Swarm node and
+// returns the number of imported chunks.
+func (c *Client) Import(r io.Reader) (int64, error) {
+	req, err := http.NewRequest("POST", c.Gateway+"/bzz-import:/", r)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-tar")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return 0, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected HTTP status: %s: %s", res.Status, strings.TrimSpace(string(data)))
+	}
+	return strconv.ParseInt(string(data), 10, 64)
+}
+
 // ErrNoFeedUpdatesFound is returned when Swarm cannot find updates of the given feed
 var ErrNoFeedUpdatesFound = errors.New("No updates found for this feed")
 
@@ -799,6 +904,48 @@ func (c *Client) queryFeed(query *feed.Query, manifestAddressOrDomain string, me
 	return res.Body, nil
 }
 
+// FeedHistory returns updates of the feed with times between from and to,
+// ordered from the oldest to the newest. If to is 0, updates up to the current
+// time are returned. If limit is 0, the default limit of the Swarm node is used.
+// The feed is referenced by fd or by manifestAddressOrDomain, if fd is nil.
+func (c *Client) FeedHistory(fd *feed.Feed, manifestAddressOrDomain string, from, to uint64, limit int) ([]*feed.HistoryEntry, error) {
+	URL, err := url.Parse(c.Gateway)
+	if err != nil {
+		return nil, err
+	}
+	URL.Path = "/bzz-feed:/" + manifestAddressOrDomain
+	values := URL.Query()
+	if fd != nil {
+		fd.AppendValues(values)
+	}
+	values.Set("history", "1")
+	if from != 0 {
+		values.Set("from", strconv.FormatUint(from, 10))
+	}
+	if to != 0 {
+		values.Set("to", strconv.FormatUint(to, 10))
+	}
+	if limit != 0 {
+		values.Set("limit", strconv.Itoa(limit))
+	}
+	URL.RawQuery = values.Encode()
+
+	res, err := c.httpClient.Get(URL.String())
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		data, _ := ioutil.ReadAll(res.Body)
+		return nil, fmt.Errorf("unexpected HTTP status: %s: %s", res.Status, strings.TrimSpace(string(data)))
+	}
+	var entries []*feed.HistoryEntry
+	if err := json.NewDecoder(res.Body).Decode(&entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
 // GetFeedRequest returns a structure that describes the referenced feed status
 // manifestAddressOrDomain is the address you obtained in CreateFeedWithManifest or an ENS domain whose Resolver
 // points to that address
@@ -822,6 +969,42 @@ func (c *Client) GetFeedRequest(query *feed.Query, manifestAddressOrDomain strin
 	return &metadata, nil
 }
 
+// UploadSOC signs the payload with the identifier as a single-owner chunk
+// and uploads it to the Swarm node. It returns the chunk address.
+func (c *Client) UploadSOC(id, payload []byte, signer feed.Signer) (string, error) {
+	ch, err := soc.NewChunk(id, payload, signer)
+	if err != nil {
+		return "", err
+	}
+	res, err := c.httpClient.Post(c.Gateway+"/bzz-soc:/", "application/octet-stream", bytes.NewReader(ch.Data()))
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected HTTP status: %s", res.Status)
+	}
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// DownloadSOC downloads the payload of the single-owner chunk
+// with the identifier of the owner.
+func (c *Client) DownloadSOC(owner common.Address, id []byte) ([]byte, error) {
+	res, err := c.httpClient.Get(c.Gateway + "/bzz-soc:/" + strings.TrimPrefix(owner.Hex(), "0x") + "/" + hex.EncodeToString(id))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status: %s", res.Status)
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
 func GetClientTrace(traceMsg, metricPrefix, ruid string, tn *time.Time) *httptrace.ClientTrace {
 	trace := &httptrace.ClientTrace{
 		GetConn: func(_ string) {
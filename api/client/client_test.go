@@ -18,27 +18,31 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethersphere/swarm/api"
 	swarmhttp "github.com/ethersphere/swarm/api/http"
+	"github.com/ethersphere/swarm/chunk"
 	chunktesting "github.com/ethersphere/swarm/chunk/testing"
 	"github.com/ethersphere/swarm/storage"
 	"github.com/ethersphere/swarm/storage/feed"
 	"github.com/ethersphere/swarm/storage/feed/lookup"
 	"github.com/ethersphere/swarm/storage/pin"
+	"github.com/ethersphere/swarm/storage/soc"
 	"github.com/ethersphere/swarm/testutil"
 )
 
 func serverFunc(api *api.API, pinAPI *pin.API) swarmhttp.TestServer {
-	return swarmhttp.NewServer(api, pinAPI, "")
+	return swarmhttp.NewServer(api, pinAPI, nil, "")
 }
 
 // TestClientUploadDownloadRaw test uploading and downloading raw data to swarm
@@ -421,6 +425,157 @@ func TestClientQueryTagByHash(t *testing.T) {
 	chunktesting.CheckTag(t, tagAPI, 1, 1, 0, 0, 0, 1)
 }
 
+// TestClientPrefetch tests that prefetch of content is started
+// and that a prefetch without a hash is rejected
+func TestClientPrefetch(t *testing.T) {
+	srv := swarmhttp.NewTestSwarmServer(t, serverFunc, nil, nil)
+	defer srv.Close()
+
+	data := testutil.RandomBytes(1, 100000)
+	client := NewClient(srv.URL)
+
+	hash, err := client.UploadRaw(bytes.NewReader(data), int64(len(data)), false, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, err := client.Prefetch(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uid == 0 {
+		t.Fatal("got zero tag uid")
+	}
+
+	if _, err := client.Prefetch(""); err == nil {
+		t.Fatal("expected error for prefetch without hash")
+	}
+}
+
+// TestClientSOC tests uploading and downloading single-owner chunks.
+func TestClientSOC(t *testing.T) {
+	srv := swarmhttp.NewTestSwarmServer(t, serverFunc, nil, nil)
+	defer srv.Close()
+
+	signer, err := newTestSigner()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(srv.URL)
+
+	id := make([]byte, soc.IDSize)
+	id[0] = 1
+	payload := []byte("single owner chunk payload")
+
+	addr, err := client.UploadSOC(id, payload, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := soc.Address(id, signer.Address()).Hex(); addr != want {
+		t.Fatalf("got address %s, want %s", addr, want)
+	}
+
+	got, err := client.DownloadSOC(signer.Address(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Fatalf("got payload %q, want %q", got, payload)
+	}
+
+	if _, err := client.DownloadSOC(signer.Address(), make([]byte, soc.IDSize)); err == nil {
+		t.Fatal("expected error for missing single-owner chunk")
+	}
+}
+
+// TestClientExportImport tests that content exported from one node can be
+// imported into another node and retrieved from it
+func TestClientExportImport(t *testing.T) {
+	srv := swarmhttp.NewTestSwarmServer(t, serverFunc, nil, nil)
+	defer srv.Close()
+
+	data := testutil.RandomBytes(1, 100000)
+	client := NewClient(srv.URL)
+
+	hash, err := client.UploadRaw(bytes.NewReader(data), int64(len(data)), false, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := client.Export(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	importSrv := swarmhttp.NewTestSwarmServer(t, serverFunc, nil, nil)
+	defer importSrv.Close()
+	importClient := NewClient(importSrv.URL)
+
+	count, err := importClient.Import(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 25 data chunks and one intermediate chunk
+	if count != 26 {
+		t.Fatalf("got %v imported chunks, want 26", count)
+	}
+
+	res, _, err := importClient.DownloadRaw(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Close()
+	got, err := ioutil.ReadAll(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("imported data does not match uploaded data")
+	}
+
+	if _, err := importClient.Import(bytes.NewReader([]byte("not an archive"))); err == nil {
+		t.Fatal("expected error importing invalid archive")
+	}
+}
+
+// TestClientExportInterrupted validates that reading an exported archive
+// returns an error if the export is interrupted on the node.
+func TestClientExportInterrupted(t *testing.T) {
+	srv := swarmhttp.NewTestSwarmServer(t, serverFunc, nil, nil)
+	defer srv.Close()
+
+	data := testutil.RandomBytes(1, 100000)
+	client := NewClient(srv.URL)
+
+	hash, err := client.UploadRaw(bytes.NewReader(data), int64(len(data)), false, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// remove the last data chunk referenced by the root chunk
+	store := srv.FileStore.ChunkStore
+	root, err := store.Get(context.Background(), chunk.ModeGetRequest, storage.Address(common.Hex2Bytes(hash)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := root.Data()[len(root.Data())-chunk.AddressLength:]
+	if err := store.Set(context.Background(), chunk.ModeSetRemove, last); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := client.Export(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	_, err = ioutil.ReadAll(archive)
+	if err == nil || !strings.Contains(err.Error(), "export interrupted") {
+		t.Fatalf("got error %v, want export interrupted", err)
+	}
+}
+
 func newTestSigner() (*feed.GenericSigner, error) {
 	privKey, err := crypto.HexToECDSA("deadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef")
 	if err != nil {
@@ -648,3 +803,84 @@ func TestClientCreateUpdateFeed(t *testing.T) {
 		t.Fatalf("Expected: %v, got %v", databytes, gotData)
 	}
 }
+
+// TestClientFeedHistory tests that all updates of a feed
+// are returned in the order they were published
+func TestClientFeedHistory(t *testing.T) {
+	signer, _ := newTestSigner()
+
+	srv := swarmhttp.NewTestSwarmServer(t, serverFunc, nil, nil)
+	client := NewClient(srv.URL)
+	defer srv.Close()
+
+	topic, _ := feed.NewTopic("history", nil)
+	updates := [][]byte{
+		[]byte("first"),
+		[]byte("second"),
+		[]byte("third"),
+	}
+
+	createRequest := feed.NewFirstRequest(topic)
+	createRequest.SetData(updates[0])
+	if err := createRequest.Sign(signer); err != nil {
+		t.Fatal(err)
+	}
+	manifestHash, err := client.CreateFeedWithManifest(createRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, data := range updates[1:] {
+		updateRequest, err := client.GetFeedRequest(nil, manifestHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		updateRequest.SetData(data)
+		if err := updateRequest.Sign(signer); err != nil {
+			t.Fatal(err)
+		}
+		if err := client.UpdateFeed(updateRequest); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fd := &feed.Feed{
+		Topic: topic,
+		User:  signer.Address(),
+	}
+	for _, tc := range []struct {
+		name     string
+		fd       *feed.Feed
+		manifest string
+	}{
+		{name: "manifest", manifest: manifestHash},
+		{name: "feed", fd: fd},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := client.FeedHistory(tc.fd, tc.manifest, 0, 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != len(updates) {
+				t.Fatalf("got %v updates, want %v", len(entries), len(updates))
+			}
+			for i, e := range entries {
+				if !bytes.Equal(e.Data, updates[i]) {
+					t.Errorf("update %v: got data %q, want %q", i, e.Data, updates[i])
+				}
+			}
+
+			entries, err = client.FeedHistory(tc.fd, tc.manifest, 0, 0, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || !bytes.Equal(entries[0].Data, updates[len(updates)-1]) {
+				t.Errorf("got %v updates with limit, want only the last one", len(entries))
+			}
+		})
+	}
+
+	if _, err := client.FeedHistory(fd, "", 0, 0, -1); err == nil {
+		t.Error("expected error for invalid limit")
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// FileState holds the file attributes that are compared
+// to detect changes in a directory.
+type FileState struct {
+	Size    int64
+	Mode    os.FileMode
+	ModTime time.Time
+}
+
+// DirectorySnapshot maps slash separated paths of files relative
+// to a directory to their state.
+type DirectorySnapshot map[string]FileState
+
+// SnapshotDirectory returns the state of all files in the directory tree.
+func SnapshotDirectory(dir string) (DirectorySnapshot, error) {
+	s := make(DirectorySnapshot)
+	err := filepath.Walk(dir, func(path string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if f.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		s[filepath.ToSlash(relPath)] = FileState{
+			Size:    f.Size(),
+			Mode:    f.Mode(),
+			ModTime: f.ModTime(),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Changes returns sorted paths of files that are added or modified and
+// of files that are removed since the previous snapshot.
+func (s DirectorySnapshot) Changes(prev DirectorySnapshot) (changed, removed []string) {
+	for path, state := range s {
+		p, ok := prev[path]
+		if !ok || p.Size != state.Size || p.Mode != state.Mode || !p.ModTime.Equal(state.ModTime) {
+			changed = append(changed, path)
+		}
+	}
+	for path := range prev {
+		if _, ok := s[path]; !ok {
+			removed = append(removed, path)
+		}
+	}
+	sort.Strings(changed)
+	sort.Strings(removed)
+	return changed, removed
+}
+
+// FilesUploader uploads files with the provided slash separated
+// paths relative to a directory
+type FilesUploader struct {
+	Dir   string
+	Paths []string
+}
+
+func (f *FilesUploader) Tag() string {
+	return filepath.Base(f.Dir)
+}
+
+// Upload performs the upload of the files
+func (f *FilesUploader) Upload(upload UploadFn) error {
+	for _, path := range f.Paths {
+		file, err := Open(filepath.Join(f.Dir, filepath.FromSlash(path)))
+		if err != nil {
+			return err
+		}
+		file.Path = path
+		if err := upload(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateDirectory patches the manifest with the given hash, uploading
+// only the changed files from the directory and removing the removed
+// ones, and returns the resulting manifest hash. Unchanged files keep
+// their manifest entries. If the file at defaultPath is changed, it is
+// also set as the root entry of the manifest.
+func (c *Client) UpdateDirectory(dir, defaultPath, manifest string, changed, removed []string, toEncrypt, toPin, anonymous bool) (hash string, err error) {
+	hash = manifest
+	if len(changed) > 0 {
+		var defaultPathChanged string
+		for _, path := range changed {
+			if path == defaultPath {
+				defaultPathChanged = defaultPath
+				break
+			}
+		}
+		hash, err = c.TarUpload(hash, &FilesUploader{Dir: dir, Paths: changed}, defaultPathChanged, toEncrypt, toPin, anonymous)
+		if err != nil {
+			return "", err
+		}
+	}
+	for _, path := range removed {
+		hash, err = c.Delete(hash, path)
+		if err != nil {
+			return "", err
+		}
+	}
+	return hash, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	swarmhttp "github.com/ethersphere/swarm/api/http"
+)
+
+// TestClientUpdateDirectory tests patching a manifest with the
+// changes in a directory detected by comparing its snapshots.
+func TestClientUpdateDirectory(t *testing.T) {
+	srv := swarmhttp.NewTestSwarmServer(t, serverFunc, nil, nil)
+	defer srv.Close()
+
+	dir := newTestDirectory(t)
+	defer os.RemoveAll(dir)
+
+	client := NewClient(srv.URL)
+	defaultPath := testDirFiles[0]
+	hash, err := client.UploadDirectory(dir, defaultPath, "", false, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev, err := SnapshotDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prev) != len(testDirFiles) {
+		t.Fatalf("got %v files in snapshot, want %v", len(prev), len(testDirFiles))
+	}
+
+	// modify the default path, add and remove files
+	write := func(path string, data []byte) {
+		p := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(testDirFiles[0], []byte("updated file1"))
+	write("dir5/file9.txt", []byte("dir5/file9.txt"))
+	if err := os.Remove(filepath.Join(dir, "dir1", "file3.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := SnapshotDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, removed := s.Changes(prev)
+	if want := []string{"dir5/file9.txt", "file1.txt"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("got changed files %v, want %v", changed, want)
+	}
+	if want := []string{"dir1/file3.txt"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("got removed files %v, want %v", removed, want)
+	}
+
+	newHash, err := client.UpdateDirectory(dir, defaultPath, hash, changed, removed, false, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newHash == hash {
+		t.Fatal("manifest not changed")
+	}
+
+	checkDownloadFile := func(path string, expected []byte) {
+		t.Helper()
+
+		file, err := client.Download(newHash, path)
+		if err != nil {
+			t.Fatalf("download %q: %v", path, err)
+		}
+		defer file.Close()
+		data, err := ioutil.ReadAll(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, expected) {
+			t.Fatalf("got %q for %q, want %q", data, path, expected)
+		}
+	}
+	checkDownloadFile("", []byte("updated file1"))
+	checkDownloadFile(testDirFiles[0], []byte("updated file1"))
+	checkDownloadFile("dir5/file9.txt", []byte("dir5/file9.txt"))
+	for _, file := range testDirFiles[1:] {
+		if file == "dir1/file3.txt" {
+			continue
+		}
+		checkDownloadFile(file, []byte(file))
+	}
+	if _, err := client.Download(newHash, "dir1/file3.txt"); err == nil {
+		t.Error("removed file is still in the manifest")
+	}
+
+	// no changes
+	changed, removed = s.Changes(s)
+	if len(changed) != 0 || len(removed) != 0 {
+		t.Errorf("got changes %v %v for the same snapshot", changed, removed)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -29,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethersphere/swarm/contracts/ens"
 	"github.com/ethersphere/swarm/network"
+	"github.com/ethersphere/swarm/network/timeouts"
 	"github.com/ethersphere/swarm/pss"
 	"github.com/ethersphere/swarm/storage"
 	"github.com/ethersphere/swarm/swap"
@@ -37,6 +39,9 @@ import (
 const (
 	DefaultHTTPListenAddr = "127.0.0.1"
 	DefaultHTTPPort       = "8500"
+	// DefaultHTTPCacheMaxAge is the max-age of HTTP responses for content
+	// addressed by a swarm hash, which can never change.
+	DefaultHTTPCacheMaxAge = 2147483648 * time.Second
 )
 
 // separate bzz directories
@@ -50,6 +55,24 @@ type Config struct {
 	DbCapacity    uint64
 	CacheCapacity uint
 	BaseKey       []byte
+	SlowStart     bool   // serve reads while the chunk DB gc size is rebuilt after an unclean shutdown
+	MinFreeSpace  uint64 // bytes of free disk space below which chunks from the network are rejected
+
+	// period of chunk DB proximity metrics updates, 0 for the default, negative disables them
+	ProximityMetricsInterval time.Duration
+
+	// proxy cache for chunks retrieved outside of the area of responsibility
+	ProxyCacheDbPath   string
+	ProxyCacheCapacity uint64 // number of chunks, 0 disables the proxy cache
+
+	// NetStore
+	FetcherTimeout time.Duration // max time a chunk is searched for on the network
+	SearchTimeout  time.Duration // max time to wait for a single peer to deliver a chunk
+
+	// recovery of chunks that can not be retrieved
+	RecoveryEnabled bool     // send recovery requests over pss when retrieval fails
+	RecoveryTargets []string // hex encoded address prefixes of repair nodes recovery requests are sent to
+	RepairEnabled   bool     // respond to recovery requests with locally stored chunks
 
 	// Swap configs
 	SwapBackendURL          string         // Ethereum API endpoint
@@ -82,7 +105,11 @@ type Config struct {
 	BootnodeMode       bool
 	DisableAutoConnect bool
 	EnablePinning      bool
+	APIKeysEnabled     bool   // require API keys for HTTP API requests
+	APIAdminKey        string `toml:"-"` // API key with admin privileges, used to manage other keys
 	Cors               string
+	HTTPCacheMaxAge    time.Duration // max-age of HTTP responses for content addressed by a swarm hash, 0 disables caching
+	HTTPCacheENSMaxAge time.Duration // max-age of HTTP responses for content addressed by an ENS name, 0 to revalidate on every request
 	BzzAccount         string
 	GlobalStoreAPI     string
 	privateKey         *ecdsa.PrivateKey
@@ -92,6 +119,8 @@ type Config struct {
 func NewConfig() *Config {
 	return &Config{
 		FileStoreParams:         storage.NewFileStoreParams(),
+		FetcherTimeout:          timeouts.FetcherGlobalTimeout,
+		SearchTimeout:           timeouts.SearchTimeout,
 		SwapBackendURL:          "",
 		SwapEnabled:             false,
 		SwapSkipDeposit:         false,
@@ -108,6 +137,7 @@ func NewConfig() *Config {
 		Path:                    node.DefaultDataDir(),
 		ListenAddr:              DefaultHTTPListenAddr,
 		Port:                    DefaultHTTPPort,
+		HTTPCacheMaxAge:         DefaultHTTPCacheMaxAge,
 		NetworkID:               network.DefaultNetworkID,
 		SyncEnabled:             true,
 		PushSyncEnabled:         true,
@@ -141,6 +171,7 @@ func (c *Config) Init(prvKey *ecdsa.PrivateKey, nodeKey *ecdsa.PrivateKey) error
 
 	c.privateKey = prvKey
 	c.ChunkDbPath = filepath.Join(c.Path, "chunks")
+	c.ProxyCacheDbPath = filepath.Join(c.Path, "proxy-cache")
 	c.BaseKey = common.FromHex(c.BzzKey)
 
 	c.Pss = c.Pss.WithPrivateKey(c.privateKey)
@@ -0,0 +1,221 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/log"
+	"github.com/ethersphere/swarm/storage"
+	"github.com/ethersphere/swarm/storage/traversal"
+)
+
+// Export archive format is the same as the one of localstore export,
+// so that archives can also be imported with swarm db import.
+const (
+	// filename in tar archive that holds the information
+	// about exported data format version
+	exportVersionFilename = ".swarm-export-version"
+	// legacy version for previous LDBStore
+	legacyExportVersion = "1"
+	// current export format version
+	currentExportVersion = "2"
+	// maximal size of the export version file
+	maxExportVersionSize = 16
+	// maximal size of chunk data in the current
+	// export format, including the span
+	maxExportChunkSize = chunk.DefaultSize + 8
+)
+
+var (
+	apiExportCount  = metrics.NewRegisteredCounter("api/export/count", nil)
+	apiExportChunks = metrics.NewRegisteredCounter("api/export/chunks", nil)
+	apiExportFail   = metrics.NewRegisteredCounter("api/export/fail", nil)
+	apiImportCount  = metrics.NewRegisteredCounter("api/import/count", nil)
+	apiImportChunks = metrics.NewRegisteredCounter("api/import/chunks", nil)
+	apiImportFail   = metrics.NewRegisteredCounter("api/import/fail", nil)
+)
+
+// Export writes a tar archive to the writer with all chunks that belong to
+// the content with the root address, including manifests and feed updates
+// referenced by it. Every chunk is written only once. It returns the number
+// of chunks exported. If an error occurs, the end of the archive is not
+// written, so that readers can detect that the archive is incomplete.
+func (a *API) Export(ctx context.Context, root storage.Address, w io.Writer) (count int64, err error) {
+	apiExportCount.Inc(1)
+	defer func() {
+		if err != nil {
+			apiExportFail.Inc(1)
+		}
+	}()
+
+	tw := tar.NewWriter(w)
+	defer func() {
+		if err == nil {
+			err = tw.Close()
+		}
+	}()
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name: exportVersionFilename,
+		Mode: 0644,
+		Size: int64(len(currentExportVersion)),
+	}); err != nil {
+		return 0, err
+	}
+	if _, err := tw.Write([]byte(currentExportVersion)); err != nil {
+		return 0, err
+	}
+
+	store := a.fileStore.ChunkStore
+	seen := make(map[string]struct{})
+	err = traversal.New(store, a.feed).TraverseAddresses(ctx, root, func(addr chunk.Address) error {
+		key := string(addr)
+		if _, ok := seen[key]; ok {
+			return nil
+		}
+		seen[key] = struct{}{}
+
+		ch, err := store.Get(ctx, chunk.ModeGetRequest, addr)
+		if err != nil {
+			return fmt.Errorf("get chunk %s: %v", addr, err)
+		}
+		data := ch.Data()
+		if err := tw.WriteHeader(&tar.Header{
+			Name: hex.EncodeToString(addr),
+			Mode: 0644,
+			Size: int64(len(data)),
+		}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+		apiExportChunks.Inc(1)
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// Import reads a tar archive produced by Export or localstore export from
+// the reader and stores its chunks for upload to the network. Chunks that
+// are neither valid content addressed chunks nor valid feed updates are
+// rejected. It returns the number of chunks imported.
+func (a *API) Import(ctx context.Context, r io.Reader) (count int64, err error) {
+	apiImportCount.Inc(1)
+	defer func() {
+		if err != nil {
+			apiImportFail.Inc(1)
+		}
+	}()
+
+	validators := []chunk.Validator{
+		storage.NewContentAddressValidator(storage.MakeHashFunc(storage.DefaultHash)),
+	}
+	if a.feed != nil {
+		validators = append(validators, a.feed)
+	}
+	store := a.fileStore.ChunkStore
+
+	tr := tar.NewReader(r)
+	// if exportVersionFilename file is not present
+	// assume legacy version
+	version := legacyExportVersion
+	for first := true; ; first = false {
+		hdr, err := tr.Next()
+		if err != nil {
+			if err == io.EOF {
+				return count, nil
+			}
+			return count, err
+		}
+		if first && hdr.Name == exportVersionFilename {
+			if hdr.Size > maxExportVersionSize {
+				return count, fmt.Errorf("export version file too large: %v bytes", hdr.Size)
+			}
+			data, err := ioutil.ReadAll(tr)
+			if err != nil {
+				return count, err
+			}
+			version = string(data)
+			continue
+		}
+
+		if len(hdr.Name) != 2*chunk.AddressLength {
+			log.Warn("ignoring non-chunk file", "name", hdr.Name)
+			continue
+		}
+		addr, err := hex.DecodeString(hdr.Name)
+		if err != nil {
+			log.Warn("ignoring invalid chunk file", "name", hdr.Name, "err", err)
+			continue
+		}
+		maxSize := int64(maxExportChunkSize)
+		if version == legacyExportVersion {
+			// legacy chunk data is prefixed with the chunk key
+			maxSize += chunk.AddressLength
+		}
+		if hdr.Size > maxSize {
+			return count, fmt.Errorf("chunk %s: %v: %v bytes", hdr.Name, chunk.ErrChunkInvalid, hdr.Size)
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return count, err
+		}
+
+		var ch chunk.Chunk
+		switch version {
+		case legacyExportVersion:
+			// LDBStore Export exported chunk data prefixed with the chunk key.
+			if len(data) < chunk.AddressLength {
+				return count, fmt.Errorf("chunk %s: %v", hdr.Name, chunk.ErrChunkInvalid)
+			}
+			ch = chunk.NewChunk(addr, data[chunk.AddressLength:])
+		case currentExportVersion:
+			ch = chunk.NewChunk(addr, data)
+		default:
+			return count, fmt.Errorf("unsupported export data version %q", version)
+		}
+
+		if !validChunk(validators, ch) {
+			return count, fmt.Errorf("chunk %s: %v", hdr.Name, chunk.ErrChunkInvalid)
+		}
+		if _, err := store.Put(ctx, chunk.ModePutUpload, ch); err != nil {
+			return count, err
+		}
+		apiImportChunks.Inc(1)
+		count++
+	}
+}
+
+// validChunk returns true if any of the validators accepts the chunk.
+func validChunk(validators []chunk.Validator, ch chunk.Chunk) bool {
+	for _, v := range validators {
+		if v.Validate(ch) {
+			return true
+		}
+	}
+	return false
+}
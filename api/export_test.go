@@ -0,0 +1,225 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/storage"
+	"github.com/ethersphere/swarm/storage/traversal"
+	"github.com/ethersphere/swarm/testutil"
+)
+
+// TestExportImport exports a collection from one node and imports it into
+// another, validating that only chunks of the exported content are included
+// and that the content can be retrieved after the import.
+func TestExportImport(t *testing.T) {
+	for _, toEncrypt := range []bool{false, true} {
+		a, cleanup := newTestExportAPI(t)
+		defer cleanup()
+
+		ctx := context.Background()
+		files := map[string][]byte{
+			"index.html":     testutil.RandomBytes(1, 100),
+			"dir/file.txt":   testutil.RandomBytes(2, 10000),
+			"dir/large.data": testutil.RandomBytes(3, 300000),
+		}
+		root, err := a.NewManifest(ctx, toEncrypt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		root, err = a.UpdateManifest(ctx, root, func(mw *ManifestWriter) error {
+			for path, data := range files {
+				_, err := mw.AddEntry(ctx, bytes.NewReader(data), &ManifestEntry{
+					Path:        path,
+					ContentType: "application/octet-stream",
+					Size:        int64(len(data)),
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// unrelated content must not be exported
+		_, wait, err := a.Store(ctx, bytes.NewReader(testutil.RandomBytes(4, 10000)), 10000, toEncrypt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		want, err := countChunks(a, root)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var archive bytes.Buffer
+		count, err := a.Export(ctx, root, &archive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != want {
+			t.Fatalf("exported %v chunks, want %v", count, want)
+		}
+
+		b, cleanup := newTestExportAPI(t)
+		defer cleanup()
+
+		count, err = b.Import(ctx, &archive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != want {
+			t.Fatalf("imported %v chunks, want %v", count, want)
+		}
+
+		for path, data := range files {
+			reader, _, _, _, err := b.Get(ctx, NOOPDecrypt, root, path)
+			if err != nil {
+				t.Fatalf("get %s: %v", path, err)
+			}
+			size, err := reader.Size(ctx, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]byte, size)
+			if _, err := reader.ReadAt(got, 0); err != nil && err != io.EOF {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("got invalid data for %s", path)
+			}
+		}
+	}
+}
+
+// TestImportInvalidChunk validates that chunks which data does not match
+// their address are rejected.
+func TestImportInvalidChunk(t *testing.T) {
+	a, cleanup := newTestExportAPI(t)
+	defer cleanup()
+
+	ch := storage.GenerateRandomChunk(chunk.DefaultSize)
+	data := append([]byte(nil), ch.Data()...)
+	data[len(data)-1]++
+
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{
+		{name: exportVersionFilename, data: []byte(currentExportVersion)},
+		{name: hex.EncodeToString(ch.Address()), data: data},
+	} {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := a.Import(context.Background(), &archive)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if count != 0 {
+		t.Fatalf("imported %v chunks, want 0", count)
+	}
+}
+
+// TestImportTooLargeChunk validates that archive entries larger than
+// the maximal chunk size are rejected before they are read.
+func TestImportTooLargeChunk(t *testing.T) {
+	a, cleanup := newTestExportAPI(t)
+	defer cleanup()
+
+	for _, version := range []string{legacyExportVersion, currentExportVersion} {
+		var archive bytes.Buffer
+		tw := tar.NewWriter(&archive)
+		if err := tw.WriteHeader(&tar.Header{Name: exportVersionFilename, Mode: 0644, Size: int64(len(version))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(version)); err != nil {
+			t.Fatal(err)
+		}
+		data := make([]byte, maxExportChunkSize+chunk.AddressLength+1)
+		if err := tw.WriteHeader(&tar.Header{Name: hex.EncodeToString(make([]byte, chunk.AddressLength)), Mode: 0644, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := a.Import(context.Background(), &archive)
+		if err == nil || !strings.Contains(err.Error(), chunk.ErrChunkInvalid.Error()) {
+			t.Errorf("version %s: got error %v, want %v", version, err, chunk.ErrChunkInvalid)
+		}
+	}
+}
+
+func newTestExportAPI(t *testing.T) (*API, func()) {
+	t.Helper()
+
+	datadir, err := ioutil.TempDir("", "bzz-export-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags := chunk.NewTags()
+	fileStore, cleanup, err := storage.NewLocalFileStore(datadir, make([]byte, 32), tags)
+	if err != nil {
+		os.RemoveAll(datadir)
+		t.Fatal(err)
+	}
+	return NewAPI(fileStore, nil, nil, nil, nil, tags), func() {
+		cleanup()
+		os.RemoveAll(datadir)
+	}
+}
+
+// countChunks returns the number of distinct chunks of the content
+// with the root address.
+func countChunks(a *API, root storage.Address) (int64, error) {
+	seen := make(map[string]struct{})
+	err := traversal.New(a.fileStore.ChunkStore, nil).TraverseAddresses(context.Background(), root, func(addr chunk.Address) error {
+		seen[string(addr)] = struct{}{}
+		return nil
+	})
+	return int64(len(seen)), err
+}
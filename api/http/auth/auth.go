@@ -0,0 +1,290 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package auth provides API keys for the Swarm HTTP API. Every key can
+// have a rate limit of requests and a quota of uploaded bytes. Keys and
+// their usage are persisted in a shed database.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/swarm/shed"
+	"github.com/syndtr/goleveldb/leveldb"
+	"golang.org/x/time/rate"
+)
+
+var (
+	// ErrInvalidKey is returned when the key secret is not known.
+	ErrInvalidKey = errors.New("invalid api key")
+	// ErrKeyNotFound is returned when there is no key with requested id.
+	ErrKeyNotFound = errors.New("api key not found")
+)
+
+// secretLength is the number of random bytes in generated key secrets.
+const secretLength = 32
+
+// Key holds settings and usage of an API key.
+// Secret key values are never stored, keys are identified by
+// the hash of their secret.
+type Key struct {
+	ID          string    `json:"id"`          // hex encoded sha256 hash of the secret
+	Name        string    `json:"name"`        // human readable name
+	Admin       bool      `json:"admin"`       // whether the key can manage other keys
+	RateLimit   float64   `json:"rateLimit"`   // allowed requests per second, 0 for unlimited
+	UploadQuota int64     `json:"uploadQuota"` // allowed uploaded bytes, 0 for unlimited
+	Uploaded    int64     `json:"uploaded"`    // number of uploaded bytes
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// RemainingQuota returns the number of bytes that the key is still allowed
+// to upload, or -1 if uploads are not limited.
+func (k *Key) RemainingQuota() int64 {
+	if k.UploadQuota <= 0 {
+		return -1
+	}
+	if k.Uploaded >= k.UploadQuota {
+		return 0
+	}
+	return k.UploadQuota - k.Uploaded
+}
+
+// Store keeps API keys and enforces their rate limits.
+type Store struct {
+	db       *shed.DB
+	keys     shed.Index
+	admin    *Key                     // admin key from the node configuration, not persisted
+	mu       sync.Mutex               // serializes key updates
+	limiters map[string]*rate.Limiter // request rate limiters by key id
+	limMu    sync.Mutex               // protects limiters
+}
+
+// NewStore opens or creates a Store with leveldb at path. If adminSecret
+// is not empty, it is accepted as a key with admin privileges that is not
+// rate limited, so that other keys can be created.
+func NewStore(path, adminSecret string) (s *Store, err error) {
+	s = &Store{
+		limiters: make(map[string]*rate.Limiter),
+	}
+	if adminSecret != "" {
+		s.admin = &Key{
+			ID:    KeyID(adminSecret),
+			Name:  "admin",
+			Admin: true,
+		}
+	}
+	s.db, err = shed.NewDB(path, "api/http/auth")
+	if err != nil {
+		return nil, err
+	}
+	// Data field of the Item holds JSON encoded Key
+	s.keys, err = s.db.NewIndex("KeyID->Key", shed.IndexFuncs{
+		EncodeKey: func(fields shed.Item) (key []byte, err error) {
+			return fields.Address, nil
+		},
+		DecodeKey: func(key []byte) (e shed.Item, err error) {
+			e.Address = key
+			return e, nil
+		},
+		EncodeValue: func(fields shed.Item) (value []byte, err error) {
+			return fields.Data, nil
+		},
+		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
+			e.Data = value
+			return e, nil
+		},
+	})
+	if err != nil {
+		s.db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// KeyID returns the identifier of the key with the secret.
+func KeyID(secret string) string {
+	h := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(h[:])
+}
+
+// Create generates a new key secret and stores the key with settings
+// from the provided key. It returns the secret and the stored key.
+func (s *Store) Create(settings Key) (secret string, key *Key, err error) {
+	b := make([]byte, secretLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	secret = hex.EncodeToString(b)
+
+	key = &Key{
+		ID:          KeyID(secret),
+		Name:        settings.Name,
+		Admin:       settings.Admin,
+		RateLimit:   settings.RateLimit,
+		UploadQuota: settings.UploadQuota,
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.put(key); err != nil {
+		return "", nil, err
+	}
+	return secret, key, nil
+}
+
+// Get returns the key with the id.
+func (s *Store) Get(id string) (*Key, error) {
+	if s.admin != nil && id == s.admin.ID {
+		k := *s.admin
+		return &k, nil
+	}
+	item, err := s.keys.Get(shed.Item{
+		Address: []byte(id),
+	})
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return nil, ErrKeyNotFound
+		}
+		return nil, err
+	}
+	return decodeKey(item.Data)
+}
+
+// Authenticate returns the key with the secret, or ErrInvalidKey.
+func (s *Store) Authenticate(secret string) (*Key, error) {
+	if secret == "" {
+		return nil, ErrInvalidKey
+	}
+	k, err := s.Get(KeyID(secret))
+	if err == ErrKeyNotFound {
+		return nil, ErrInvalidKey
+	}
+	return k, err
+}
+
+// List returns all stored keys.
+func (s *Store) List() (keys []*Key, err error) {
+	err = s.keys.Iterate(func(item shed.Item) (stop bool, err error) {
+		k, err := decodeKey(item.Data)
+		if err != nil {
+			return true, err
+		}
+		keys = append(keys, k)
+		return false, nil
+	}, nil)
+	return keys, err
+}
+
+// Delete removes the key with the id.
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item := shed.Item{
+		Address: []byte(id),
+	}
+	has, err := s.keys.Has(item)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return ErrKeyNotFound
+	}
+	if err := s.keys.Delete(item); err != nil {
+		return err
+	}
+
+	s.limMu.Lock()
+	delete(s.limiters, id)
+	s.limMu.Unlock()
+	return nil
+}
+
+// Allow reports whether a request with the key is allowed by its rate limit.
+// When it is not allowed, the duration after which a request can be retried is returned.
+func (s *Store) Allow(key *Key) (ok bool, retryAfter time.Duration) {
+	if key.RateLimit <= 0 {
+		return true, 0
+	}
+
+	s.limMu.Lock()
+	l, ok := s.limiters[key.ID]
+	if !ok || l.Limit() != rate.Limit(key.RateLimit) {
+		burst := int(key.RateLimit)
+		if burst < 1 {
+			burst = 1
+		}
+		l = rate.NewLimiter(rate.Limit(key.RateLimit), burst)
+		s.limiters[key.ID] = l
+	}
+	s.limMu.Unlock()
+
+	r := l.Reserve()
+	if d := r.Delay(); d > 0 {
+		r.Cancel()
+		return false, d
+	}
+	return true, 0
+}
+
+// AddUploaded increments the number of uploaded bytes for the key with the id.
+func (s *Store) AddUploaded(id string, n int64) error {
+	if n == 0 || (s.admin != nil && id == s.admin.ID) {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	k.Uploaded += n
+	return s.put(k)
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) put(k *Key) error {
+	data, err := json.Marshal(k)
+	if err != nil {
+		return err
+	}
+	return s.keys.Put(shed.Item{
+		Address: []byte(k.ID),
+		Data:    data,
+	})
+}
+
+func decodeKey(data []byte) (*Key, error) {
+	k := new(Key)
+	if err := json.Unmarshal(data, k); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
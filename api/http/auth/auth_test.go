@@ -0,0 +1,194 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package auth
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func newTestStore(t *testing.T, adminSecret string) (s *Store, cleanup func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "swarm-api-auth-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err = NewStore(dir, adminSecret)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return s, func() {
+		s.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// TestStore validates creating, authenticating, listing and deleting keys.
+func TestStore(t *testing.T) {
+	s, cleanup := newTestStore(t, "")
+	defer cleanup()
+
+	secret, key, err := s.Create(Key{
+		Name:        "test",
+		RateLimit:   10,
+		UploadQuota: 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.ID != KeyID(secret) {
+		t.Errorf("got key id %s, want %s", key.ID, KeyID(secret))
+	}
+
+	got, err := s.Authenticate(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "test" || got.RateLimit != 10 || got.UploadQuota != 100 || got.Admin {
+		t.Errorf("got key %+v", got)
+	}
+
+	if _, err := s.Authenticate("invalid"); err != ErrInvalidKey {
+		t.Errorf("got error %v, want %v", err, ErrInvalidKey)
+	}
+	if _, err := s.Authenticate(""); err != ErrInvalidKey {
+		t.Errorf("got error %v, want %v", err, ErrInvalidKey)
+	}
+
+	if _, _, err := s.Create(Key{Name: "other"}); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 {
+		t.Errorf("got %v keys, want 2", len(keys))
+	}
+
+	if err := s.Delete(key.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Authenticate(secret); err != ErrInvalidKey {
+		t.Errorf("got error %v, want %v", err, ErrInvalidKey)
+	}
+	if err := s.Delete(key.ID); err != ErrKeyNotFound {
+		t.Errorf("got error %v, want %v", err, ErrKeyNotFound)
+	}
+}
+
+// TestStoreAdmin validates that the admin key from configuration
+// is accepted, but not listed or accounted.
+func TestStoreAdmin(t *testing.T) {
+	s, cleanup := newTestStore(t, "admin secret")
+	defer cleanup()
+
+	key, err := s.Authenticate("admin secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !key.Admin {
+		t.Error("admin key has no admin privileges")
+	}
+	if err := s.AddUploaded(key.ID, 100); err != nil {
+		t.Fatal(err)
+	}
+	if q := key.RemainingQuota(); q != -1 {
+		t.Errorf("got remaining quota %v, want -1", q)
+	}
+
+	keys, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 0 {
+		t.Errorf("got %v keys, want 0", len(keys))
+	}
+}
+
+// TestStoreUploadQuota validates that uploaded bytes are persisted
+// and reduce the remaining quota.
+func TestStoreUploadQuota(t *testing.T) {
+	s, cleanup := newTestStore(t, "")
+	defer cleanup()
+
+	_, key, err := s.Create(Key{UploadQuota: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := key.RemainingQuota(); q != 100 {
+		t.Errorf("got remaining quota %v, want 100", q)
+	}
+
+	for _, tc := range []struct {
+		n    int64
+		want int64
+	}{
+		{n: 40, want: 60},
+		{n: 40, want: 20},
+		{n: 40, want: 0},
+	} {
+		if err := s.AddUploaded(key.ID, tc.n); err != nil {
+			t.Fatal(err)
+		}
+		key, err = s.Get(key.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if q := key.RemainingQuota(); q != tc.want {
+			t.Errorf("got remaining quota %v, want %v", q, tc.want)
+		}
+	}
+
+	if err := s.AddUploaded("unknown", 1); err != ErrKeyNotFound {
+		t.Errorf("got error %v, want %v", err, ErrKeyNotFound)
+	}
+}
+
+// TestStoreAllow validates rate limiting of keys.
+func TestStoreAllow(t *testing.T) {
+	s, cleanup := newTestStore(t, "")
+	defer cleanup()
+
+	_, unlimited, err := s.Create(Key{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if ok, _ := s.Allow(unlimited); !ok {
+			t.Fatalf("request %v not allowed for unlimited key", i)
+		}
+	}
+
+	_, limited, err := s.Create(Key{RateLimit: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := s.Allow(limited); !ok {
+		t.Fatal("first request not allowed")
+	}
+	ok, retryAfter := s.Allow(limited)
+	if ok {
+		t.Fatal("second request allowed")
+	}
+	if retryAfter <= 0 {
+		t.Errorf("got retry after %v, want positive duration", retryAfter)
+	}
+}
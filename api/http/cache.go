@@ -0,0 +1,98 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/swarm/api"
+	"github.com/ethersphere/swarm/storage"
+)
+
+// CacheOptions configure the Cache-Control header of responses served
+// on bzz:/ and bzz-raw:/ routes.
+type CacheOptions struct {
+	// MaxAge is used for content addressed by a swarm hash, which never
+	// changes. Zero disables caching.
+	MaxAge time.Duration
+	// ENSMaxAge is used for content addressed by an ENS name, which may be
+	// updated to point to different content. Zero makes clients revalidate
+	// on every request, which is cheap as the ETag is the content hash.
+	ENSMaxAge time.Duration
+}
+
+var defaultCacheOptions = CacheOptions{
+	MaxAge: api.DefaultHTTPCacheMaxAge,
+}
+
+// SetCacheOptions sets the options used to construct the Cache-Control
+// header of content responses.
+func (s *Server) SetCacheOptions(o CacheOptions) {
+	s.cache = o
+}
+
+// cacheControl returns the Cache-Control header value for content which
+// was addressed by a swarm hash if immutable is true, or by an ENS name
+// otherwise.
+func (o CacheOptions) cacheControl(immutable bool) string {
+	if !immutable {
+		if o.ENSMaxAge <= 0 {
+			return "no-cache"
+		}
+		return fmt.Sprintf("max-age=%d", o.ENSMaxAge/time.Second)
+	}
+	if o.MaxAge <= 0 {
+		return "no-cache"
+	}
+	return fmt.Sprintf("max-age=%d, immutable", o.MaxAge/time.Second)
+}
+
+// setCacheHeaders sets the caching headers of a content response, with
+// the swarm hash of the content as the ETag.
+func (s *Server) setCacheHeaders(w http.ResponseWriter, immutable bool, addr storage.Address, modTime time.Time) {
+	w.Header().Set("Cache-Control", s.cache.cacheControl(immutable))
+	w.Header().Set("ETag", etag(addr))
+	if !modTime.IsZero() {
+		w.Header().Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	}
+}
+
+// etag returns the quoted ETag header value for the given address.
+func etag(addr storage.Address) string {
+	return fmt.Sprintf("%q", addr.Hex())
+}
+
+// notModified reports whether the If-None-Match header of the request
+// matches the ETag of the given address, in which case the client already
+// has the content. Weak comparison is used as for all GET requests.
+func notModified(r *http.Request, addr storage.Address) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	tag := etag(addr)
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" || strings.TrimPrefix(t, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -1,7 +1,11 @@
 package http
 
 import (
+	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"runtime/debug"
 	"strconv"
@@ -10,6 +14,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethersphere/swarm/api"
+	"github.com/ethersphere/swarm/api/http/auth"
 	"github.com/ethersphere/swarm/chunk"
 	"github.com/ethersphere/swarm/log"
 	"github.com/ethersphere/swarm/sctx"
@@ -34,9 +39,10 @@ type Adapter func(http.Handler) http.Handler
 // as a unique identifier and injects it into the request context
 func SetRequestID(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		r = r.WithContext(SetRUID(r.Context(), uuid.New()[:8]))
+		ruid := uuid.New()[:8]
+		r = r.WithContext(log.WithContext(SetRUID(r.Context(), ruid), "ruid", ruid))
 		metrics.GetOrRegisterCounter(fmt.Sprintf("http/request/%s", r.Method), nil).Inc(1)
-		log.Info("created ruid for request", "ruid", GetRUID(r.Context()), "method", r.Method, "url", r.RequestURI)
+		log.Info("created ruid for request", "ruid", ruid, "method", r.Method, "url", r.RequestURI)
 
 		h.ServeHTTP(w, r)
 	})
@@ -53,6 +59,36 @@ func SetRequestHost(h http.Handler) http.Handler {
 	})
 }
 
+// SetRetrieveOptions is a middleware that limits the duration of the request
+// and the number of hops of chunk retrieve requests, if they are set with
+// RetrieveTimeoutHeaderName and RetrieveHopsHeaderName request headers,
+// so that requests for content that is not found fail fast
+func SetRetrieveOptions(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if v := r.Header.Get(RetrieveTimeoutHeaderName); v != "" {
+			timeout, err := time.ParseDuration(v)
+			if err != nil || timeout <= 0 {
+				respondError(w, r, fmt.Sprintf("invalid %s header value %q", RetrieveTimeoutHeaderName, v), http.StatusBadRequest)
+				return
+			}
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		if v := r.Header.Get(RetrieveHopsHeaderName); v != "" {
+			hops, err := strconv.ParseUint(v, 10, 8)
+			if err != nil || hops == 0 {
+				respondError(w, r, fmt.Sprintf("invalid %s header value %q", RetrieveHopsHeaderName, v), http.StatusBadRequest)
+				return
+			}
+			ctx = sctx.SetHopLimit(ctx, uint8(hops))
+		}
+
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 // ParseURI is a middleware that parses the request URI
 // to a Swarm URI object that dissects the content presented after the HTTP URI's first slash
 func ParseURI(h http.Handler) http.Handler {
@@ -91,7 +127,7 @@ func InitLoggingResponseWriter(h http.Handler) http.Handler {
 		h.ServeHTTP(writer, r)
 
 		ts := time.Since(tn)
-		log.Info("request served", "ruid", GetRUID(r.Context()), "code", writer.statusCode, "time", ts)
+		log.FromContext(r.Context()).Info("request served", "code", writer.statusCode, "time", ts)
 		metrics.GetOrRegisterResettingTimer(fmt.Sprintf("http/request/%s/time", r.Method), nil).Update(ts)
 		metrics.GetOrRegisterResettingTimer(fmt.Sprintf("http/request/%s/%d/time", r.Method, writer.statusCode), nil).Update(ts)
 	})
@@ -146,6 +182,37 @@ func InitUploadTag(h http.Handler, tags *chunk.Tags) http.Handler {
 	})
 }
 
+// StampValidator validates postage stamps attached to uploads. Chunks
+// are not stored with stamps for which it returns an error, for example
+// if the referenced postage batch is unknown or has no balance left.
+type StampValidator func(ctx context.Context, stamp []byte) error
+
+// AttachUploadStamp parses the postage stamp from the StampHeaderName header,
+// validates it and sets it in the request context, so that it is attached
+// to all uploaded chunks. Uploads without the header are not stamped.
+func AttachUploadStamp(h http.Handler, validate StampValidator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get(StampHeaderName)
+		if header == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		stamp, err := hex.DecodeString(strings.TrimPrefix(header, "0x"))
+		if err != nil || len(stamp) != chunk.StampSize {
+			respondError(w, r, fmt.Sprintf("%v: expected %d hex encoded bytes", chunk.ErrInvalidStamp, chunk.StampSize), http.StatusBadRequest)
+			return
+		}
+		if validate != nil {
+			if err := validate(r.Context(), stamp); err != nil {
+				respondError(w, r, fmt.Sprintf("%v: %v", chunk.ErrInvalidStamp, err), http.StatusPaymentRequired)
+				return
+			}
+		}
+		log.Trace("setting postage stamp to context", "stamp", header)
+		h.ServeHTTP(w, r.WithContext(sctx.SetStamp(r.Context(), stamp)))
+	})
+}
+
 // InstrumentOpenTracing instruments an HTTP request with an OpenTracing span
 func InstrumentOpenTracing(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -156,6 +223,12 @@ func InstrumentOpenTracing(h http.Handler) http.Handler {
 		}
 		spanName := fmt.Sprintf("http.%s.%s", r.Method, uri.Scheme)
 		ctx, sp := spancontext.StartSpan(r.Context(), spanName)
+		// jaeger span contexts are formatted as trace:span:parent:flags
+		if sc, ok := sp.Context().(fmt.Stringer); ok {
+			if trace := strings.SplitN(sc.String(), ":", 2)[0]; trace != "" {
+				ctx = log.WithContext(ctx, "trace", trace)
+			}
+		}
 
 		defer sp.Finish()
 		h.ServeHTTP(w, r.WithContext(ctx))
@@ -197,3 +270,118 @@ func RecoverPanic(h http.Handler) http.Handler {
 		h.ServeHTTP(w, r)
 	})
 }
+
+// Authenticate is a middleware that requires a valid API key in the APIKeyHeaderName header
+// or as a bearer token in the Authorization header, if keys are enabled (keys is not nil).
+// Requests exceeding the rate limit of the key are rejected.
+// The authenticated key is injected into the request context.
+func Authenticate(h http.Handler, keys *auth.Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if keys == nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		secret := r.Header.Get(APIKeyHeaderName)
+		if secret == "" {
+			if a := r.Header.Get("Authorization"); strings.HasPrefix(a, "Bearer ") {
+				secret = strings.TrimPrefix(a, "Bearer ")
+			}
+		}
+		key, err := keys.Authenticate(secret)
+		if err != nil {
+			metrics.GetOrRegisterCounter("api/http/auth/fail", nil).Inc(1)
+			if err != auth.ErrInvalidKey {
+				log.Error("authenticate api key", "ruid", GetRUID(r.Context()), "err", err)
+				respondError(w, r, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			respondError(w, r, "Invalid or missing API key", http.StatusUnauthorized)
+			return
+		}
+
+		if ok, retryAfter := keys.Allow(key); !ok {
+			metrics.GetOrRegisterCounter("api/http/auth/ratelimited", nil).Inc(1)
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter/time.Second)+1))
+			respondError(w, r, "Rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+
+		log.Trace("authenticated api key", "ruid", GetRUID(r.Context()), "key", key.Name)
+		h.ServeHTTP(w, r.WithContext(SetAPIKey(r.Context(), key)))
+	})
+}
+
+// RequireAdmin is a middleware that allows only requests authenticated with
+// an admin API key. If keys are not enabled, all requests are rejected.
+func RequireAdmin(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := GetAPIKey(r.Context())
+		if key == nil {
+			respondError(w, r, "API keys disabled on this node", http.StatusForbidden)
+			return
+		}
+		if !key.Admin {
+			respondError(w, r, "Admin API key required", http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// errUploadQuotaExceeded is returned by the request body reader when the upload exceeds the quota
+var errUploadQuotaExceeded = errors.New("upload quota exceeded")
+
+// EnforceUploadQuota is a middleware that counts the bytes uploaded with the API key
+// from the request context and rejects uploads over the key upload quota.
+// Quota is checked against the key usage at the time the request was authenticated,
+// so concurrent uploads with the same key may exceed it by the size of one upload.
+func EnforceUploadQuota(h http.Handler, keys *auth.Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := GetAPIKey(r.Context())
+		if keys == nil || key == nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		remaining := key.RemainingQuota()
+		if remaining == 0 || (remaining > 0 && r.ContentLength > remaining) {
+			metrics.GetOrRegisterCounter("api/http/auth/quotaexceeded", nil).Inc(1)
+			respondError(w, r, errUploadQuotaExceeded.Error(), http.StatusForbidden)
+			return
+		}
+
+		body := &quotaReader{
+			r:     r.Body,
+			limit: remaining,
+		}
+		r.Body = body
+		h.ServeHTTP(w, r)
+
+		if err := keys.AddUploaded(key.ID, body.n); err != nil {
+			log.Error("update api key usage", "ruid", GetRUID(r.Context()), "key", key.Name, "err", err)
+		}
+	})
+}
+
+// quotaReader counts the bytes read from the request body and
+// returns errUploadQuotaExceeded if more than limit bytes are read.
+// Negative limit means no limit.
+type quotaReader struct {
+	r     io.ReadCloser
+	n     int64
+	limit int64
+}
+
+func (q *quotaReader) Read(p []byte) (n int, err error) {
+	n, err = q.r.Read(p)
+	q.n += int64(n)
+	if q.limit >= 0 && q.n > q.limit {
+		return n, errUploadQuotaExceeded
+	}
+	return n, err
+}
+
+func (q *quotaReader) Close() error {
+	return q.r.Close()
+}
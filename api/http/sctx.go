@@ -4,11 +4,14 @@ import (
 	"context"
 
 	"github.com/ethersphere/swarm/api"
+	"github.com/ethersphere/swarm/api/http/auth"
 	"github.com/ethersphere/swarm/sctx"
 )
 
 type uriKey struct{}
 
+type apiKeyKey struct{}
+
 func GetRUID(ctx context.Context) string {
 	v, ok := ctx.Value(sctx.HTTPRequestIDKey{}).(string)
 	if ok {
@@ -32,3 +35,17 @@ func GetURI(ctx context.Context) *api.URI {
 func SetURI(ctx context.Context, uri *api.URI) context.Context {
 	return context.WithValue(ctx, uriKey{}, uri)
 }
+
+// GetAPIKey returns the authenticated API key of the request,
+// or nil if API keys are not enabled.
+func GetAPIKey(ctx context.Context) *auth.Key {
+	v, ok := ctx.Value(apiKeyKey{}).(*auth.Key)
+	if ok {
+		return v
+	}
+	return nil
+}
+
+func SetAPIKey(ctx context.Context, key *auth.Key) context.Context {
+	return context.WithValue(ctx, apiKeyKey{}, key)
+}
@@ -21,6 +21,8 @@ package http
 
 import (
 	"bytes"
+	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -39,6 +41,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethersphere/swarm/api"
+	"github.com/ethersphere/swarm/api/http/auth"
 	"github.com/ethersphere/swarm/api/http/langos"
 	"github.com/ethersphere/swarm/chunk"
 	"github.com/ethersphere/swarm/log"
@@ -47,41 +50,83 @@ import (
 	"github.com/ethersphere/swarm/storage"
 	"github.com/ethersphere/swarm/storage/feed"
 	"github.com/ethersphere/swarm/storage/pin"
+	"github.com/ethersphere/swarm/storage/soc"
 	"github.com/rs/cors"
 )
 
 var (
-	postRawCount    = metrics.NewRegisteredCounter("api/http/post/raw/count", nil)
-	postRawFail     = metrics.NewRegisteredCounter("api/http/post/raw/fail", nil)
-	postFilesCount  = metrics.NewRegisteredCounter("api/http/post/files/count", nil)
-	postFilesFail   = metrics.NewRegisteredCounter("api/http/post/files/fail", nil)
-	deleteCount     = metrics.NewRegisteredCounter("api/http/delete/count", nil)
-	deleteFail      = metrics.NewRegisteredCounter("api/http/delete/fail", nil)
-	getCount        = metrics.NewRegisteredCounter("api/http/get/count", nil)
-	getFail         = metrics.NewRegisteredCounter("api/http/get/fail", nil)
-	getFileCount    = metrics.NewRegisteredCounter("api/http/get/file/count", nil)
-	getFileNotFound = metrics.NewRegisteredCounter("api/http/get/file/notfound", nil)
-	getFileFail     = metrics.NewRegisteredCounter("api/http/get/file/fail", nil)
-	getListCount    = metrics.NewRegisteredCounter("api/http/get/list/count", nil)
-	getListFail     = metrics.NewRegisteredCounter("api/http/get/list/fail", nil)
-	getTagCount     = metrics.NewRegisteredCounter("api/http/get/tag/count", nil)
-	getTagNotFound  = metrics.NewRegisteredCounter("api/http/get/tag/notfound", nil)
-	getTagFail      = metrics.NewRegisteredCounter("api/http/get/tag/fail", nil)
-	getPinCount     = metrics.NewRegisteredCounter("api/http/get/pin/count", nil)
-	getPinFail      = metrics.NewRegisteredCounter("api/http/get/pin/fail", nil)
-	postPinCount    = metrics.NewRegisteredCounter("api/http/post/pin/count", nil)
-	postPinFail     = metrics.NewRegisteredCounter("api/http/post/pin/fail", nil)
-	deletePinCount  = metrics.NewRegisteredCounter("api/http/delete/pin/count", nil)
-	deletePinFail   = metrics.NewRegisteredCounter("api/http/delete/pin/fail", nil)
+	postRawCount      = metrics.NewRegisteredCounter("api/http/post/raw/count", nil)
+	postRawFail       = metrics.NewRegisteredCounter("api/http/post/raw/fail", nil)
+	postFilesCount    = metrics.NewRegisteredCounter("api/http/post/files/count", nil)
+	postFilesFail     = metrics.NewRegisteredCounter("api/http/post/files/fail", nil)
+	deleteCount       = metrics.NewRegisteredCounter("api/http/delete/count", nil)
+	deleteFail        = metrics.NewRegisteredCounter("api/http/delete/fail", nil)
+	getCount          = metrics.NewRegisteredCounter("api/http/get/count", nil)
+	getFail           = metrics.NewRegisteredCounter("api/http/get/fail", nil)
+	getFileCount      = metrics.NewRegisteredCounter("api/http/get/file/count", nil)
+	getFileNotFound   = metrics.NewRegisteredCounter("api/http/get/file/notfound", nil)
+	getFileFail       = metrics.NewRegisteredCounter("api/http/get/file/fail", nil)
+	getListCount      = metrics.NewRegisteredCounter("api/http/get/list/count", nil)
+	getListFail       = metrics.NewRegisteredCounter("api/http/get/list/fail", nil)
+	getTagCount       = metrics.NewRegisteredCounter("api/http/get/tag/count", nil)
+	getTagNotFound    = metrics.NewRegisteredCounter("api/http/get/tag/notfound", nil)
+	getTagFail        = metrics.NewRegisteredCounter("api/http/get/tag/fail", nil)
+	getPinCount       = metrics.NewRegisteredCounter("api/http/get/pin/count", nil)
+	getPinFail        = metrics.NewRegisteredCounter("api/http/get/pin/fail", nil)
+	postPinCount      = metrics.NewRegisteredCounter("api/http/post/pin/count", nil)
+	postPinFail       = metrics.NewRegisteredCounter("api/http/post/pin/fail", nil)
+	deletePinCount    = metrics.NewRegisteredCounter("api/http/delete/pin/count", nil)
+	deletePinFail     = metrics.NewRegisteredCounter("api/http/delete/pin/fail", nil)
+	getExportCount    = metrics.NewRegisteredCounter("api/http/get/export/count", nil)
+	getExportFail     = metrics.NewRegisteredCounter("api/http/get/export/fail", nil)
+	postImportCount   = metrics.NewRegisteredCounter("api/http/post/import/count", nil)
+	postImportFail    = metrics.NewRegisteredCounter("api/http/post/import/fail", nil)
+	getKeysCount      = metrics.NewRegisteredCounter("api/http/get/keys/count", nil)
+	getKeysFail       = metrics.NewRegisteredCounter("api/http/get/keys/fail", nil)
+	postKeyCount      = metrics.NewRegisteredCounter("api/http/post/key/count", nil)
+	postKeyFail       = metrics.NewRegisteredCounter("api/http/post/key/fail", nil)
+	deleteKeyCount    = metrics.NewRegisteredCounter("api/http/delete/key/count", nil)
+	deleteKeyFail     = metrics.NewRegisteredCounter("api/http/delete/key/fail", nil)
+	getAuditCount     = metrics.NewRegisteredCounter("api/http/get/audit/count", nil)
+	getAuditFail      = metrics.NewRegisteredCounter("api/http/get/audit/fail", nil)
+	postAuditCount    = metrics.NewRegisteredCounter("api/http/post/audit/count", nil)
+	postAuditFail     = metrics.NewRegisteredCounter("api/http/post/audit/fail", nil)
+	postPrefetchCount = metrics.NewRegisteredCounter("api/http/post/prefetch/count", nil)
+	postPrefetchFail  = metrics.NewRegisteredCounter("api/http/post/prefetch/fail", nil)
+	getSOCCount       = metrics.NewRegisteredCounter("api/http/get/soc/count", nil)
+	getSOCFail        = metrics.NewRegisteredCounter("api/http/get/soc/fail", nil)
+	postSOCCount      = metrics.NewRegisteredCounter("api/http/post/soc/count", nil)
+	postSOCFail       = metrics.NewRegisteredCounter("api/http/post/soc/fail", nil)
+	getStatusCount    = metrics.NewRegisteredCounter("api/http/get/status/count", nil)
+	getStatusFail     = metrics.NewRegisteredCounter("api/http/get/status/fail", nil)
 )
 
 const (
 	TagHeaderName       = "x-swarm-tag"       // Presence of this in header indicates the tag
 	AnonymousHeaderName = "x-swarm-anonymous" // Presence of this in header indicates only pull sync should be used for upload
 	PinHeaderName       = "x-swarm-pin"       // Presence of this in header indicates pinning required
+	APIKeyHeaderName    = "x-swarm-api-key"   // API key, when API keys are enabled
+	StampHeaderName     = "x-swarm-stamp"     // hex encoded postage stamp attached to uploaded chunks
+
+	ExportErrorTrailerName = "x-swarm-export-error" // trailer with the error that interrupted an export
+
+	RetrieveTimeoutHeaderName = "x-swarm-retrieve-timeout" // max duration of chunk retrieval for the request, like 500ms
+	RetrieveHopsHeaderName    = "x-swarm-retrieve-hops"    // max number of hops chunk retrieve requests travel
 
 	encryptAddr    = "encrypt"
 	tarContentType = "application/x-tar"
+
+	// number of feed updates returned by history requests
+	// by default and at most
+	feedHistoryDefaultLimit = 100
+	feedHistoryMaxLimit     = 1000
+)
+
+var (
+	// maximal duration of a prefetch started with a bzz-prefetch request
+	prefetchTimeout = 30 * time.Minute
+	// maximal number of prefetches in progress at the same time
+	maxPrefetches = 16
 )
 
 type methodHandler map[string]http.Handler
@@ -95,7 +140,9 @@ func (m methodHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	rw.WriteHeader(http.StatusMethodNotAllowed)
 }
 
-func NewServer(api *api.API, pinAPI *pin.API, corsString string) *Server {
+// NewServer returns a new HTTP API server. Pinning endpoints are enabled if pinAPI is not nil.
+// If keys is not nil, all requests must be authenticated with an API key from the keys store.
+func NewServer(api *api.API, pinAPI *pin.API, keys *auth.Store, corsString string) *Server {
 	var allowedOrigins []string
 	for _, domain := range strings.Split(corsString, ",") {
 		allowedOrigins = append(allowedOrigins, strings.TrimSpace(domain))
@@ -107,7 +154,24 @@ func NewServer(api *api.API, pinAPI *pin.API, corsString string) *Server {
 		AllowedHeaders: []string{"*"},
 	})
 
-	server := &Server{api: api, pinAPI: pinAPI}
+	server := &Server{api: api, pinAPI: pinAPI, keys: keys, cache: defaultCacheOptions, prefetches: make(chan struct{}, maxPrefetches)}
+
+	authAdapter := Adapter(func(h http.Handler) http.Handler {
+		return Authenticate(h, keys)
+	})
+
+	quotaAdapter := Adapter(func(h http.Handler) http.Handler {
+		return EnforceUploadQuota(h, keys)
+	})
+
+	// endpoints that require an admin API key when keys are enabled
+	// are available to all requests when they are not
+	adminAdapter := Adapter(func(h http.Handler) http.Handler {
+		if keys == nil {
+			return h
+		}
+		return RequireAdmin(h)
+	})
 
 	defaultMiddlewares := []Adapter{
 		RecoverPanic,
@@ -115,6 +179,8 @@ func NewServer(api *api.API, pinAPI *pin.API, corsString string) *Server {
 		SetRequestHost,
 		InitLoggingResponseWriter,
 		ParseURI,
+		authAdapter,
+		SetRetrieveOptions,
 		InstrumentOpenTracing,
 	}
 
@@ -128,7 +194,11 @@ func NewServer(api *api.API, pinAPI *pin.API, corsString string) *Server {
 		})
 	}
 
-	defaultPostMiddlewares := append(defaultMiddlewares, tagAdapter)
+	stampAdapter := Adapter(func(h http.Handler) http.Handler {
+		return AttachUploadStamp(h, server.validateStamp)
+	})
+
+	defaultPostMiddlewares := append(defaultMiddlewares, quotaAdapter, tagAdapter, stampAdapter)
 
 	mux := http.NewServeMux()
 	mux.Handle("/bzz:/", methodHandler{
@@ -180,7 +250,7 @@ func NewServer(api *api.API, pinAPI *pin.API, corsString string) *Server {
 		),
 		"POST": Adapt(
 			http.HandlerFunc(server.HandlePostFeed),
-			defaultMiddlewares...,
+			append(defaultMiddlewares, quotaAdapter)...,
 		),
 	})
 	mux.Handle("/bzz-tag:/", methodHandler{
@@ -209,6 +279,68 @@ func NewServer(api *api.API, pinAPI *pin.API, corsString string) *Server {
 			append(defaultMiddlewares, pinAdapter(false))...,
 		),
 	})
+	mux.Handle("/bzz-export:/", methodHandler{
+		"GET": Adapt(
+			http.HandlerFunc(server.HandleGetExport),
+			defaultMiddlewares...,
+		),
+	})
+	mux.Handle("/bzz-import:/", methodHandler{
+		"POST": Adapt(
+			http.HandlerFunc(server.HandlePostImport),
+			append(defaultMiddlewares, quotaAdapter)...,
+		),
+	})
+	mux.Handle("/bzz-audit:/", methodHandler{
+		"GET": Adapt(
+			http.HandlerFunc(server.HandleGetAudit),
+			defaultMiddlewares...,
+		),
+		"POST": Adapt(
+			http.HandlerFunc(server.HandlePostAudit),
+			defaultMiddlewares...,
+		),
+	})
+	mux.Handle("/bzz-prefetch:/", methodHandler{
+		"POST": Adapt(
+			http.HandlerFunc(server.HandlePostPrefetch),
+			append(defaultMiddlewares, quotaAdapter, tagAdapter)...,
+		),
+	})
+	mux.Handle("/bzz-soc:/", methodHandler{
+		"GET": Adapt(
+			http.HandlerFunc(server.HandleGetSOC),
+			defaultMiddlewares...,
+		),
+		"POST": Adapt(
+			http.HandlerFunc(server.HandlePostSOC),
+			append(defaultMiddlewares, quotaAdapter)...,
+		),
+	})
+	mux.Handle("/bzz-keys:/", methodHandler{
+		"GET": Adapt(
+			http.HandlerFunc(server.HandleGetKeys),
+			append(defaultMiddlewares, RequireAdmin)...,
+		),
+		"POST": Adapt(
+			http.HandlerFunc(server.HandlePostKey),
+			append(defaultMiddlewares, RequireAdmin)...,
+		),
+		"DELETE": Adapt(
+			http.HandlerFunc(server.HandleDeleteKey),
+			append(defaultMiddlewares, RequireAdmin)...,
+		),
+	})
+	mux.Handle("/bzz-debug/status", methodHandler{
+		"GET": Adapt(
+			http.HandlerFunc(server.HandleGetStatus),
+			RecoverPanic,
+			SetRequestID,
+			InitLoggingResponseWriter,
+			authAdapter,
+			adminAdapter,
+		),
+	})
 	mux.Handle("/", methodHandler{
 		"GET": Adapt(
 			http.HandlerFunc(server.HandleRootPaths),
@@ -226,6 +358,28 @@ func (s *Server) ListenAndServe(addr string) error {
 	return http.ListenAndServe(addr, s)
 }
 
+// SetStampValidator sets the validator of postage stamps attached
+// to uploads. Stamps are not validated if it is not set.
+func (s *Server) SetStampValidator(v StampValidator) {
+	s.stamps = v
+}
+
+// validateStamp validates a postage stamp with the validator
+// set by SetStampValidator, if there is one.
+func (s *Server) validateStamp(ctx context.Context, stamp []byte) error {
+	if s.stamps == nil {
+		return nil
+	}
+	return s.stamps(ctx, stamp)
+}
+
+// SetInspector sets the inspector that provides the node status
+// on bzz-debug/status. The endpoint is disabled if it is not set,
+// and it requires an admin API key if API keys are enabled.
+func (s *Server) SetInspector(i *api.Inspector) {
+	s.inspector = i
+}
+
 // browser API for registering bzz url scheme handlers:
 // https://developer.mozilla.org/en/docs/Web-based_protocol_handlers
 // electron (chromium) api for registering bzz url scheme handlers:
@@ -234,7 +388,12 @@ type Server struct {
 	http.Handler
 	api        *api.API
 	pinAPI     *pin.API
+	keys       *auth.Store
+	cache      CacheOptions
+	stamps     StampValidator
+	inspector  *api.Inspector
 	listenAddr string
+	prefetches chan struct{} // limits the number of prefetches in progress
 }
 
 func (s *Server) HandleBzzGet(w http.ResponseWriter, r *http.Request) {
@@ -699,6 +858,11 @@ func (s *Server) HandleGetFeed(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.URL.Query().Get("history") == "1" {
+		s.handleGetFeedHistory(w, r, fd)
+		return
+	}
+
 	lookupParams := &feed.Query{Feed: *fd}
 	if err = lookupParams.FromValues(r.URL.Query()); err != nil { // parse period, version
 		respondError(w, r, fmt.Sprintf("invalid feed update request:%s", err), http.StatusBadRequest)
@@ -720,6 +884,61 @@ func (s *Server) HandleGetFeed(w http.ResponseWriter, r *http.Request) {
 	http.ServeContent(w, r, "", time.Now(), bytes.NewReader(data))
 }
 
+// handleGetFeedHistory responds with a JSON array of feed updates between
+// the from and to query parameter times, ordered from the oldest to the newest.
+// At most limit query parameter newest updates are returned.
+func (s *Server) handleGetFeedHistory(w http.ResponseWriter, r *http.Request, fd *feed.Feed) {
+	ruid := GetRUID(r.Context())
+	query := r.URL.Query()
+
+	var from, to uint64
+	limit := feedHistoryDefaultLimit
+	var err error
+	if v := query.Get("from"); v != "" {
+		if from, err = strconv.ParseUint(v, 10, 64); err != nil {
+			getFail.Inc(1)
+			respondError(w, r, fmt.Sprintf("invalid from parameter: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("to"); v != "" {
+		if to, err = strconv.ParseUint(v, 10, 64); err != nil {
+			getFail.Inc(1)
+			respondError(w, r, fmt.Sprintf("invalid to parameter: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 || limit > feedHistoryMaxLimit {
+			getFail.Inc(1)
+			respondError(w, r, fmt.Sprintf("invalid limit parameter, it must be between 1 and %v", feedHistoryMaxLimit), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if to != 0 && from > to {
+		getFail.Inc(1)
+		respondError(w, r, "from parameter must not be greater than to parameter", http.StatusBadRequest)
+		return
+	}
+
+	entries, err := s.api.FeedsHistory(r.Context(), fd, from, to, limit)
+	if err != nil {
+		getFail.Inc(1)
+		code, err2 := s.translateFeedError(w, r, "feed history fail", err)
+		respondError(w, r, err2.Error(), code)
+		return
+	}
+	if entries == nil {
+		entries = make([]*feed.HistoryEntry, 0)
+	}
+
+	log.Debug("Found feed history", "feed", fd.Hex(), "ruid", ruid, "updates", len(entries))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(entries)
+}
+
 func (s *Server) HandleGetFeedRaw(w http.ResponseWriter, r *http.Request) {
 	ruid := GetRUID(r.Context())
 	uri := GetURI(r.Context())
@@ -782,20 +1001,15 @@ func (s *Server) HandleGet(w http.ResponseWriter, r *http.Request) {
 		respondError(w, r, fmt.Sprintf("cannot resolve %s: %s", uri.Addr, err), http.StatusNotFound)
 		return
 	}
-	w.Header().Set("Cache-Control", "max-age=2147483648, immutable") // url was of type bzz://<hex key>/path, so we are sure it is immutable.
 
 	log.Debug("handle.get: resolved", "ruid", ruid, "key", addr)
 
 	// if path is set, interpret <key> as a manifest and return the
 	// raw entry at the given path
-	etag := common.Bytes2Hex(addr)
-	noneMatchEtag := r.Header.Get("If-None-Match")
-	w.Header().Set("ETag", fmt.Sprintf("%q", etag)) // set etag to manifest key or raw entry key.
-	if noneMatchEtag != "" {
-		if bytes.Equal(storage.Address(common.Hex2Bytes(noneMatchEtag)), addr) {
-			w.WriteHeader(http.StatusNotModified)
-			return
-		}
+	s.setCacheHeaders(w, uri.Address() != nil, addr, time.Time{}) // set etag to manifest key or raw entry key.
+	if notModified(r, addr) {
+		w.WriteHeader(http.StatusNotModified)
+		return
 	}
 
 	switch {
@@ -821,7 +1035,7 @@ func (s *Server) HandleGet(w http.ResponseWriter, r *http.Request) {
 			fileName = found
 		}
 		w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", fileName))
-		http.ServeContent(w, r, fileName, time.Now(), langos.NewBufferedReadSeeker(reader, getFileBufferSize))
+		http.ServeContent(w, r, fileName, time.Time{}, langos.NewBufferedReadSeeker(reader, getFileBufferSize))
 
 	case uri.Hash():
 		w.Header().Set("Content-Type", "text/plain")
@@ -914,24 +1128,11 @@ func (s *Server) HandleGetFile(w http.ResponseWriter, r *http.Request) {
 			respondError(w, r, fmt.Sprintf("cannot resolve %s: %s", uri.Addr, err), http.StatusNotFound)
 			return
 		}
-	} else {
-		w.Header().Set("Cache-Control", "max-age=2147483648, immutable") // url was of type bzz://<hex key>/path, so we are sure it is immutable.
 	}
 
 	log.Debug("handle.get.file: resolved", "ruid", ruid, "key", manifestAddr)
 
-	reader, contentType, status, contentKey, err := s.api.Get(r.Context(), s.api.Decryptor(r.Context(), credentials), manifestAddr, uri.Path)
-
-	etag := common.Bytes2Hex(contentKey)
-	noneMatchEtag := r.Header.Get("If-None-Match")
-	w.Header().Set("ETag", fmt.Sprintf("%q", etag)) // set etag to actual content key.
-	if noneMatchEtag != "" {
-		if bytes.Equal(storage.Address(common.Hex2Bytes(noneMatchEtag)), contentKey) {
-			w.WriteHeader(http.StatusNotModified)
-			return
-		}
-	}
-
+	reader, entry, status, contentKey, err := s.api.GetEntry(r.Context(), s.api.Decryptor(r.Context(), credentials), manifestAddr, uri.Path)
 	if err != nil {
 		if isDecryptError(err) {
 			w.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", manifestAddr))
@@ -971,6 +1172,14 @@ func (s *Server) HandleGetFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// set etag to actual content key, the content is immutable only if url
+	// was of type bzz://<hex key>/path and it was not resolved through a feed
+	s.setCacheHeaders(w, uri.Address() != nil && entry.Feed == nil, contentKey, entry.ModTime)
+	if notModified(r, contentKey) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	// check the root chunk exists by retrieving the file's size
 	if _, err := reader.Size(r.Context(), nil); err != nil {
 		getFileNotFound.Inc(1)
@@ -978,8 +1187,8 @@ func (s *Server) HandleGetFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if contentType != "" {
-		w.Header().Set("Content-Type", contentType)
+	if entry.ContentType != "" {
+		w.Header().Set("Content-Type", entry.ContentType)
 	}
 
 	fileName := uri.Addr
@@ -988,7 +1197,7 @@ func (s *Server) HandleGetFile(w http.ResponseWriter, r *http.Request) {
 	}
 	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", fileName))
 
-	http.ServeContent(w, r, fileName, time.Now(), langos.NewBufferedReadSeeker(reader, getFileBufferSize))
+	http.ServeContent(w, r, fileName, entry.ModTime, langos.NewBufferedReadSeeker(reader, getFileBufferSize))
 }
 
 // HandleGetTag responds to the following request
@@ -1126,6 +1335,264 @@ func (s *Server) HandleGetPins(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(&pinnedFiles)
 }
 
+// HandleGetExport handles a GET request to bzz-export:/<manifest> and
+// streams a tar archive with all chunks of the content, which can be
+// imported with a POST request to bzz-import:/
+func (s *Server) HandleGetExport(w http.ResponseWriter, r *http.Request) {
+	getExportCount.Inc(1)
+	ruid := GetRUID(r.Context())
+	uri := GetURI(r.Context())
+	log.Debug("handle.get.export", "ruid", ruid, "uri", r.RequestURI)
+
+	addr, err := s.api.Resolve(r.Context(), uri.Addr)
+	if err != nil {
+		getExportFail.Inc(1)
+		respondError(w, r, fmt.Sprintf("cannot resolve %s: %s", uri.Addr, err), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", tarContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.tar\"", addr.Hex()))
+	w.Header().Set("Trailer", ExportErrorTrailerName)
+	// the status is already written when the archive is streamed,
+	// errors are signalled with the trailer and the archive without its end
+	count, err := s.api.Export(r.Context(), addr, w)
+	if err != nil {
+		getExportFail.Inc(1)
+		log.Error("export failed", "ruid", ruid, "key", addr, "exported", count, "err", err)
+		w.Header().Set(ExportErrorTrailerName, err.Error())
+		return
+	}
+	log.Debug("exported content", "ruid", ruid, "key", addr, "chunks", count)
+}
+
+// HandlePostImport handles a POST request to bzz-import:/ with a tar
+// archive in the request body, as produced by bzz-export:/, and stores
+// its chunks. It responds with the number of imported chunks.
+func (s *Server) HandlePostImport(w http.ResponseWriter, r *http.Request) {
+	postImportCount.Inc(1)
+	ruid := GetRUID(r.Context())
+	log.Debug("handle.post.import", "ruid", ruid, "uri", r.RequestURI)
+
+	count, err := s.api.Import(r.Context(), r.Body)
+	if err != nil {
+		postImportFail.Inc(1)
+		respondError(w, r, fmt.Sprintf("error importing chunks (imported %d): %s", count, err), http.StatusBadRequest)
+		return
+	}
+
+	log.Debug("imported chunks", "ruid", ruid, "chunks", count)
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, count)
+}
+
+// HandleGetAudit handles a GET request to bzz-audit:/<hash>?challenge=<hex>
+// and responds with the JSON encoded storage.AuditProof that the content
+// with the hash is stored on this node, for the data chunk selected by
+// the challenge. The hash must be the root hash of unencrypted content,
+// as returned by bzz-raw:/ uploads.
+func (s *Server) HandleGetAudit(w http.ResponseWriter, r *http.Request) {
+	getAuditCount.Inc(1)
+	ruid := GetRUID(r.Context())
+	uri := GetURI(r.Context())
+	log.Debug("handle.get.audit", "ruid", ruid, "uri", r.RequestURI)
+
+	challenge, err := hex.DecodeString(strings.TrimPrefix(r.URL.Query().Get("challenge"), "0x"))
+	if err != nil || len(challenge) == 0 {
+		getAuditFail.Inc(1)
+		respondError(w, r, "missing or invalid hex encoded challenge", http.StatusBadRequest)
+		return
+	}
+
+	addr, err := s.api.Resolve(r.Context(), uri.Addr)
+	if err != nil {
+		getAuditFail.Inc(1)
+		respondError(w, r, fmt.Sprintf("cannot resolve %s: %s", uri.Addr, err), http.StatusNotFound)
+		return
+	}
+
+	proof, err := s.api.AuditProof(r.Context(), addr, challenge)
+	if err != nil {
+		getAuditFail.Inc(1)
+		switch err {
+		case storage.ErrChunkNotFound:
+			respondError(w, r, fmt.Sprintf("content %s is not stored locally", addr), http.StatusNotFound)
+		case storage.ErrAuditEncrypted:
+			respondError(w, r, err.Error(), http.StatusBadRequest)
+		default:
+			respondError(w, r, fmt.Sprintf("error creating audit proof for %s: %s", addr, err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(proof)
+}
+
+// AuditVerifyResponse is the JSON response to a POST request to bzz-audit:/<hash>.
+type AuditVerifyResponse struct {
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+// HandlePostAudit handles a POST request to bzz-audit:/<hash> with the
+// JSON encoded storage.AuditProof in the request body, as returned by
+// GET request to bzz-audit:/ on this or any other node. It responds
+// with the AuditVerifyResponse.
+func (s *Server) HandlePostAudit(w http.ResponseWriter, r *http.Request) {
+	postAuditCount.Inc(1)
+	ruid := GetRUID(r.Context())
+	uri := GetURI(r.Context())
+	log.Debug("handle.post.audit", "ruid", ruid, "uri", r.RequestURI)
+
+	addr, err := s.api.Resolve(r.Context(), uri.Addr)
+	if err != nil {
+		postAuditFail.Inc(1)
+		respondError(w, r, fmt.Sprintf("cannot resolve %s: %s", uri.Addr, err), http.StatusNotFound)
+		return
+	}
+
+	var proof storage.AuditProof
+	if err := json.NewDecoder(r.Body).Decode(&proof); err != nil {
+		postAuditFail.Inc(1)
+		respondError(w, r, fmt.Sprintf("invalid audit proof: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	var res AuditVerifyResponse
+	if err := s.api.VerifyAuditProof(addr, &proof); err != nil {
+		log.Debug("audit proof not valid", "ruid", ruid, "key", addr, "err", err)
+		res.Error = err.Error()
+	} else {
+		res.Valid = true
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&res)
+}
+
+// HandlePostPrefetch handles a POST request to bzz-prefetch:/<hash>. It starts
+// retrieving all chunks of the content into the local store in the background
+// and responds with the tag that reports the progress in the x-swarm-tag header.
+// The tag is complete wrt the stored state once all chunks are stored.
+// Prefetch is cancelled after prefetchTimeout and at most maxPrefetches can be
+// in progress, otherwise the request is rejected with the service unavailable status.
+func (s *Server) HandlePostPrefetch(w http.ResponseWriter, r *http.Request) {
+	postPrefetchCount.Inc(1)
+	ruid := GetRUID(r.Context())
+	uri := GetURI(r.Context())
+	log.Debug("handle.post.prefetch", "ruid", ruid, "uri", r.RequestURI)
+
+	addr := uri.Address()
+	if addr == nil {
+		postPrefetchFail.Inc(1)
+		respondError(w, r, "missing hash to prefetch", http.StatusBadRequest)
+		return
+	}
+
+	tagUID := sctx.GetTag(r.Context())
+	tag, err := s.api.Tags.Get(tagUID)
+	if err != nil {
+		postPrefetchFail.Inc(1)
+		log.Error("handle post prefetch got an error retrieving tag", "ruid", ruid, "tagUID", tagUID, "err", err)
+		respondError(w, r, "prefetch tag not found", http.StatusInternalServerError)
+		return
+	}
+
+	select {
+	case s.prefetches <- struct{}{}:
+	default:
+		postPrefetchFail.Inc(1)
+		respondError(w, r, "too many prefetches in progress", http.StatusServiceUnavailable)
+		return
+	}
+
+	// the request context is cancelled when the response is sent
+	go func() {
+		defer func() { <-s.prefetches }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), prefetchTimeout)
+		defer cancel()
+
+		count, err := s.api.Prefetch(ctx, addr, tag)
+		if err != nil {
+			log.Warn("prefetch failed", "ruid", ruid, "key", addr, "tag", tagUID, "chunks", count, "err", err)
+			return
+		}
+		log.Debug("prefetched content", "ruid", ruid, "key", addr, "tag", tagUID, "chunks", count)
+	}()
+
+	w.Header().Set(TagHeaderName, fmt.Sprint(tagUID))
+	w.Header().Set("Access-Control-Expose-Headers", TagHeaderName)
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusAccepted)
+	fmt.Fprint(w, addr)
+}
+
+// HandlePostSOC handles a POST request to bzz-soc:/ with the signed
+// single-owner chunk data in the request body. The chunk is stored locally
+// and the chunk address, derived from its identifier and owner, is returned
+// as a text/plain response.
+func (s *Server) HandlePostSOC(w http.ResponseWriter, r *http.Request) {
+	postSOCCount.Inc(1)
+	ruid := GetRUID(r.Context())
+	log.Debug("handle.post.soc", "ruid", ruid, "uri", r.RequestURI)
+
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, soc.MaxChunkSize+1))
+	if err != nil {
+		postSOCFail.Inc(1)
+		respondError(w, r, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	addr, err := s.api.SOCPut(r.Context(), data)
+	if err != nil {
+		postSOCFail.Inc(1)
+		respondError(w, r, fmt.Sprintf("cannot store single-owner chunk: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, addr)
+}
+
+// HandleGetSOC handles a GET request to bzz-soc:/<owner>/<id> and responds
+// with the payload of the single-owner chunk with the hex encoded identifier
+// of the owner.
+func (s *Server) HandleGetSOC(w http.ResponseWriter, r *http.Request) {
+	getSOCCount.Inc(1)
+	ruid := GetRUID(r.Context())
+	uri := GetURI(r.Context())
+	log.Debug("handle.get.soc", "ruid", ruid, "uri", r.RequestURI)
+
+	if !common.IsHexAddress(uri.Addr) {
+		getSOCFail.Inc(1)
+		respondError(w, r, fmt.Sprintf("invalid owner address %q", uri.Addr), http.StatusBadRequest)
+		return
+	}
+	owner := common.HexToAddress(uri.Addr)
+
+	id, err := hex.DecodeString(strings.TrimPrefix(uri.Path, "0x"))
+	if err != nil || len(id) != soc.IDSize {
+		getSOCFail.Inc(1)
+		respondError(w, r, fmt.Sprintf("invalid single-owner chunk id %q", uri.Path), http.StatusBadRequest)
+		return
+	}
+
+	sc, err := s.api.SOCGet(r.Context(), owner, id)
+	if err != nil {
+		getSOCFail.Inc(1)
+		respondError(w, r, fmt.Sprintf("single-owner chunk not found: %s", err), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", api.MimeOctetStream)
+	w.WriteHeader(http.StatusOK)
+	w.Write(sc.Payload)
+}
+
 // calculateNumberOfChunks calculates the number of chunks in an arbitrary content length
 func calculateNumberOfChunks(contentLength int64, isEncrypted bool) int64 {
 	if contentLength < 4096 {
@@ -1172,3 +1639,116 @@ func (lrw *loggingResponseWriter) WriteHeader(code int) {
 func isDecryptError(err error) bool {
 	return strings.Contains(err.Error(), api.ErrDecrypt.Error())
 }
+
+// HandleGetKeys handles a GET request to bzz-keys:/ and responds
+// with the list of API keys. The key secrets are not included.
+func (s *Server) HandleGetKeys(w http.ResponseWriter, r *http.Request) {
+	getKeysCount.Inc(1)
+	ruid := GetRUID(r.Context())
+	log.Debug("handle.get.keys", "ruid", ruid, "uri", r.RequestURI)
+
+	keys, err := s.keys.List()
+	if err != nil {
+		getKeysFail.Inc(1)
+		respondError(w, r, fmt.Sprintf("error getting api keys: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&keys)
+}
+
+// CreateKeyResponse is the JSON response to a POST request to bzz-keys:/.
+// Secret is the value that clients need to send in the APIKeyHeaderName header
+// and it can not be retrieved later.
+type CreateKeyResponse struct {
+	*auth.Key
+	Secret string `json:"secret"`
+}
+
+// HandlePostKey handles a POST request to bzz-keys:/ with the JSON encoded
+// auth.Key settings in the request body. It creates a new API key and
+// responds with the CreateKeyResponse.
+func (s *Server) HandlePostKey(w http.ResponseWriter, r *http.Request) {
+	postKeyCount.Inc(1)
+	ruid := GetRUID(r.Context())
+	log.Debug("handle.post.key", "ruid", ruid, "uri", r.RequestURI)
+
+	var settings auth.Key
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		postKeyFail.Inc(1)
+		respondError(w, r, fmt.Sprintf("invalid api key settings: %s", err), http.StatusBadRequest)
+		return
+	}
+	if settings.RateLimit < 0 || settings.UploadQuota < 0 {
+		postKeyFail.Inc(1)
+		respondError(w, r, "rate limit and upload quota must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	secret, key, err := s.keys.Create(settings)
+	if err != nil {
+		postKeyFail.Inc(1)
+		respondError(w, r, fmt.Sprintf("error creating api key: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	log.Debug("created api key", "ruid", ruid, "name", key.Name, "id", key.ID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(&CreateKeyResponse{
+		Key:    key,
+		Secret: secret,
+	})
+}
+
+// HandleDeleteKey handles a DELETE request to bzz-keys:/<id> and removes the API key.
+func (s *Server) HandleDeleteKey(w http.ResponseWriter, r *http.Request) {
+	deleteKeyCount.Inc(1)
+	ruid := GetRUID(r.Context())
+	uri := GetURI(r.Context())
+	log.Debug("handle.delete.key", "ruid", ruid, "uri", r.RequestURI)
+
+	if uri.Addr == "" {
+		deleteKeyFail.Inc(1)
+		respondError(w, r, "missing api key id", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.keys.Delete(uri.Addr); err != nil {
+		deleteKeyFail.Inc(1)
+		if err == auth.ErrKeyNotFound {
+			respondError(w, r, fmt.Sprintf("api key %s not found", uri.Addr), http.StatusNotFound)
+			return
+		}
+		respondError(w, r, fmt.Sprintf("error deleting api key %s: %s", uri.Addr, err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleGetStatus handles a GET request to bzz-debug/status and responds
+// with the aggregated node status as JSON.
+func (s *Server) HandleGetStatus(w http.ResponseWriter, r *http.Request) {
+	getStatusCount.Inc(1)
+	ruid := GetRUID(r.Context())
+	log.Debug("handle.get.status", "ruid", ruid, "uri", r.RequestURI)
+
+	if s.inspector == nil {
+		getStatusFail.Inc(1)
+		respondError(w, r, "Node status is not available", http.StatusNotFound)
+		return
+	}
+
+	status, err := s.inspector.Status()
+	if err != nil {
+		getStatusFail.Inc(1)
+		respondError(w, r, fmt.Sprintf("error getting node status: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
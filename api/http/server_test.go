@@ -30,6 +30,7 @@ import (
 	"math/big"
 	"mime/multipart"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"path"
@@ -43,12 +44,16 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethersphere/swarm/api"
+	"github.com/ethersphere/swarm/api/http/auth"
 	"github.com/ethersphere/swarm/chunk"
 	chunktesting "github.com/ethersphere/swarm/chunk/testing"
+	"github.com/ethersphere/swarm/sctx"
 	"github.com/ethersphere/swarm/storage"
 	"github.com/ethersphere/swarm/storage/feed"
 	"github.com/ethersphere/swarm/storage/feed/lookup"
+	"github.com/ethersphere/swarm/storage/localstore"
 	"github.com/ethersphere/swarm/storage/pin"
+	"github.com/ethersphere/swarm/storage/soc"
 	"github.com/ethersphere/swarm/testutil"
 )
 
@@ -57,7 +62,7 @@ func init() {
 }
 
 func serverFunc(api *api.API, pinAPI *pin.API) TestServer {
-	return NewServer(api, pinAPI, "")
+	return NewServer(api, pinAPI, nil, "")
 }
 
 func newTestSigner() (*feed.GenericSigner, *ecdsa.PrivateKey, error) {
@@ -472,6 +477,11 @@ func TestBzzWithFeed(t *testing.T) {
 	if !bytes.Equal(retrievedData, dataBytes) {
 		t.Fatalf("retrieved data mismatch, expected %x, got %x", dataBytes, retrievedData)
 	}
+	// the feed manifest hash is fixed, but the content it resolves to
+	// changes with feed updates, so it must not be cached as immutable
+	if h := resp.Header.Get("Cache-Control"); h != "no-cache" {
+		t.Fatalf("got Cache-Control %q, want %q", h, "no-cache")
+	}
 }
 
 // Test Swarm feeds using the raw update methods
@@ -1617,6 +1627,187 @@ func TestBzzGetFileWithResolver(t *testing.T) {
 	}
 }
 
+// TestBzzCacheHeaders tests the ETag, Last-Modified and Cache-Control
+// headers of bzz:/ and bzz-raw:/ responses and conditional requests with
+// the If-None-Match header.
+func TestBzzCacheHeaders(t *testing.T) {
+	resolver := newTestResolveValidator("")
+	srv := NewTestSwarmServer(t, serverFunc, resolver, nil)
+	defer srv.Close()
+
+	modTime := time.Unix(1500000000, 0)
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	data := "cached data"
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    "file.txt",
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(tw, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	res, manifestHash := httpDo("POST", srv.URL+"/bzz:/", buf, map[string]string{"Content-Type": "application/x-tar"}, false, t)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code from server %d want %d", res.StatusCode, http.StatusOK)
+	}
+	hash := common.HexToHash(manifestHash)
+	resolver.hash = &hash
+
+	fileURL := fmt.Sprintf("%s/bzz:/%s/file.txt", srv.URL, manifestHash)
+	res, body := httpDo("GET", fileURL, nil, nil, false, t)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d but got %d", http.StatusOK, res.StatusCode)
+	}
+	if body != data {
+		t.Fatalf("got body %q, want %q", body, data)
+	}
+	etag := res.Header.Get("ETag")
+	if len(etag) != 66 || etag == fmt.Sprintf("%q", manifestHash) {
+		t.Fatalf("got etag %s, want the quoted file content hash", etag)
+	}
+	if h := res.Header.Get("Last-Modified"); h != modTime.UTC().Format(http.TimeFormat) {
+		t.Fatalf("got Last-Modified %q, want %q", h, modTime.UTC().Format(http.TimeFormat))
+	}
+	if h := res.Header.Get("Cache-Control"); h != "max-age=2147483648, immutable" {
+		t.Fatalf("got Cache-Control %q", h)
+	}
+
+	for _, tc := range []struct {
+		name         string
+		url          string
+		ifNoneMatch  string
+		wantStatus   int
+		wantETag     string
+		cacheControl string
+	}{
+		{
+			name:         "no condition",
+			url:          fileURL,
+			wantStatus:   http.StatusOK,
+			wantETag:     etag,
+			cacheControl: "max-age=2147483648, immutable",
+		},
+		{
+			name:         "matching etag",
+			url:          fileURL,
+			ifNoneMatch:  etag,
+			wantStatus:   http.StatusNotModified,
+			wantETag:     etag,
+			cacheControl: "max-age=2147483648, immutable",
+		},
+		{
+			name:         "weak etag in list",
+			url:          fileURL,
+			ifNoneMatch:  `"abcd", W/` + etag,
+			wantStatus:   http.StatusNotModified,
+			wantETag:     etag,
+			cacheControl: "max-age=2147483648, immutable",
+		},
+		{
+			name:         "any etag",
+			url:          fileURL,
+			ifNoneMatch:  "*",
+			wantStatus:   http.StatusNotModified,
+			wantETag:     etag,
+			cacheControl: "max-age=2147483648, immutable",
+		},
+		{
+			name:         "other etag",
+			url:          fileURL,
+			ifNoneMatch:  fmt.Sprintf("%q", manifestHash),
+			wantStatus:   http.StatusOK,
+			wantETag:     etag,
+			cacheControl: "max-age=2147483648, immutable",
+		},
+		{
+			name:         "unquoted etag",
+			url:          fileURL,
+			ifNoneMatch:  strings.Trim(etag, `"`),
+			wantStatus:   http.StatusOK,
+			wantETag:     etag,
+			cacheControl: "max-age=2147483648, immutable",
+		},
+		{
+			name:         "ens name",
+			url:          srv.URL + "/bzz:/swarm.eth/file.txt",
+			ifNoneMatch:  etag,
+			wantStatus:   http.StatusNotModified,
+			wantETag:     etag,
+			cacheControl: "no-cache",
+		},
+		{
+			name:         "raw manifest",
+			url:          fmt.Sprintf("%s/bzz-raw:/%s", srv.URL, manifestHash),
+			ifNoneMatch:  fmt.Sprintf("%q", manifestHash),
+			wantStatus:   http.StatusNotModified,
+			wantETag:     fmt.Sprintf("%q", manifestHash),
+			cacheControl: "max-age=2147483648, immutable",
+		},
+		{
+			name:         "raw ens name",
+			url:          srv.URL + "/bzz-raw:/swarm.eth",
+			wantStatus:   http.StatusOK,
+			wantETag:     fmt.Sprintf("%q", manifestHash),
+			cacheControl: "no-cache",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tc.ifNoneMatch != "" {
+				headers["If-None-Match"] = tc.ifNoneMatch
+			}
+			res, _ := httpDo("GET", tc.url, nil, headers, false, t)
+			if res.StatusCode != tc.wantStatus {
+				t.Fatalf("expected status code %d but got %d", tc.wantStatus, res.StatusCode)
+			}
+			if h := res.Header.Get("ETag"); h != tc.wantETag {
+				t.Fatalf("got ETag %s, want %s", h, tc.wantETag)
+			}
+			if h := res.Header.Get("Cache-Control"); h != tc.cacheControl {
+				t.Fatalf("got Cache-Control %q, want %q", h, tc.cacheControl)
+			}
+		})
+	}
+}
+
+func TestCacheOptions(t *testing.T) {
+	for _, tc := range []struct {
+		options   CacheOptions
+		immutable string
+		ens       string
+	}{
+		{
+			options:   defaultCacheOptions,
+			immutable: "max-age=2147483648, immutable",
+			ens:       "no-cache",
+		},
+		{
+			options:   CacheOptions{},
+			immutable: "no-cache",
+			ens:       "no-cache",
+		},
+		{
+			options:   CacheOptions{MaxAge: 24 * time.Hour, ENSMaxAge: time.Minute},
+			immutable: "max-age=86400, immutable",
+			ens:       "max-age=60",
+		},
+	} {
+		if got := tc.options.cacheControl(true); got != tc.immutable {
+			t.Errorf("%+v: got immutable Cache-Control %q, want %q", tc.options, got, tc.immutable)
+		}
+		if got := tc.options.cacheControl(false); got != tc.ens {
+			t.Errorf("%+v: got ENS Cache-Control %q, want %q", tc.options, got, tc.ens)
+		}
+	}
+}
+
 // TestCalculateNumberOfChunks is a unit test for the chunk-number-according-to-content-length
 // calculation
 func TestCalculateNumberOfChunks(t *testing.T) {
@@ -1759,3 +1950,580 @@ func unpinFile(t *testing.T, srv *TestSwarmServer, rootHash []byte) []byte {
 	}
 	return unpinMessage
 }
+
+// TestAPIKeys validates authentication with API keys, key management
+// with the admin key and enforcement of upload quotas.
+func TestAPIKeys(t *testing.T) {
+	keysDir, err := ioutil.TempDir("", "swarm-api-keys-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(keysDir)
+
+	keys, err := auth.NewStore(keysDir, "admin secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer keys.Close()
+
+	srv := NewTestSwarmServer(t, func(api *api.API, pinAPI *pin.API) TestServer {
+		return NewServer(api, pinAPI, keys, "")
+	}, nil, nil)
+	defer srv.Close()
+
+	do := func(method, path, secret string, body []byte) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(method, srv.URL+path, bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if secret != "" {
+			req.Header.Set(APIKeyHeaderName, secret)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	expectStatus := func(res *http.Response, want int) {
+		t.Helper()
+		res.Body.Close()
+		if res.StatusCode != want {
+			t.Fatalf("got status %s, want %v", res.Status, want)
+		}
+	}
+
+	data := testutil.RandomBytes(1, 100)
+
+	expectStatus(do("POST", "/bzz-raw:/", "", data), http.StatusUnauthorized)
+	expectStatus(do("POST", "/bzz-raw:/", "invalid", data), http.StatusUnauthorized)
+	expectStatus(do("GET", "/bzz-keys:/", "", nil), http.StatusUnauthorized)
+
+	res := do("POST", "/bzz-keys:/", "admin secret", []byte(`{"name":"user","uploadQuota":150}`))
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("got status %s, want %v", res.Status, http.StatusCreated)
+	}
+	var created CreateKeyResponse
+	if err := json.NewDecoder(res.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if created.Secret == "" || created.Name != "user" || created.UploadQuota != 150 {
+		t.Fatalf("got created key %+v", created)
+	}
+
+	// user key can upload within its quota, but can not manage keys
+	expectStatus(do("POST", "/bzz-raw:/", created.Secret, data), http.StatusOK)
+	expectStatus(do("POST", "/bzz-raw:/", created.Secret, data), http.StatusForbidden)
+	expectStatus(do("POST", "/bzz-feed:/", created.Secret, data), http.StatusForbidden)
+	expectStatus(do("GET", "/bzz-keys:/", created.Secret, nil), http.StatusForbidden)
+
+	res = do("GET", "/bzz-keys:/", "admin secret", nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got status %s, want %v", res.Status, http.StatusOK)
+	}
+	var list []*auth.Key
+	if err := json.NewDecoder(res.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if len(list) != 1 || list[0].ID != created.ID || list[0].Uploaded != int64(len(data)) {
+		t.Fatalf("got keys %+v", list)
+	}
+
+	expectStatus(do("DELETE", "/bzz-keys:/"+created.ID, "admin secret", nil), http.StatusOK)
+	expectStatus(do("DELETE", "/bzz-keys:/"+created.ID, "admin secret", nil), http.StatusNotFound)
+	expectStatus(do("GET", "/bzz-raw:/", created.Secret, nil), http.StatusUnauthorized)
+}
+
+// TestBzzAudit validates creating an audit proof for uploaded
+// content and its verification over the HTTP API.
+func TestBzzAudit(t *testing.T) {
+	srv := NewTestSwarmServer(t, serverFunc, nil, nil)
+	defer srv.Close()
+
+	data := testutil.RandomBytes(1, 3*4096+100)
+	resp, err := http.Post(fmt.Sprintf("%s/bzz-raw:/", srv.URL), "text/plain", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got upload status %s", resp.Status)
+	}
+	root := string(rootHash)
+
+	resp, err = http.Get(fmt.Sprintf("%s/bzz-audit:/%s", srv.URL, root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("got status %s without challenge, want %v", resp.Status, http.StatusBadRequest)
+	}
+
+	resp, err = http.Get(fmt.Sprintf("%s/bzz-audit:/%s?challenge=%x", srv.URL, root, testutil.RandomBytes(2, 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %s, want %v", resp.Status, http.StatusOK)
+	}
+
+	for _, tc := range []struct {
+		root  string
+		valid bool
+	}{
+		{root: root, valid: true},
+		{root: hex.EncodeToString(make([]byte, 32)), valid: false},
+	} {
+		resp, err = http.Post(fmt.Sprintf("%s/bzz-audit:/%s", srv.URL, tc.root), "application/json", bytes.NewReader(proof))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var res AuditVerifyResponse
+		err = json.NewDecoder(resp.Body).Decode(&res)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Valid != tc.valid {
+			t.Errorf("root %s: got valid %v, want %v (%s)", tc.root, res.Valid, tc.valid, res.Error)
+		}
+	}
+}
+
+// TestBzzPrefetch tests that bzz-prefetch:/ starts retrieval of all content
+// chunks and reports the progress with a tag.
+func TestBzzPrefetch(t *testing.T) {
+	srv := NewTestSwarmServer(t, serverFunc, nil, nil)
+	defer srv.Close()
+
+	data := testutil.RandomBytes(1, 100000)
+	resp, err := http.Post(fmt.Sprintf("%s/bzz-raw:/", srv.URL), "text/plain", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got upload status %s", resp.Status)
+	}
+
+	resp, err = http.Post(fmt.Sprintf("%s/bzz-prefetch:/", srv.URL), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("got status %s without hash, want %v", resp.Status, http.StatusBadRequest)
+	}
+
+	resp, err = http.Post(fmt.Sprintf("%s/bzz-prefetch:/%s", srv.URL, rootHash), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("got status %s, want %v", resp.Status, http.StatusAccepted)
+	}
+	tagUID := resp.Header.Get(TagHeaderName)
+	if tagUID == "" {
+		t.Fatal("missing tag header")
+	}
+
+	var tag chunk.Tag
+	timeout := time.After(10 * time.Second)
+	for {
+		resp, err = http.Get(fmt.Sprintf("%s/bzz-tag:/?Id=%s", srv.URL, tagUID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&tag)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tag.Total > 0 && tag.Stored == tag.Total {
+			break
+		}
+		select {
+		case <-timeout:
+			t.Fatalf("timeout waiting for prefetch, got %v stored of %v", tag.Stored, tag.Total)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	// 25 data chunks and one intermediate chunk
+	if tag.Total != 26 {
+		t.Fatalf("got %v prefetched chunks, want 26", tag.Total)
+	}
+	if tag.Address.Hex() != string(rootHash) {
+		t.Fatalf("got tag address %s, want %s", tag.Address.Hex(), rootHash)
+	}
+}
+
+// TestBzzPrefetchLimit tests that bzz-prefetch:/ requests are rejected
+// when the maximal number of prefetches is in progress.
+func TestBzzPrefetchLimit(t *testing.T) {
+	defer func(m int) { maxPrefetches = m }(maxPrefetches)
+	maxPrefetches = 0
+
+	srv := NewTestSwarmServer(t, serverFunc, nil, nil)
+	defer srv.Close()
+
+	resp, err := http.Post(fmt.Sprintf("%s/bzz-prefetch:/%s", srv.URL, hex.EncodeToString(testutil.RandomBytes(1, 32))), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("got status %s, want %v", resp.Status, http.StatusServiceUnavailable)
+	}
+}
+
+// TestBzzSOC tests uploading and retrieving single-owner chunks
+// with bzz-soc:/ requests.
+func TestBzzSOC(t *testing.T) {
+	srv := NewTestSwarmServer(t, serverFunc, nil, nil)
+	defer srv.Close()
+
+	signer, _, err := newTestSigner()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := make([]byte, soc.IDSize)
+	id[0] = 1
+	payload := []byte("single owner chunk payload")
+
+	ch, err := soc.NewChunk(id, payload, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Post(fmt.Sprintf("%s/bzz-soc:/", srv.URL), "application/octet-stream", bytes.NewReader(ch.Data()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got upload status %s", resp.Status)
+	}
+	if string(addr) != ch.Address().Hex() {
+		t.Fatalf("got address %s, want %s", addr, ch.Address().Hex())
+	}
+
+	owner := strings.TrimPrefix(signer.Address().Hex(), "0x")
+
+	resp, err = http.Get(fmt.Sprintf("%s/bzz-soc:/%s/%x", srv.URL, owner, id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got download status %s", resp.Status)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Fatalf("got payload %q, want %q", got, payload)
+	}
+
+	otherID := make([]byte, soc.IDSize)
+	for _, tc := range []struct {
+		method string
+		url    string
+		body   []byte
+		status int
+	}{
+		{"POST", fmt.Sprintf("%s/bzz-soc:/", srv.URL), ch.Data()[:soc.IDSize], http.StatusBadRequest},
+		{"GET", fmt.Sprintf("%s/bzz-soc:/%s/%x", srv.URL, owner, otherID), nil, http.StatusNotFound},
+		{"GET", fmt.Sprintf("%s/bzz-soc:/%s/%x", srv.URL, "invalid", id), nil, http.StatusBadRequest},
+		{"GET", fmt.Sprintf("%s/bzz-soc:/%s/%x", srv.URL, owner, id[:10]), nil, http.StatusBadRequest},
+	} {
+		req, err := http.NewRequest(tc.method, tc.url, bytes.NewReader(tc.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.status {
+			t.Errorf("%s %s: got status %s, want %v", tc.method, tc.url, resp.Status, tc.status)
+		}
+	}
+}
+
+// TestSetRetrieveOptions tests that the retrieve timeout and hop limit
+// request headers are validated and set in the request context.
+func TestSetRetrieveOptions(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		timeout  string
+		hops     string
+		status   int
+		deadline bool
+		hopLimit uint8
+	}{
+		{name: "none", status: http.StatusOK},
+		{name: "timeout", timeout: "500ms", status: http.StatusOK, deadline: true},
+		{name: "hops", hops: "2", status: http.StatusOK, hopLimit: 2},
+		{name: "both", timeout: "1s", hops: "5", status: http.StatusOK, deadline: true, hopLimit: 5},
+		{name: "invalid timeout", timeout: "soon", status: http.StatusBadRequest},
+		{name: "negative timeout", timeout: "-1s", status: http.StatusBadRequest},
+		{name: "zero hops", hops: "0", status: http.StatusBadRequest},
+		{name: "too many hops", hops: "256", status: http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var called bool
+			h := SetRetrieveOptions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				if _, ok := r.Context().Deadline(); ok != tc.deadline {
+					t.Errorf("got deadline %v, want %v", ok, tc.deadline)
+				}
+				if got := sctx.GetHopLimit(r.Context()); got != tc.hopLimit {
+					t.Errorf("got hop limit %v, want %v", got, tc.hopLimit)
+				}
+			}))
+
+			req := httptest.NewRequest("GET", "/bzz-raw:/", nil)
+			if tc.timeout != "" {
+				req.Header.Set(RetrieveTimeoutHeaderName, tc.timeout)
+			}
+			if tc.hops != "" {
+				req.Header.Set(RetrieveHopsHeaderName, tc.hops)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if w.Code != tc.status {
+				t.Errorf("got status %v, want %v", w.Code, tc.status)
+			}
+			if called != (tc.status == http.StatusOK) {
+				t.Errorf("got handler called %v", called)
+			}
+		})
+	}
+}
+
+func TestAttachUploadStamp(t *testing.T) {
+	stamp := bytes.Repeat([]byte{0xab}, chunk.StampSize)
+	rejected := bytes.Repeat([]byte{0xcd}, chunk.StampSize)
+	validate := func(ctx context.Context, s []byte) error {
+		if bytes.Equal(s, rejected) {
+			return errors.New("batch has no balance")
+		}
+		return nil
+	}
+
+	for _, tc := range []struct {
+		name   string
+		header string
+		status int
+		stamp  []byte
+	}{
+		{name: "none", status: http.StatusOK},
+		{name: "valid", header: hex.EncodeToString(stamp), status: http.StatusOK, stamp: stamp},
+		{name: "prefixed", header: "0x" + hex.EncodeToString(stamp), status: http.StatusOK, stamp: stamp},
+		{name: "not hex", header: "stamp", status: http.StatusBadRequest},
+		{name: "short", header: hex.EncodeToString(stamp[1:]), status: http.StatusBadRequest},
+		{name: "rejected", header: hex.EncodeToString(rejected), status: http.StatusPaymentRequired},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var called bool
+			h := AttachUploadStamp(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				if got := sctx.GetStamp(r.Context()); !bytes.Equal(got, tc.stamp) {
+					t.Errorf("got stamp %x, want %x", got, tc.stamp)
+				}
+			}), validate)
+
+			req := httptest.NewRequest("POST", "/bzz-raw:/", nil)
+			if tc.header != "" {
+				req.Header.Set(StampHeaderName, tc.header)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if w.Code != tc.status {
+				t.Errorf("got status %v, want %v", w.Code, tc.status)
+			}
+			if called != (tc.status == http.StatusOK) {
+				t.Errorf("got handler called %v", called)
+			}
+		})
+	}
+}
+
+// TestBzzDebugStatus validates that bzz-debug/status responds with
+// the node status provided by the inspector, and with 404 if there
+// is no inspector.
+func TestBzzDebugStatus(t *testing.T) {
+	dir, err := ioutil.TempDir("", "swarm-status-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ls, err := localstore.New(dir, make([]byte, 32), &localstore.Options{Capacity: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ls.Close()
+
+	keysDir, err := ioutil.TempDir("", "swarm-status-keys-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(keysDir)
+
+	keys, err := auth.NewStore(keysDir, "admin secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer keys.Close()
+	userSecret, _, err := keys.Create(auth.Key{Name: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	getStatus := func(srvURL, secret string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest("GET", srvURL+"/bzz-debug/status", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(APIKeyHeaderName, secret)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	chunks := []chunk.Chunk{
+		storage.GenerateRandomChunk(chunk.DefaultSize),
+		storage.GenerateRandomChunk(chunk.DefaultSize),
+	}
+	if _, err := ls.Put(context.Background(), chunk.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+	if err := ls.Set(context.Background(), chunk.ModeSetSyncPush, chunks[0].Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := NewTestSwarmServer(t, func(a *api.API, pinAPI *pin.API) TestServer {
+		server := NewServer(a, pinAPI, keys, "")
+		server.SetInspector(api.NewInspector(a, nil, nil, nil, ls, nil))
+		return server
+	}, nil, nil)
+	defer srv.Close()
+
+	res := getStatus(srv.URL, userSecret)
+	res.Body.Close()
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("got status %s with non-admin key, want %v", res.Status, http.StatusForbidden)
+	}
+
+	res = getStatus(srv.URL, "admin secret")
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got status %s, want %v", res.Status, http.StatusOK)
+	}
+	if ct := res.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("got content type %q, want %q", ct, "application/json")
+	}
+
+	var status api.NodeStatus
+	if err := json.NewDecoder(res.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Version == "" {
+		t.Error("version is not set")
+	}
+	if status.Kademlia != nil {
+		t.Error("unexpected kademlia status without hive")
+	}
+	if status.Accounting != nil {
+		t.Error("unexpected accounting status without swap")
+	}
+	if status.Sync == nil {
+		t.Fatal("sync status is not set")
+	}
+	if status.Sync.PushIndexSize != 1 {
+		t.Errorf("got push index size %v, want %v", status.Sync.PushIndexSize, 1)
+	}
+	if status.Sync.PullIndexSize != 2 {
+		t.Errorf("got pull index size %v, want %v", status.Sync.PullIndexSize, 2)
+	}
+	if status.Storage == nil {
+		t.Fatal("storage status is not set")
+	}
+	if status.Storage.Capacity != 1000 {
+		t.Errorf("got capacity %v, want %v", status.Storage.Capacity, 1000)
+	}
+	if status.Storage.GCSize != 1 {
+		t.Errorf("got gcSize %v, want %v", status.Storage.GCSize, 1)
+	}
+	if status.Storage.Utilization != 0.001 {
+		t.Errorf("got utilization %v, want %v", status.Storage.Utilization, 0.001)
+	}
+	if !status.Storage.Ready {
+		t.Error("storage is not ready")
+	}
+
+	t.Run("no inspector", func(t *testing.T) {
+		srv := NewTestSwarmServer(t, func(a *api.API, pinAPI *pin.API) TestServer {
+			return NewServer(a, pinAPI, keys, "")
+		}, nil, nil)
+		defer srv.Close()
+
+		res := getStatus(srv.URL, "admin secret")
+		res.Body.Close()
+		if res.StatusCode != http.StatusNotFound {
+			t.Fatalf("got status %s, want %v", res.Status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("keys disabled", func(t *testing.T) {
+		srv := NewTestSwarmServer(t, func(a *api.API, pinAPI *pin.API) TestServer {
+			server := NewServer(a, pinAPI, nil, "")
+			server.SetInspector(api.NewInspector(a, nil, nil, nil, ls, nil))
+			return server
+		}, nil, nil)
+		defer srv.Close()
+
+		res := getStatus(srv.URL, "")
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("got status %s, want %v", res.Status, http.StatusOK)
+		}
+		var status api.NodeStatus
+		if err := json.NewDecoder(res.Body).Decode(&status); err != nil {
+			t.Fatal(err)
+		}
+		if status.Storage == nil {
+			t.Error("storage status is not set")
+		}
+	})
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/ethersphere/swarm/network/stream"
 	"github.com/ethersphere/swarm/storage"
 	"github.com/ethersphere/swarm/storage/localstore"
+	"github.com/ethersphere/swarm/swap"
 )
 
 const InspectorIsPullSyncingTolerance = 15 * time.Second
@@ -40,10 +41,11 @@ type Inspector struct {
 	netStore *storage.NetStore
 	stream   *stream.Registry
 	ls       *localstore.DB
+	swap     *swap.Swap
 }
 
-func NewInspector(api *API, hive *network.Hive, netStore *storage.NetStore, pullSyncer *stream.Registry, ls *localstore.DB) *Inspector {
-	return &Inspector{api, hive, netStore, pullSyncer, ls}
+func NewInspector(api *API, hive *network.Hive, netStore *storage.NetStore, pullSyncer *stream.Registry, ls *localstore.DB, swap *swap.Swap) *Inspector {
+	return &Inspector{api, hive, netStore, pullSyncer, ls, swap}
 }
 
 // Hive prints the kademlia table
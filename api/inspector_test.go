@@ -42,7 +42,7 @@ func TestInspectorPeerStreams(t *testing.T) {
 	i := NewInspector(nil, nil, netStore, stream.New(state.NewInmemoryStore(), baseAddress, stream.NewSyncProvider(netStore, network.NewKademlia(
 		baseKey,
 		network.NewKadParams(),
-	), baseAddress, false, false)), localStore)
+	), baseAddress, false, false)), localStore, nil)
 
 	server := rpc.NewServer()
 	if err := server.RegisterName("inspector", i); err != nil {
@@ -88,7 +88,7 @@ func TestInspectorStorageIndices(t *testing.T) {
 	i := NewInspector(nil, nil, netStore, stream.New(state.NewInmemoryStore(), network.NewBzzAddr(baseKey, baseKey), stream.NewSyncProvider(netStore, network.NewKademlia(
 		baseKey,
 		network.NewKadParams(),
-	), baseAddress, false, false)), localStore)
+	), baseAddress, false, false)), localStore, nil)
 
 	server := rpc.NewServer()
 	if err := server.RegisterName("inspector", i); err != nil {
@@ -107,3 +107,75 @@ func TestInspectorStorageIndices(t *testing.T) {
 		t.Fatalf("expected gcSize to be %d but got %d", 0, indiceInfo["gcSize"])
 	}
 }
+
+// TestInspectorStatus validates that response from RPC status
+// aggregates kademlia and storage state.
+func TestInspectorStatus(t *testing.T) {
+	dir, err := ioutil.TempDir("", "swarm-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	baseKey := make([]byte, 32)
+	_, err = rand.Read(baseKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	localStore, err := localstore.New(dir, baseKey, &localstore.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer localStore.Close()
+
+	kad := network.NewKademlia(baseKey, network.NewKadParams())
+	if err := kad.Register(network.RandomBzzAddr(), network.RandomBzzAddr(), network.RandomBzzAddr()); err != nil {
+		t.Fatal(err)
+	}
+	hive := network.NewHive(network.NewHiveParams(), kad, state.NewInmemoryStore())
+
+	i := NewInspector(nil, hive, nil, nil, localStore, nil)
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("inspector", i); err != nil {
+		t.Fatal(err)
+	}
+
+	client := rpc.DialInProc(server)
+
+	var status NodeStatus
+
+	err = client.Call(&status, "inspector_status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Kademlia == nil {
+		t.Fatal("kademlia status is not set")
+	}
+	if status.Kademlia.TotalKnown != 3 {
+		t.Errorf("got %v known peers, want %v", status.Kademlia.TotalKnown, 3)
+	}
+	var known int
+	for _, n := range status.Kademlia.Known {
+		known += n
+	}
+	if known != 3 {
+		t.Errorf("got %v known peers in bins, want %v", known, 3)
+	}
+	if status.Kademlia.TotalConnections != 0 {
+		t.Errorf("got %v connections, want %v", status.Kademlia.TotalConnections, 0)
+	}
+	if status.Storage == nil {
+		t.Fatal("storage status is not set")
+	}
+	if status.Storage.Schema != localstore.DbSchemaCurrent {
+		t.Errorf("got schema %q, want %q", status.Storage.Schema, localstore.DbSchemaCurrent)
+	}
+	if status.Sync == nil {
+		t.Fatal("sync status is not set")
+	}
+	if status.Accounting != nil {
+		t.Error("unexpected accounting status without swap")
+	}
+}
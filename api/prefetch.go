@@ -0,0 +1,74 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/storage"
+	"github.com/ethersphere/swarm/storage/traversal"
+)
+
+var (
+	apiPrefetchCount  = metrics.NewRegisteredCounter("api/prefetch/count", nil)
+	apiPrefetchChunks = metrics.NewRegisteredCounter("api/prefetch/chunks", nil)
+	apiPrefetchFail   = metrics.NewRegisteredCounter("api/prefetch/fail", nil)
+)
+
+// Prefetch retrieves all chunks that belong to the content with the root
+// address, including manifests and feed updates referenced by it, so that
+// they are stored in the local store. Chunks that are not stored locally are
+// requested from the network. Progress is reported on the tag, if it is not
+// nil: split and stored counts are incremented for every retrieved chunk and
+// the total is set once all chunks are retrieved. It returns the number of
+// retrieved chunks.
+func (a *API) Prefetch(ctx context.Context, root storage.Address, tag *chunk.Tag) (count int64, err error) {
+	apiPrefetchCount.Inc(1)
+	defer func() {
+		if err != nil {
+			apiPrefetchFail.Inc(1)
+		}
+	}()
+
+	seen := make(map[string]struct{})
+	// traversal gets every chunk from the store before its address is
+	// passed to the function, so it is already stored when counted
+	err = traversal.New(a.fileStore.ChunkStore, a.feed).TraverseAddresses(ctx, root, func(addr chunk.Address) error {
+		key := string(addr)
+		if _, ok := seen[key]; ok {
+			return nil
+		}
+		seen[key] = struct{}{}
+
+		if tag != nil {
+			tag.Inc(chunk.StateSplit)
+			tag.Inc(chunk.StateStored)
+		}
+		apiPrefetchChunks.Inc(1)
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+	if tag != nil {
+		tag.DoneSplit(root)
+	}
+	return count, nil
+}
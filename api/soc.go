@@ -0,0 +1,68 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/storage"
+	"github.com/ethersphere/swarm/storage/soc"
+)
+
+var (
+	apiSOCPutCount = metrics.NewRegisteredCounter("api/soc/put/count", nil)
+	apiSOCPutFail  = metrics.NewRegisteredCounter("api/soc/put/fail", nil)
+	apiSOCGetCount = metrics.NewRegisteredCounter("api/soc/get/count", nil)
+	apiSOCGetFail  = metrics.NewRegisteredCounter("api/soc/get/fail", nil)
+)
+
+// SOCPut stores the signed single-owner chunk data and returns the chunk
+// address derived from its identifier and the owner recovered from the
+// signature.
+func (a *API) SOCPut(ctx context.Context, data []byte) (storage.Address, error) {
+	apiSOCPutCount.Inc(1)
+	ch, _, err := soc.FromData(data)
+	if err != nil {
+		apiSOCPutFail.Inc(1)
+		return nil, err
+	}
+	if _, err := a.fileStore.ChunkStore.Put(ctx, chunk.ModePutUpload, ch); err != nil {
+		apiSOCPutFail.Inc(1)
+		return nil, err
+	}
+	return ch.Address(), nil
+}
+
+// SOCGet retrieves the single-owner chunk with the identifier of the owner.
+// The returned chunk is validated against its address.
+func (a *API) SOCGet(ctx context.Context, owner common.Address, id []byte) (*soc.SOC, error) {
+	apiSOCGetCount.Inc(1)
+	ch, err := a.fileStore.ChunkStore.Get(ctx, chunk.ModeGetRequest, soc.Address(id, owner))
+	if err != nil {
+		apiSOCGetFail.Inc(1)
+		return nil, err
+	}
+	s, err := soc.FromChunk(ch)
+	if err != nil {
+		apiSOCGetFail.Inc(1)
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"github.com/ethersphere/swarm/storage/localstore"
+	"github.com/ethersphere/swarm/version"
+)
+
+// NodeStatus aggregates the state of node components needed
+// by orchestration systems for readiness checks and alerting.
+// Sections of components that are not available are omitted.
+type NodeStatus struct {
+	Version    string            `json:"version"`
+	GitCommit  string            `json:"gitCommit,omitempty"`
+	Kademlia   *KademliaStatus   `json:"kademlia,omitempty"`
+	Sync       *SyncStatus       `json:"sync,omitempty"`
+	Storage    *StorageStatus    `json:"storage,omitempty"`
+	Accounting *AccountingStatus `json:"accounting,omitempty"`
+}
+
+// KademliaStatus holds the kademlia table depth, saturation
+// and the number of peers in every bin.
+type KademliaStatus struct {
+	Depth            int `json:"depth"`
+	Saturation       int `json:"saturation"`
+	TotalConnections int `json:"totalConnections"`
+	TotalKnown       int `json:"totalKnown"`
+	// Connections holds the number of connected peers
+	// for every proximity order.
+	Connections []int `json:"connections"`
+	// Known holds the number of known peers
+	// for every proximity order.
+	Known []int `json:"known"`
+}
+
+// SyncStatus holds the number of chunks waiting to be push synced
+// and available for pull syncing.
+type SyncStatus struct {
+	PushIndexSize int  `json:"pushIndexSize"`
+	PullIndexSize int  `json:"pullIndexSize"`
+	PullSyncing   bool `json:"pullSyncing"`
+}
+
+// StorageStatus holds the local store utilization.
+type StorageStatus struct {
+	Schema   string `json:"schema"`
+	Capacity uint64 `json:"capacity"`
+	GCSize   uint64 `json:"gcSize"`
+	// Utilization is the ratio of gcSize and capacity.
+	Utilization float64               `json:"utilization"`
+	Ready       bool                  `json:"ready"`
+	Disk        localstore.DiskStatus `json:"disk"`
+}
+
+// AccountingStatus summarizes SWAP balances with all known peers.
+type AccountingStatus struct {
+	Peers int `json:"peers"`
+	// Balance is the sum of balances with all peers.
+	Balance int64 `json:"balance"`
+}
+
+// Status returns the aggregated status of the node components.
+func (i *Inspector) Status() (*NodeStatus, error) {
+	s := &NodeStatus{
+		Version:   version.VersionWithMeta,
+		GitCommit: version.GitCommit,
+	}
+	if i.hive != nil {
+		info := i.hive.KademliaInfo()
+		k := &KademliaStatus{
+			Depth:            info.Depth,
+			Saturation:       i.hive.Saturation(),
+			TotalConnections: info.TotalConnections,
+			TotalKnown:       info.TotalKnown,
+			Connections:      make([]int, len(info.Connections)),
+			Known:            make([]int, len(info.Known)),
+		}
+		for po, row := range info.Connections {
+			k.Connections[po] = len(row)
+		}
+		for po, row := range info.Known {
+			k.Known[po] = len(row)
+		}
+		s.Kademlia = k
+	}
+	if i.ls != nil {
+		ls, err := i.ls.Status()
+		if err != nil {
+			return nil, err
+		}
+		s.Sync = &SyncStatus{
+			PushIndexSize: ls.PushIndexSize,
+			PullIndexSize: ls.PullIndexSize,
+		}
+		if i.stream != nil {
+			s.Sync.PullSyncing = i.IsPullSyncing()
+		}
+		s.Storage = &StorageStatus{
+			Schema:   ls.Schema,
+			Capacity: ls.Capacity,
+			GCSize:   ls.GCSize,
+			Ready:    ls.Ready,
+			Disk:     ls.Disk,
+		}
+		if ls.Capacity > 0 {
+			s.Storage.Utilization = float64(ls.GCSize) / float64(ls.Capacity)
+		}
+	}
+	if i.swap != nil {
+		balances, err := i.swap.Balances()
+		if err != nil {
+			return nil, err
+		}
+		a := &AccountingStatus{
+			Peers: len(balances),
+		}
+		for _, b := range balances {
+			a.Balance += b
+		}
+		s.Accounting = a
+	}
+	return s, nil
+}
@@ -86,7 +86,7 @@ func Parse(rawuri string) (*URI, error) {
 
 	// check the scheme is valid
 	switch uri.Scheme {
-	case "bzz", "bzz-raw", "bzz-immutable", "bzz-list", "bzz-hash", "bzz-feed", "bzz-feed-raw", "bzz-tag", "bzz-pin":
+	case "bzz", "bzz-raw", "bzz-immutable", "bzz-list", "bzz-hash", "bzz-feed", "bzz-feed-raw", "bzz-tag", "bzz-pin", "bzz-export", "bzz-import", "bzz-keys", "bzz-audit", "bzz-prefetch", "bzz-soc":
 	default:
 		return nil, fmt.Errorf("unknown scheme %q", u.Scheme)
 	}
@@ -139,6 +139,36 @@ func (u *URI) Pin() bool {
 	return u.Scheme == "bzz-pin"
 }
 
+// Keys returns true if the uri scheme is for api keys management
+func (u *URI) Keys() bool {
+	return u.Scheme == "bzz-keys"
+}
+
+// Export returns true if the uri scheme is bzz-export
+func (u *URI) Export() bool {
+	return u.Scheme == "bzz-export"
+}
+
+// Import returns true if the uri scheme is bzz-import
+func (u *URI) Import() bool {
+	return u.Scheme == "bzz-import"
+}
+
+// Audit returns true if the uri scheme is bzz-audit
+func (u *URI) Audit() bool {
+	return u.Scheme == "bzz-audit"
+}
+
+// Prefetch returns true if the uri scheme is bzz-prefetch
+func (u *URI) Prefetch() bool {
+	return u.Scheme == "bzz-prefetch"
+}
+
+// SOC returns true if the uri scheme is bzz-soc
+func (u *URI) SOC() bool {
+	return u.Scheme == "bzz-soc"
+}
+
 func (u *URI) String() string {
 	return u.Scheme + ":/" + u.Addr + "/" + u.Path
 }
@@ -82,7 +82,7 @@ type BaseHasherFunc func() hash.Hash
 // - the same hasher instance is synchronously reuseable
 // - Sum gives back the tree to the pool and guaranteed to leave
 //   the tree and itself in a state reusable for hashing a new chunk
-// - generates and verifies segment inclusion proofs (TODO:)
+// - segment inclusion proofs are constructed with NewProof and verified with Proof.Verify
 type Hasher struct {
 	mtx     sync.Mutex // protects Hasher.size increments (temporary solution)
 	pool    *TreePool  // BMT resource pool
@@ -115,6 +115,7 @@ type TreePool struct {
 	Size         int            // the total length of the data (count * size)
 	count        int            // current count of (ever) allocated resources
 	zerohashes   [][]byte       // lookup table for predictable padding subtrees for all levels
+	trees        *sync.Pool     // unbounded pool of trees used instead of c by shared pools
 }
 
 // NewTreePool creates a tree pool with hasher, segment size, segment count and capacity
@@ -143,8 +144,26 @@ func NewTreePool(hasher BaseHasherFunc, segmentCount, capacity int) *TreePool {
 	}
 }
 
-// Drain drains the pool until it has no more than n resources
+// NewSharedTreePool creates a tree pool that can be shared by any number of
+// hashers without limiting their concurrency. Released trees are kept for reuse
+// in a sync.Pool, so that they are freed by the garbage collector when not
+// used, as well as trees that hashers never release.
+func NewSharedTreePool(hasher BaseHasherFunc, segmentCount int) *TreePool {
+	p := NewTreePool(hasher, segmentCount, 0)
+	p.trees = &sync.Pool{
+		New: func() interface{} {
+			return newTree(p.SegmentSize, p.Depth, p.hasher)
+		},
+	}
+	return p
+}
+
+// Drain drains the pool until it has no more than n resources,
+// shared pools are drained by the garbage collector
 func (p *TreePool) Drain(n int) {
+	if p.trees != nil {
+		return
+	}
 	p.lock.Lock()
 	defer p.lock.Unlock()
 	for len(p.c) > n {
@@ -157,6 +176,9 @@ func (p *TreePool) Drain(n int) {
 // it reuses free trees or creates a new one if size is not reached
 // TODO: should use a context here
 func (p *TreePool) reserve() *tree {
+	if p.trees != nil {
+		return p.trees.Get().(*tree)
+	}
 	p.lock.Lock()
 	defer p.lock.Unlock()
 	var t *tree
@@ -175,6 +197,10 @@ func (p *TreePool) reserve() *tree {
 // release gives back a tree to the pool.
 // this tree is guaranteed to be in reusable state
 func (p *TreePool) release(t *tree) {
+	if p.trees != nil {
+		p.trees.Put(t)
+		return
+	}
 	p.c <- t // can never fail ...
 }
 
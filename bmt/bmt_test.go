@@ -229,6 +229,40 @@ LOOP:
 	}
 }
 
+// tests that the shared tree pool is safe for concurrent use
+// by more hashers than the default pool size
+func TestSharedTreePoolConcurrentUse(t *testing.T) {
+	hasher := sha3.NewLegacyKeccak256
+	pool := NewSharedTreePool(hasher, bmttestutil.SegmentCount)
+	cycles := 100
+	errc := make(chan error)
+
+	for i := 0; i < cycles; i++ {
+		go func() {
+			bmt := New(pool)
+			for j := 0; j < 4; j++ {
+				data := testutil.RandomBytes(1, bmttestutil.BufferSize)
+				n := rand.Intn(bmt.Size())
+				if err := testHasherCorrectness(bmt, hasher, data, n, 128); err != nil {
+					errc <- err
+					return
+				}
+			}
+			errc <- nil
+		}()
+	}
+	for ; cycles > 0; cycles-- {
+		select {
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out")
+		case err := <-errc:
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
 // Tests BMT Hasher io.Writer interface is working correctly
 // even multiple short random write buffers
 func TestBMTWriterBuffers(t *testing.T) {
@@ -351,6 +385,30 @@ func BenchmarkPool(t *testing.B) {
 	}
 }
 
+// BenchmarkSharedPool compares hashing with a new pool for every
+// hasher, as hashers were constructed before shared pools, and with
+// a single shared pool.
+func BenchmarkSharedPool(t *testing.B) {
+	size := 4096
+	data := testutil.RandomBytes(1, size)
+	hasher := sha3.NewLegacyKeccak256
+	t.Run("new", func(t *testing.B) {
+		t.ReportAllocs()
+		for i := 0; i < t.N; i++ {
+			pool := NewTreePool(hasher, bmttestutil.SegmentCount, PoolSize)
+			syncHash(New(pool), 0, data)
+		}
+	})
+	t.Run("shared", func(t *testing.B) {
+		pool := NewSharedTreePool(hasher, bmttestutil.SegmentCount)
+		t.ReportAllocs()
+		t.ResetTimer()
+		for i := 0; i < t.N; i++ {
+			syncHash(New(pool), 0, data)
+		}
+	})
+}
+
 // benchmarks simple sha3 hash on chunks
 func benchmarkSHA3(t *testing.B, n int) {
 	data := testutil.RandomBytes(1, n)
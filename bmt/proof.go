@@ -0,0 +1,121 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package bmt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// ErrSegmentIndexOutOfRange is returned when the inclusion proof
+// is requested for a segment that is not in the tree.
+var ErrSegmentIndexOutOfRange = errors.New("segment index out of range")
+
+// Proof is an inclusion proof of a single data segment in a chunk
+// hashed with BMT. It holds the sister segment and the sister nodes
+// on the path from the segment to the BMT root, together with the span
+// of the chunk, which are sufficient to calculate the chunk hash.
+type Proof struct {
+	Span    []byte   `json:"span"`    // little endian encoded length of data under the chunk
+	Index   int      `json:"index"`   // index of the proven segment
+	Segment []byte   `json:"segment"` // proven data segment
+	Sisters [][]byte `json:"sisters"` // sister segment and sister node hashes from the bottom to the top of the tree
+}
+
+// NewProof returns the inclusion proof of the segment at index in the chunk
+// data with span, for the BMT with count segments and hasher as the base hash.
+// Data shorter than the BMT size is padded with zeros, as in hashing.
+func NewProof(hasher BaseHasherFunc, count int, span, data []byte, index int) (*Proof, error) {
+	h := hasher()
+	segmentSize := h.Size()
+	c := 2
+	for ; c < count; c *= 2 {
+	}
+	if index < 0 || index >= c {
+		return nil, ErrSegmentIndexOutOfRange
+	}
+	d := make([]byte, c*segmentSize)
+	copy(d, data)
+
+	level := make([][]byte, c)
+	for i := range level {
+		level[i] = d[i*segmentSize : (i+1)*segmentSize]
+	}
+
+	p := &Proof{
+		Span:    span,
+		Index:   index,
+		Segment: level[index],
+	}
+	// the proof is constructed bottom up, pairs of nodes
+	// are hashed to get the nodes on the level above
+	for i := index; len(level) > 1; i /= 2 {
+		p.Sisters = append(p.Sisters, level[i^1])
+		parents := make([][]byte, len(level)/2)
+		for j := range parents {
+			parents[j] = doSum(h, nil, level[2*j], level[2*j+1])
+		}
+		level = parents
+	}
+	return p, nil
+}
+
+// Root returns the BMT hash of the chunk calculated from the proof
+// with hasher as the base hash.
+func (p *Proof) Root(hasher BaseHasherFunc) []byte {
+	h := hasher()
+	s := p.Segment
+	for i, sister := range p.Sisters {
+		if (p.Index>>uint(i))&1 == 0 {
+			s = doSum(h, nil, s, sister)
+		} else {
+			s = doSum(h, nil, sister, s)
+		}
+	}
+	return doSum(h, nil, p.Span, s)
+}
+
+// Verify reports whether the proof proves inclusion of the segment in the chunk
+// with the address, for BMT with count segments and hasher as the base hash.
+func (p *Proof) Verify(hasher BaseHasherFunc, count int, addr []byte) bool {
+	segmentSize := hasher().Size()
+	depth := 1
+	for c := 2; c < count; c *= 2 {
+		depth++
+	}
+	if len(p.Span) != 8 || len(p.Segment) != segmentSize || len(p.Sisters) != depth {
+		return false
+	}
+	if p.Index < 0 || p.Index >= 1<<uint(depth) {
+		return false
+	}
+	for _, s := range p.Sisters {
+		if len(s) != segmentSize {
+			return false
+		}
+	}
+	return bytes.Equal(p.Root(hasher), addr)
+}
+
+// Length returns the length of data under the chunk decoded from the span.
+func (p *Proof) Length() uint64 {
+	if len(p.Span) != 8 {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(p.Span)
+}
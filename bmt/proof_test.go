@@ -0,0 +1,73 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package bmt
+
+import (
+	"bytes"
+	"testing"
+
+	bmttestutil "github.com/ethersphere/swarm/bmt/testutil"
+	"github.com/ethersphere/swarm/testutil"
+	"golang.org/x/crypto/sha3"
+)
+
+// TestProof validates that inclusion proofs of all segments
+// result in the chunk hash calculated by the Hasher and that
+// proofs with modified segments are not valid.
+func TestProof(t *testing.T) {
+	hasher := sha3.NewLegacyKeccak256
+	count := bmttestutil.SegmentCount
+	pool := NewTreePool(hasher, count, PoolSize)
+	segmentSize := hasher().Size()
+
+	for _, length := range []int{1, 31, 32, 33, 64, 1000, 4095, 4096} {
+		data := testutil.RandomBytes(length, length)
+		addr := syncHash(New(pool), length, data)
+		span := LengthToSpan(length)
+
+		for i := 0; i < count; i++ {
+			p, err := NewProof(hasher, count, span, data, i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Length() != uint64(length) {
+				t.Fatalf("length %v: got proof length %v", length, p.Length())
+			}
+			if !p.Verify(hasher, count, addr) {
+				t.Fatalf("length %v: proof of segment %v not valid", length, i)
+			}
+
+			want := make([]byte, segmentSize)
+			if i*segmentSize < length {
+				copy(want, data[i*segmentSize:])
+			}
+			if !bytes.Equal(p.Segment, want) {
+				t.Fatalf("length %v: got segment %v %x, want %x", length, i, p.Segment, want)
+			}
+
+			p.Segment = append([]byte{}, p.Segment...)
+			p.Segment[0]++
+			if p.Verify(hasher, count, addr) {
+				t.Fatalf("length %v: proof of modified segment %v valid", length, i)
+			}
+		}
+	}
+
+	if _, err := NewProof(hasher, count, LengthToSpan(1), []byte{1}, count); err != ErrSegmentIndexOutOfRange {
+		t.Errorf("got error %v, want %v", err, ErrSegmentIndexOutOfRange)
+	}
+}
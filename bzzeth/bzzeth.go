@@ -32,13 +32,15 @@ import (
 	"github.com/ethersphere/swarm/chunk"
 	"github.com/ethersphere/swarm/log"
 	"github.com/ethersphere/swarm/network"
-	"github.com/ethersphere/swarm/network/timeouts"
 	"github.com/ethersphere/swarm/p2p/protocols"
 	"github.com/ethersphere/swarm/spancontext"
 	"github.com/ethersphere/swarm/storage"
 	"golang.org/x/sync/errgroup"
 )
 
+// maxHeadersRange is the maximal number of block numbers in GetBlockHeadersRange request
+var maxHeadersRange uint64 = 1000
+
 var (
 	errUnsolicitedHeader    = errors.New("unsolicited header received")
 	errDuplicateHeader      = errors.New("duplicate header received")
@@ -52,15 +54,19 @@ var _ node.Service = &BzzEth{}
 type BzzEth struct {
 	peers    *peers            // bzzeth peer pool
 	netStore *storage.NetStore // netstore to retrieve and store
+	headers  *HeaderStore      // index of locally stored headers, optional
 	kad      *network.Kademlia // kademlia to determine if a header chunk belongs to us
 	quit     chan struct{}     // quit channel to close go routines
 }
 
 // New constructs the BzzEth node service
-func New(netStore *storage.NetStore, kad *network.Kademlia) *BzzEth {
+// If headers store is not nil, stored headers are indexed by block number and
+// served from it.
+func New(netStore *storage.NetStore, kad *network.Kademlia, headers *HeaderStore) *BzzEth {
 	return &BzzEth{
 		peers:    newPeers(),
 		netStore: netStore,
+		headers:  headers,
 		kad:      kad,
 		quit:     make(chan struct{}),
 	}
@@ -108,6 +114,8 @@ func (b *BzzEth) handleMsg(p *Peer) func(context.Context, interface{}) error {
 			return b.handleBlockHeaders(ctx, p, msg)
 		case *GetBlockHeaders:
 			return b.handleGetBlockHeaders(ctx, p, msg)
+		case *GetBlockHeadersRange:
+			return b.handleGetBlockHeadersRange(ctx, p, msg)
 		}
 		return nil
 	}
@@ -299,6 +307,12 @@ func (b *BzzEth) storeChunks(ctx context.Context, chunks []chunk.Chunk) error {
 		return err
 	}
 	log.Debug("Stored all headers ", "count", len(chunks))
+
+	if b.headers != nil {
+		if err := b.headers.Put(chunks...); err != nil {
+			return fmt.Errorf("indexing headers: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -425,6 +439,34 @@ DELIVERY:
 	return nil
 }
 
+// handleGetBlockHeadersRange serves the locally stored headers with block numbers
+// in the requested range directly from the headers store. Unlike GetBlockHeaders,
+// headers are not retrieved from the network.
+func (b *BzzEth) handleGetBlockHeadersRange(ctx context.Context, p *Peer, msg *GetBlockHeadersRange) error {
+	p.logger.Debug("bzzeth.handleGetBlockHeadersRange", "id", msg.Rid, "from", msg.From, "to", msg.To)
+
+	if msg.From > msg.To || msg.To-msg.From >= maxHeadersRange {
+		return protocols.Break(fmt.Errorf("invalid block headers range %d-%d", msg.From, msg.To))
+	}
+
+	var headers []rlp.RawValue
+	if b.headers != nil {
+		err := b.headers.Range(msg.From, msg.To, func(_ uint64, _ chunk.Address, data []byte) (stop bool, err error) {
+			headers = append(headers, data)
+			return false, nil
+		})
+		if err != nil {
+			return fmt.Errorf("reading headers range: %w", err)
+		}
+	}
+
+	p.logger.Debug("sending headers", "count", len(headers))
+	return p.Send(ctx, &BlockHeaders{
+		Rid:     uint32(msg.Rid),
+		Headers: headers,
+	})
+}
+
 var batchWait = 100 * time.Millisecond // time to wait for collecting headers in a batch
 var minBatchSize = 1                   // minimum headers in a batch
 
@@ -466,7 +508,18 @@ BATCH:
 }
 
 // getBlockHeaderBzz retrieves a block header by its hash from swarm
+// Headers in the headers store are served from it, without accessing the localstore.
 func (b *BzzEth) getBlockHeaderBzz(ctx context.Context, hash chunk.Address) ([]byte, error) {
+	if b.headers != nil {
+		data, err := b.headers.Get(hash)
+		if err == nil {
+			return data, nil
+		}
+		if err != ErrHeaderNotFound {
+			log.Error("bzzeth.getBlockHeaderBzz: headers store", "hash", hash.Hex(), "err", err)
+		}
+	}
+
 	req := &storage.Request{
 		Addr:   hash,
 		Origin: b.netStore.LocalID,
@@ -481,7 +534,7 @@ func (b *BzzEth) getBlockHeaderBzz(ctx context.Context, hash chunk.Address) ([]b
 // requestAll requests each hash from Swarm (local or Network) and send t0
 // the delivery channel for processing the header
 func (b *BzzEth) requestAll(ctx context.Context, deliveries chan []byte, hashes []chunk.Address) {
-	ctx, cancel := context.WithTimeout(ctx, timeouts.FetcherGlobalTimeout)
+	ctx, cancel := context.WithTimeout(ctx, b.netStore.FetcherTimeout)
 	defer cancel()
 
 	// missingHeaders collects hashes of headers not found within swarm
@@ -57,7 +57,15 @@ func newBzzEthTester(t *testing.T, prvkey *ecdsa.PrivateKey, netStore *storage.N
 		prvkey = key
 	}
 
-	b := New(netStore, nil)
+	// headers are indexed in localstore of the netstore
+	var headers *HeaderStore
+	if netStore != nil {
+		if db, ok := netStore.Store.(*localstore.DB); ok {
+			headers = NewHeaderStore(db)
+		}
+	}
+
+	b := New(netStore, nil, headers)
 	protocolTester := p2ptest.NewProtocolTester(prvkey, 1, b.Run)
 	teardown := func() {
 		protocolTester.Stop()
@@ -335,6 +343,34 @@ func getBlockHeaderExchange(tester *p2ptest.ProtocolTester, peerID enode.ID, req
 		})
 }
 
+func getBlockHeadersRangeExchange(tester *p2ptest.ProtocolTester, peerID enode.ID, requestID uint32, from, to uint64, offeredHeaders []rlp.RawValue) error {
+	return tester.TestExchanges(
+		p2ptest.Exchange{
+			Label: "GetBlockHeadersRange",
+			Triggers: []p2ptest.Trigger{
+				{
+					Code: 4,
+					Msg: GetBlockHeadersRange{
+						Rid:  uint64(requestID),
+						From: from,
+						To:   to,
+					},
+					Peer: peerID,
+				},
+			},
+			Expects: []p2ptest.Expect{
+				{
+					Code: 3,
+					Msg: BlockHeaders{
+						Rid:     requestID,
+						Headers: offeredHeaders,
+					},
+					Peer: peerID,
+				},
+			},
+		})
+}
+
 // TestNewBlockHeaders full eth node sends new block header hashes
 // respond with a GetBlockHeaders requesting headers falling into the proximity of this node
 // Also test two other conditions
@@ -568,6 +604,49 @@ func checkStorage(t *testing.T, wantedIndexes []int, wanted []chunk.Address, wan
 	}
 }
 
+// TestGetBlockHeadersRange tests that headers stored by the Swarm node
+// are served by block number ranges from the headers store
+func TestGetBlockHeadersRange(t *testing.T) {
+	prvKey, netstore, cleanup := newTestNetworkStore(t)
+	defer cleanup()
+
+	tester, b, teardown, err := newBzzEthTester(t, prvKey, netstore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	headers := make([]rlp.RawValue, 20)
+	for i := range headers {
+		hdr := types.Header{Number: new(big.Int).SetUint64(uint64(i))}
+		res, err := rlp.EncodeToBytes(hdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		headers[i] = res
+		if err := b.storeChunks(context.Background(), []chunk.Chunk{newChunk(res)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	node := tester.Nodes[0]
+	err = handshakeExchange(tester, node.ID(), true, true)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	err = getBlockHeadersRangeExchange(tester, node.ID(), newRequestIDFunc(), 5, 9, headers[5:10])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the range past stored headers results in partial response
+	err = getBlockHeadersRangeExchange(tester, node.ID(), newRequestIDFunc(), 15, 100, headers[15:])
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 func checkDelivery(t *testing.T, wantedIndexes []int, wanted []chunk.Address, hashes map[string]bool) {
 	for i := range wantedIndexes {
 		hash := hex.EncodeToString(wanted[i])
@@ -0,0 +1,100 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package bzzeth
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/storage/localstore"
+)
+
+// ErrHeaderNotFound is returned by HeaderStore when a header is not stored.
+var ErrHeaderNotFound = errors.New("header not found")
+
+// HeaderStore keeps block headers stored by the node in dedicated localstore
+// indexes, so that headers can be looked up by block number ranges and served
+// without affecting the garbage collection ordering of regular content.
+// Headers are removed from the indexes when their chunks are garbage collected.
+type HeaderStore struct {
+	db *localstore.DB
+}
+
+// NewHeaderStore creates a HeaderStore that indexes headers in localstore.
+func NewHeaderStore(db *localstore.DB) *HeaderStore {
+	return &HeaderStore{
+		db: db,
+	}
+}
+
+// Put indexes header chunks that are already stored in localstore.
+// The block number of each header is decoded from its RLP encoded data.
+func (s *HeaderStore) Put(chunks ...chunk.Chunk) error {
+	headers := make([]localstore.BlockHeader, 0, len(chunks))
+	for _, ch := range chunks {
+		number, err := headerNumber(ch.Data())
+		if err != nil {
+			return err
+		}
+		headers = append(headers, localstore.BlockHeader{
+			Address: ch.Address(),
+			Number:  number,
+		})
+	}
+	return s.db.PutBlockHeaders(headers...)
+}
+
+// Get returns the header data for the header chunk address.
+func (s *HeaderStore) Get(addr chunk.Address) ([]byte, error) {
+	data, err := s.db.GetBlockHeader(addr)
+	if err != nil {
+		if err == chunk.ErrChunkNotFound {
+			return nil, ErrHeaderNotFound
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// Has returns true if the header with the chunk address is stored.
+func (s *HeaderStore) Has(addr chunk.Address) (bool, error) {
+	return s.db.HasBlockHeader(addr)
+}
+
+// Range calls fn for every stored header with block number in the
+// inclusive range from-to, ordered by block number. If there are
+// multiple headers for the same block number, all of them are passed to fn.
+// Iteration stops if fn returns true or an error.
+func (s *HeaderStore) Range(from, to uint64, fn func(number uint64, addr chunk.Address, data []byte) (stop bool, err error)) error {
+	return s.db.IterateBlockHeaders(from, to, func(h localstore.BlockHeader, data []byte) (stop bool, err error) {
+		return fn(h.Number, h.Address, data)
+	})
+}
+
+// headerNumber returns the block number from the RLP encoded block header.
+func headerNumber(data []byte) (uint64, error) {
+	var header types.Header
+	if err := rlp.DecodeBytes(data, &header); err != nil {
+		return 0, err
+	}
+	if header.Number == nil || !header.Number.IsUint64() {
+		return 0, errors.New("invalid block number")
+	}
+	return header.Number.Uint64(), nil
+}
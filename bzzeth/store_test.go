@@ -0,0 +1,107 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package bzzeth
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/storage/localstore"
+)
+
+// TestHeaderStore validates indexing stored headers and retrieving them
+// by hash and by block number ranges.
+func TestHeaderStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bzzeth-headers-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := localstore.New(dir, make([]byte, 32), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	s := NewHeaderStore(db)
+
+	// store headers in reverse order to validate range ordering
+	var chunks []chunk.Chunk
+	for i := 9; i >= 0; i-- {
+		hdr := types.Header{Number: new(big.Int).SetUint64(uint64(i * 1000))}
+		data, err := rlp.EncodeToBytes(hdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks = append(chunks, newChunk(data))
+	}
+	if _, err := db.Put(context.Background(), chunk.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(chunks...); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ch := range chunks {
+		data, err := s.Get(ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, ch.Data()) {
+			t.Errorf("got header data %x, want %x", data, ch.Data())
+		}
+	}
+
+	_, err = s.Get(make([]byte, 32))
+	if err != ErrHeaderNotFound {
+		t.Errorf("got error %v, want %v", err, ErrHeaderNotFound)
+	}
+
+	var numbers []uint64
+	err = s.Range(2500, 6000, func(number uint64, addr chunk.Address, data []byte) (stop bool, err error) {
+		numbers = append(numbers, number)
+		return false, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []uint64{3000, 4000, 5000, 6000}
+	if len(numbers) != len(want) {
+		t.Fatalf("got block numbers %v, want %v", numbers, want)
+	}
+	for i := range want {
+		if numbers[i] != want[i] {
+			t.Fatalf("got block numbers %v, want %v", numbers, want)
+		}
+	}
+
+	invalid := newChunk([]byte("not a header"))
+	if _, err := db.Put(context.Background(), chunk.ModePutUpload, invalid); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(invalid); err == nil {
+		t.Error("expected error storing invalid header")
+	}
+}
@@ -26,13 +26,14 @@ import (
 // Spec is the protocol spec for bzzeth
 var Spec = &protocols.Spec{
 	Name:       "bzzeth",
-	Version:    1,
+	Version:    2,
 	MaxMsgSize: 10 * 1024 * 1024,
 	Messages: []interface{}{
 		Handshake{},
 		NewBlockHeaders{},
 		GetBlockHeaders{},
 		BlockHeaders{},
+		GetBlockHeadersRange{},
 	},
 	DisableContext: true,
 }
@@ -62,3 +63,12 @@ type BlockHeaders struct {
 	Rid     uint32         // request id
 	Headers []rlp.RawValue // list of rlp encoded block headers
 }
+
+// GetBlockHeadersRange is sent by the Ethereum node to request the headers stored on the Swarm node
+// with block numbers in the inclusive range From-To.
+// Headers are sent in a single BlockHeaders response with the same request id, ordered by block number.
+type GetBlockHeadersRange struct {
+	Rid  uint64 // request id
+	From uint64 // first block number
+	To   uint64 // last block number
+}
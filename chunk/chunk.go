@@ -28,11 +28,20 @@ const (
 	DefaultSize   = 4096
 	MaxPO         = 16
 	AddressLength = 32
+	// StampSize is the length of a postage stamp, which
+	// references the prepaid postage batch of a chunk.
+	StampSize = 32
 )
 
 var (
 	ErrChunkNotFound = errors.New("chunk not found")
 	ErrChunkInvalid  = errors.New("invalid chunk")
+	// ErrDiskFull is returned by stores that do not accept
+	// chunks because there is not enough free disk space.
+	ErrDiskFull = errors.New("disk full")
+	// ErrInvalidStamp is returned for postage stamps that
+	// are malformed or rejected by a stamp validator.
+	ErrInvalidStamp = errors.New("invalid postage stamp")
 )
 
 type Chunk interface {
@@ -42,6 +51,8 @@ type Chunk interface {
 	WithPinCounter(p uint64) Chunk
 	TagID() uint32
 	WithTagID(t uint32) Chunk
+	Stamp() []byte
+	WithStamp(s []byte) Chunk
 }
 
 type chunk struct {
@@ -49,6 +60,7 @@ type chunk struct {
 	sdata      []byte
 	pinCounter uint64
 	tagID      uint32
+	stamp      []byte
 }
 
 func NewChunk(addr Address, data []byte) Chunk {
@@ -68,6 +80,12 @@ func (c *chunk) WithTagID(t uint32) Chunk {
 	return c
 }
 
+// WithStamp attaches a postage stamp to the chunk.
+func (c *chunk) WithStamp(s []byte) Chunk {
+	c.stamp = s
+	return c
+}
+
 func (c *chunk) Address() Address {
 	return c.addr
 }
@@ -84,6 +102,12 @@ func (c *chunk) TagID() uint32 {
 	return c.tagID
 }
 
+// Stamp returns the postage stamp of the chunk,
+// or nil if the chunk is not stamped.
+func (c *chunk) Stamp() []byte {
+	return c.stamp
+}
+
 func (self *chunk) String() string {
 	return fmt.Sprintf("Address: %v Chunksize: %v", self.addr.Log(), len(self.sdata))
 }
@@ -132,21 +156,14 @@ func (a *Address) UnmarshalJSON(value []byte) error {
 // binary representation of the x^y.
 //
 // (0 farthest, 255 closest, 256 self)
+//
+// Addresses of at least 8 bytes are compared as 64 bit words,
+// see proximity.go.
 func Proximity(one, other []byte) (ret int) {
-	b := (MaxPO-1)/8 + 1
-	if b > len(one) {
-		b = len(one)
-	}
-	m := 8
-	for i := 0; i < b; i++ {
-		oxo := one[i] ^ other[i]
-		for j := 0; j < m; j++ {
-			if (oxo>>uint8(7-j))&0x01 != 0 {
-				return i*8 + j
-			}
-		}
+	if len(one) >= 8 && len(other) >= 8 {
+		return proximityWord(one, other)
 	}
-	return MaxPO
+	return proximityBytes(one, other)
 }
 
 // ModeGet enumerates different Getter modes.
@@ -260,6 +277,8 @@ func (d *Descriptor) String() string {
 type Store interface {
 	Get(ctx context.Context, mode ModeGet, addr Address) (ch Chunk, err error)
 	GetMulti(ctx context.Context, mode ModeGet, addrs ...Address) (ch []Chunk, err error)
+	// Put stores chunks and returns exist as true for every chunk that
+	// was already stored, in which case its data is not written again.
 	Put(ctx context.Context, mode ModePut, chs ...Chunk) (exist []bool, err error)
 	Has(ctx context.Context, addr Address) (yes bool, err error)
 	HasMulti(ctx context.Context, addrs ...Address) (yes []bool, err error)
@@ -0,0 +1,52 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package chunk
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// proximityBytes calculates proximity order by comparing one byte
+// at a time and counting leading zeros of the first different byte.
+func proximityBytes(one, other []byte) (ret int) {
+	b := (MaxPO-1)/8 + 1
+	if b > len(one) {
+		b = len(one)
+	}
+	for i := 0; i < b; i++ {
+		if oxo := one[i] ^ other[i]; oxo != 0 {
+			return i*8 + bits.LeadingZeros8(oxo)
+		}
+	}
+	return MaxPO
+}
+
+// proximityWord calculates proximity order by counting leading zeros
+// of the xor of the first 64 bits of both addresses. Both addresses
+// must be at least 8 bytes long.
+func proximityWord(one, other []byte) (ret int) {
+	const b = (MaxPO-1)/8 + 1
+	if b > 8 {
+		return proximityBytes(one, other)
+	}
+	lz := bits.LeadingZeros64(binary.BigEndian.Uint64(one) ^ binary.BigEndian.Uint64(other))
+	if lz < b*8 {
+		return lz
+	}
+	return MaxPO
+}
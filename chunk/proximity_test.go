@@ -17,6 +17,7 @@
 package chunk
 
 import (
+	"crypto/rand"
 	"strconv"
 	"testing"
 )
@@ -184,3 +185,52 @@ func TestProximity(t *testing.T) {
 		}
 	}
 }
+
+// TestProximityImplementations validates that all Proximity
+// implementations return the same values for random addresses.
+func TestProximityImplementations(t *testing.T) {
+	for i := 0; i < 10000; i++ {
+		one, other := randomAddressPair(t, i%(MaxPO+1))
+		want := proximityBytes(one, other)
+		if got := proximityWord(one, other); got != want {
+			t.Fatalf("got proximity %v for %x and %x, want %v", got, one, other, want)
+		}
+	}
+}
+
+// randomAddressPair returns two random addresses with the
+// first po bits equal.
+func randomAddressPair(t testing.TB, po int) (one, other []byte) {
+	one = make([]byte, AddressLength)
+	other = make([]byte, AddressLength)
+	if _, err := rand.Read(one); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rand.Read(other); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < po; i++ {
+		mask := byte(1) << uint(7-i%8)
+		other[i/8] = other[i/8]&^mask | one[i/8]&mask
+	}
+	return one, other
+}
+
+func BenchmarkProximity(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		f    func(one, other []byte) int
+	}{
+		{name: "selected", f: Proximity},
+		{name: "bytes", f: proximityBytes},
+		{name: "word", f: proximityWord},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			one, other := randomAddressPair(b, MaxPO/2+1)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				bc.f(one, other)
+			}
+		})
+	}
+}
@@ -0,0 +1,691 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package test provides a conformance test suite for chunk.Store
+// implementations, so that all of them can be validated against the
+// same expectations.
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/swarm/chunk"
+	chunktesting "github.com/ethersphere/swarm/chunk/testing"
+	"golang.org/x/sync/errgroup"
+)
+
+// NewStoreFunc opens a Store that persists its data in the provided
+// directory. A Store opened in the directory of a previously closed one
+// must contain all of its chunks, and so must a Store opened from a copy
+// of the directory of one that was not closed, as after a crash. If capacity is not 0, the Store must
+// garbage collect unpinned chunks when the number of chunks that can be
+// garbage collected exceeds it. Zero capacity is for the default one,
+// which must be large enough to hold a few thousand chunks.
+type NewStoreFunc func(t *testing.T, dir string, capacity uint64) (chunk.Store, error)
+
+// gcCapacity is the store capacity used in garbage collection tests.
+const gcCapacity = 100
+
+// gcTimeout is the time a store has to garbage collect chunks
+// above its capacity.
+var gcTimeout = 10 * time.Second
+
+// RunAll runs all conformance tests on stores opened by newStore.
+func RunAll(t *testing.T, newStore NewStoreFunc) {
+	t.Run("put get", func(t *testing.T) {
+		testPutGet(t, newStore)
+	})
+	t.Run("put existing", func(t *testing.T) {
+		testPutExisting(t, newStore)
+	})
+	t.Run("remove", func(t *testing.T) {
+		testRemove(t, newStore)
+	})
+	t.Run("pull subscriptions", func(t *testing.T) {
+		testPullSubscriptions(t, newStore)
+	})
+	t.Run("concurrency", func(t *testing.T) {
+		testConcurrency(t, newStore)
+	})
+	t.Run("gc", func(t *testing.T) {
+		testGC(t, newStore)
+	})
+	t.Run("pin", func(t *testing.T) {
+		testPin(t, newStore)
+	})
+	t.Run("restart", func(t *testing.T) {
+		testRestart(t, newStore)
+	})
+	t.Run("crash restart", func(t *testing.T) {
+		testCrashRestart(t, newStore)
+	})
+}
+
+// testPutGet validates that chunks stored with every put mode
+// are returned by all getter methods.
+func testPutGet(t *testing.T, newStore NewStoreFunc) {
+	for _, mode := range []chunk.ModePut{
+		chunk.ModePutRequest,
+		chunk.ModePutSync,
+		chunk.ModePutUpload,
+	} {
+		t.Run(mode.String(), func(t *testing.T) {
+			s, cleanup := openStore(t, newStore, 0)
+			defer cleanup()
+
+			chunks := chunktesting.GenerateTestRandomChunks(10)
+			exist, err := s.Put(context.Background(), mode, chunks...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(exist) != len(chunks) {
+				t.Fatalf("got %v exist flags, want %v", len(exist), len(chunks))
+			}
+			for i, e := range exist {
+				if e {
+					t.Errorf("chunk %v reported as existing", i)
+				}
+			}
+
+			checkStored(t, s, chunks)
+
+			got, err := s.GetMulti(context.Background(), chunk.ModeGetRequest, addresses(chunks)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(chunks) {
+				t.Fatalf("got %v chunks from get multi, want %v", len(got), len(chunks))
+			}
+			for i, ch := range got {
+				checkChunk(t, ch, chunks[i])
+			}
+
+			yes, err := s.HasMulti(context.Background(), addresses(chunks)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, y := range yes {
+				if !y {
+					t.Errorf("chunk %v not found by has multi", i)
+				}
+			}
+
+			missing := chunktesting.GenerateTestRandomChunk()
+			checkNotStored(t, s, missing.Address())
+			_, err = s.GetMulti(context.Background(), chunk.ModeGetRequest, chunks[0].Address(), missing.Address())
+			if !errors.Is(err, chunk.ErrChunkNotFound) {
+				t.Errorf("got get multi error %v, want %v", err, chunk.ErrChunkNotFound)
+			}
+			yes, err = s.HasMulti(context.Background(), chunks[0].Address(), missing.Address())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(yes) != 2 || !yes[0] || yes[1] {
+				t.Errorf("got has multi %v, want [true false]", yes)
+			}
+		})
+	}
+}
+
+// testPutExisting validates that putting already stored chunks
+// reports them as existing and does not change their data.
+func testPutExisting(t *testing.T, newStore NewStoreFunc) {
+	s, cleanup := openStore(t, newStore, 0)
+	defer cleanup()
+
+	chunks := chunktesting.GenerateTestRandomChunks(5)
+	if _, err := s.Put(context.Background(), chunk.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+
+	newChunks := chunktesting.GenerateTestRandomChunks(5)
+	exist, err := s.Put(context.Background(), chunk.ModePutSync, append(chunks, newChunks...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, e := range exist {
+		if want := i < len(chunks); e != want {
+			t.Errorf("chunk %v: got exist %v, want %v", i, e, want)
+		}
+	}
+
+	checkStored(t, s, append(chunks, newChunks...))
+}
+
+// testRemove validates that removed chunks are no longer
+// available and that other chunks are not affected.
+func testRemove(t *testing.T, newStore NewStoreFunc) {
+	s, cleanup := openStore(t, newStore, 0)
+	defer cleanup()
+
+	chunks := chunktesting.GenerateTestRandomChunks(10)
+	if _, err := s.Put(context.Background(), chunk.ModePutRequest, chunks...); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Set(context.Background(), chunk.ModeSetRemove, chunks[0].Address(), chunks[1].Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	checkNotStored(t, s, chunks[0].Address(), chunks[1].Address())
+	checkStored(t, s, chunks[2:])
+}
+
+// testPullSubscriptions validates that every stored chunk is
+// provided by exactly one pull subscription bin and that bin IDs
+// are increasing.
+func testPullSubscriptions(t *testing.T, newStore NewStoreFunc) {
+	s, cleanup := openStore(t, newStore, 0)
+	defer cleanup()
+
+	chunks := chunktesting.GenerateTestRandomChunks(100)
+	if _, err := s.Put(context.Background(), chunk.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+	index := pullIndex(t, s)
+	checkPullIndex(t, index, chunks)
+
+	// new chunks must be added after the existing ones
+	newChunks := chunktesting.GenerateTestRandomChunks(100)
+	if _, err := s.Put(context.Background(), chunk.ModePutSync, newChunks...); err != nil {
+		t.Fatal(err)
+	}
+	newIndex := pullIndex(t, s)
+	checkPullIndex(t, newIndex, append(chunks, newChunks...))
+	checkPullIndexAppended(t, index, newIndex)
+}
+
+// testConcurrency validates that concurrent puts and gets do not
+// lose chunks or assign the same bin ID to more than one chunk.
+func testConcurrency(t *testing.T, newStore NewStoreFunc) {
+	s, cleanup := openStore(t, newStore, 0)
+	defer cleanup()
+
+	const workers = 8
+	chunks := make([][]chunk.Chunk, workers)
+	var g errgroup.Group
+	for i := 0; i < workers; i++ {
+		chunks[i] = chunktesting.GenerateTestRandomChunks(50)
+		chs := chunks[i]
+		g.Go(func() error {
+			for j := 0; j < len(chs); j += 5 {
+				batch := chs[j : j+5]
+				if _, err := s.Put(context.Background(), chunk.ModePutUpload, batch...); err != nil {
+					return err
+				}
+				for _, ch := range batch {
+					got, err := s.Get(context.Background(), chunk.ModeGetRequest, ch.Address())
+					if err != nil {
+						return fmt.Errorf("get chunk %s: %v", ch.Address(), err)
+					}
+					if !bytes.Equal(got.Data(), ch.Data()) {
+						return fmt.Errorf("got invalid data for chunk %s", ch.Address())
+					}
+				}
+				if _, err := s.HasMulti(context.Background(), addresses(batch)...); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	var all []chunk.Chunk
+	for _, chs := range chunks {
+		all = append(all, chs...)
+	}
+	checkStored(t, s, all)
+	checkPullIndex(t, pullIndex(t, s), all)
+}
+
+// testGC validates that the number of garbage collectable chunks
+// is reduced to the store capacity and that neither the most recently
+// stored chunk nor unsynced uploaded chunks are garbage collected.
+func testGC(t *testing.T, newStore NewStoreFunc) {
+	s, cleanup := openStore(t, newStore, gcCapacity)
+	defer cleanup()
+
+	uploaded := chunktesting.GenerateTestRandomChunks(50)
+	if _, err := s.Put(context.Background(), chunk.ModePutUpload, uploaded...); err != nil {
+		t.Fatal(err)
+	}
+
+	chunks := putInBatches(t, s, chunk.ModePutRequest, 3*gcCapacity)
+	waitGC(t, s, chunks)
+
+	checkStored(t, s, chunks[len(chunks)-1:])
+	checkStored(t, s, uploaded)
+}
+
+// testPin validates that pinned chunks are not garbage collected
+// until they are unpinned as many times as they were pinned.
+func testPin(t *testing.T, newStore NewStoreFunc) {
+	s, cleanup := openStore(t, newStore, gcCapacity)
+	defer cleanup()
+
+	pinned := chunktesting.GenerateTestRandomChunks(20)
+	if _, err := s.Put(context.Background(), chunk.ModePutRequest, pinned...); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set(context.Background(), chunk.ModeSetPin, addresses(pinned)...); err != nil {
+		t.Fatal(err)
+	}
+	// pin the first half of chunks twice
+	twice := pinned[:len(pinned)/2]
+	if err := s.Set(context.Background(), chunk.ModeSetPin, addresses(twice)...); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set(context.Background(), chunk.ModeSetUnpin, addresses(twice)...); err != nil {
+		t.Fatal(err)
+	}
+
+	chunks := putInBatches(t, s, chunk.ModePutRequest, 3*gcCapacity)
+	waitGC(t, s, chunks)
+
+	checkStored(t, s, pinned)
+}
+
+// testRestart validates that chunks, pins and the pull syncing index
+// are preserved when a store is closed and opened again.
+func testRestart(t *testing.T, newStore NewStoreFunc) {
+	dir, err := ioutil.TempDir("", "chunk-store-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := newStore(t, dir, gcCapacity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploaded := chunktesting.GenerateTestRandomChunks(50)
+	if _, err := s.Put(context.Background(), chunk.ModePutUpload, uploaded...); err != nil {
+		t.Fatal(err)
+	}
+	pinned := chunktesting.GenerateTestRandomChunks(10)
+	if _, err := s.Put(context.Background(), chunk.ModePutRequest, pinned...); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set(context.Background(), chunk.ModeSetPin, addresses(pinned)...); err != nil {
+		t.Fatal(err)
+	}
+	index := pullIndex(t, s)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = newStore(t, dir, gcCapacity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := s.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	stored := append(uploaded, pinned...)
+	checkStored(t, s, stored)
+	// chunks stored on retrieval requests are not synced
+	checkPullIndex(t, pullIndex(t, s), uploaded)
+
+	newUploaded := chunktesting.GenerateTestRandomChunks(10)
+	if _, err := s.Put(context.Background(), chunk.ModePutUpload, newUploaded...); err != nil {
+		t.Fatal(err)
+	}
+	checkPullIndexAppended(t, index, pullIndex(t, s))
+
+	chunks := putInBatches(t, s, chunk.ModePutRequest, 3*gcCapacity)
+	waitGC(t, s, chunks)
+	checkStored(t, s, append(stored, newUploaded...))
+}
+
+// testCrashRestart validates that chunks, pin counters, the pull syncing
+// index and garbage collection are preserved when a store is opened
+// from the files of a store that was not closed.
+func testCrashRestart(t *testing.T, newStore NewStoreFunc) {
+	dir, err := ioutil.TempDir("", "chunk-store-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := newStore(t, dir, gcCapacity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := s.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+	uploaded := chunktesting.GenerateTestRandomChunks(50)
+	if _, err := s.Put(context.Background(), chunk.ModePutUpload, uploaded...); err != nil {
+		t.Fatal(err)
+	}
+	pinned := chunktesting.GenerateTestRandomChunks(10)
+	if _, err := s.Put(context.Background(), chunk.ModePutRequest, pinned...); err != nil {
+		t.Fatal(err)
+	}
+	// pin chunks twice, so that they are still pinned
+	// after they are unpinned once in the new store
+	for i := 0; i < 2; i++ {
+		if err := s.Set(context.Background(), chunk.ModeSetPin, addresses(pinned)...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	requested := putInBatches(t, s, chunk.ModePutRequest, gcCapacity/2)
+	index := pullIndex(t, s)
+
+	// the store is not closed before its files are copied
+	crashDir, err := ioutil.TempDir("", "chunk-store-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(crashDir)
+	if err := copyDir(dir, crashDir); err != nil {
+		t.Fatal(err)
+	}
+
+	cs, err := newStore(t, crashDir, gcCapacity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := cs.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	stored := append(uploaded, pinned...)
+	checkStored(t, cs, append(stored, requested...))
+	// chunks stored on retrieval requests are not synced
+	checkPullIndex(t, pullIndex(t, cs), uploaded)
+	checkPullIndexAppended(t, index, pullIndex(t, cs))
+
+	if err := cs.Set(context.Background(), chunk.ModeSetUnpin, addresses(pinned)...); err != nil {
+		t.Fatal(err)
+	}
+
+	chunks := putInBatches(t, cs, chunk.ModePutRequest, 3*gcCapacity)
+	waitGC(t, cs, chunks)
+	checkStored(t, cs, stored)
+}
+
+// openStore opens a store in a new temporary directory. The returned
+// cleanup function closes the store and removes the directory.
+func openStore(t *testing.T, newStore NewStoreFunc, capacity uint64) (s chunk.Store, cleanup func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "chunk-store-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err = newStore(t, dir, capacity)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return s, func() {
+		if err := s.Close(); err != nil {
+			t.Error(err)
+		}
+		os.RemoveAll(dir)
+	}
+}
+
+// putInBatches stores count new chunks in batches of 10, so that the
+// store can garbage collect while they are stored.
+func putInBatches(t *testing.T, s chunk.Store, mode chunk.ModePut, count int) (chunks []chunk.Chunk) {
+	t.Helper()
+
+	chunks = chunktesting.GenerateTestRandomChunks(count)
+	for i := 0; i < len(chunks); i += 10 {
+		end := i + 10
+		if end > len(chunks) {
+			end = len(chunks)
+		}
+		if _, err := s.Put(context.Background(), mode, chunks[i:end]...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return chunks
+}
+
+// waitGC waits until no more than gcCapacity of the provided chunks
+// are stored.
+func waitGC(t *testing.T, s chunk.Store, chunks []chunk.Chunk) {
+	t.Helper()
+
+	deadline := time.Now().Add(gcTimeout)
+	for {
+		yes, err := s.HasMulti(context.Background(), addresses(chunks)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var count int
+		for _, y := range yes {
+			if y {
+				count++
+			}
+		}
+		if count <= gcCapacity {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %v stored chunks after %s, want at most %v", count, gcTimeout, gcCapacity)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// checkStored validates that all chunks are stored with the correct data.
+func checkStored(t *testing.T, s chunk.Store, chunks []chunk.Chunk) {
+	t.Helper()
+
+	for _, want := range chunks {
+		got, err := s.Get(context.Background(), chunk.ModeGetRequest, want.Address())
+		if err != nil {
+			t.Fatalf("get chunk %s: %v", want.Address(), err)
+		}
+		checkChunk(t, got, want)
+
+		yes, err := s.Has(context.Background(), want.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !yes {
+			t.Fatalf("chunk %s not found by has", want.Address())
+		}
+	}
+}
+
+// checkNotStored validates that none of the chunks is stored.
+func checkNotStored(t *testing.T, s chunk.Store, addrs ...chunk.Address) {
+	t.Helper()
+
+	for _, addr := range addrs {
+		_, err := s.Get(context.Background(), chunk.ModeGetRequest, addr)
+		if !errors.Is(err, chunk.ErrChunkNotFound) {
+			t.Fatalf("chunk %s: got error %v, want %v", addr, err, chunk.ErrChunkNotFound)
+		}
+		yes, err := s.Has(context.Background(), addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if yes {
+			t.Fatalf("chunk %s found by has", addr)
+		}
+	}
+}
+
+// checkChunk validates that the got chunk has the same
+// address and data as the want chunk.
+func checkChunk(t *testing.T, got, want chunk.Chunk) {
+	t.Helper()
+
+	if !bytes.Equal(got.Address(), want.Address()) {
+		t.Fatalf("got chunk address %s, want %s", got.Address(), want.Address())
+	}
+	if !bytes.Equal(got.Data(), want.Data()) {
+		t.Fatalf("got invalid data for chunk %s", want.Address())
+	}
+}
+
+// pullIndex returns descriptors of all chunks provided by pull
+// subscriptions, for every bin.
+func pullIndex(t *testing.T, s chunk.Store) (index map[uint8][]chunk.Descriptor) {
+	t.Helper()
+
+	index = make(map[uint8][]chunk.Descriptor)
+	for bin := uint8(0); bin <= chunk.MaxPO; bin++ {
+		last, err := s.LastPullSubscriptionBinID(bin)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if last == 0 {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		c, stop := s.SubscribePull(ctx, bin, 0, last)
+		for d := range c {
+			index[bin] = append(index[bin], d)
+		}
+		stop()
+		cancel()
+
+		descriptors := index[bin]
+		if len(descriptors) == 0 || descriptors[len(descriptors)-1].BinID != last {
+			t.Fatalf("bin %v: pull subscription did not reach the last bin id %v", bin, last)
+		}
+	}
+	return index
+}
+
+// checkPullIndex validates that every chunk is in the pull index
+// exactly once and that bin IDs are increasing in every bin.
+func checkPullIndex(t *testing.T, index map[uint8][]chunk.Descriptor, chunks []chunk.Chunk) {
+	t.Helper()
+
+	seen := make(map[string]struct{})
+	for bin, descriptors := range index {
+		var binID uint64
+		for _, d := range descriptors {
+			if d.BinID <= binID {
+				t.Fatalf("bin %v: bin id %v is not greater than the previous %v", bin, d.BinID, binID)
+			}
+			binID = d.BinID
+			key := string(d.Address)
+			if _, ok := seen[key]; ok {
+				t.Fatalf("chunk %s is in the pull index more than once", d.Address)
+			}
+			seen[key] = struct{}{}
+		}
+	}
+	if len(seen) != len(chunks) {
+		t.Fatalf("got %v chunks in the pull index, want %v", len(seen), len(chunks))
+	}
+	for _, ch := range chunks {
+		if _, ok := seen[string(ch.Address())]; !ok {
+			t.Fatalf("chunk %s is not in the pull index", ch.Address())
+		}
+	}
+}
+
+// checkPullIndexAppended validates that the pull index newIndex
+// starts with all descriptors from the index and that new descriptors
+// were only appended to it.
+func checkPullIndexAppended(t *testing.T, index, newIndex map[uint8][]chunk.Descriptor) {
+	t.Helper()
+
+	for bin, descriptors := range index {
+		newDescriptors := newIndex[bin]
+		if len(newDescriptors) < len(descriptors) {
+			t.Fatalf("bin %v: got %v descriptors, want at least %v", bin, len(newDescriptors), len(descriptors))
+		}
+		for i, d := range descriptors {
+			got := newDescriptors[i]
+			if !bytes.Equal(got.Address, d.Address) || got.BinID != d.BinID {
+				t.Fatalf("bin %v: got descriptor %s at position %v, want %s", bin, got.String(), i, d.String())
+			}
+		}
+	}
+}
+
+// copyDir copies files from the src directory to the dst directory.
+// Files that are removed while they are copied are skipped.
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode())
+		}
+		err = copyFile(path, target)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	})
+}
+
+// copyFile copies the contents of the src file to the dst file.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func addresses(chunks []chunk.Chunk) (addrs []chunk.Address) {
+	addrs = make([]chunk.Address, len(chunks))
+	for i, ch := range chunks {
+		addrs[i] = ch.Address()
+	}
+	return addrs
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/ethersphere/swarm"
 	"github.com/ethersphere/swarm/log"
 	"github.com/ethersphere/swarm/storage"
+	"github.com/ethersphere/swarm/storage/localstore"
 )
 
 type Bzz struct {
@@ -79,3 +80,17 @@ func (b *Bzz) IsPushSynced(tagname string) (bool, error) {
 
 	return isSynced, nil
 }
+
+// StorageIndices returns counts and samples of items of all localstore indexes,
+// the schema name and the migration history of the node database
+func (b *Bzz) StorageIndices(sampleSize int) (*localstore.StorageIndices, error) {
+	var info localstore.StorageIndices
+
+	err := b.client.Call(&info, "debug_storageIndices", sampleSize)
+	if err != nil {
+		log.Error("error calling host for storageIndices", "err", err)
+		return nil, err
+	}
+
+	return &info, nil
+}
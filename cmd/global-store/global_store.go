@@ -27,6 +27,7 @@ import (
 	"github.com/ethersphere/swarm/storage/mock"
 	"github.com/ethersphere/swarm/storage/mock/db"
 	"github.com/ethersphere/swarm/storage/mock/mem"
+	mockRPC "github.com/ethersphere/swarm/storage/mock/rpc"
 	cli "gopkg.in/urfave/cli.v1"
 )
 
@@ -45,7 +46,7 @@ func startHTTP(ctx *cli.Context) (err error) {
 	}
 	log.Info("http", "address", listener.Addr().String())
 
-	return http.Serve(listener, server)
+	return http.Serve(listener, mockRPC.NewAuthHandler(server, ctx.String("auth-token")))
 }
 
 // startWS starts a global store with WebSocket RPC server.
@@ -64,7 +65,7 @@ func startWS(ctx *cli.Context) (err error) {
 	origins := ctx.StringSlice("origins")
 	log.Info("websocket", "address", listener.Addr().String(), "origins", origins)
 
-	return http.Serve(listener, server.WebsocketHandler(origins))
+	return http.Serve(listener, mockRPC.NewAuthHandler(server.WebsocketHandler(origins), ctx.String("auth-token")))
 }
 
 // newServer creates a global store and starts a chunk explorer server if configured.
@@ -94,7 +95,7 @@ func newServer(ctx *cli.Context) (server *rpc.Server, cleanup func(), err error)
 	}
 
 	server = rpc.NewServer()
-	if err := server.RegisterName("mockStore", globalStore); err != nil {
+	if err := server.RegisterName("mockStore", mockRPC.NewService(globalStore)); err != nil {
 		cleanup()
 		return nil, nil, err
 	}
@@ -155,6 +155,55 @@ func testWebsocket(t *testing.T, put bool, args ...string) {
 	}
 }
 
+// TestWebsocket_Auth tests that global store with authentication
+// token accepts only websocket clients that provide it.
+func TestWebsocket_Auth(t *testing.T) {
+	addr := findFreeTCPAddress(t)
+	testCmd := runGlobalStore(t, "ws", "--addr", addr, "--auth-token", "secret")
+	defer testCmd.Kill()
+
+	var client *rpc.Client
+	var err error
+	for i := 0; i < 1000; i++ {
+		client, err = mockRPC.Dial(context.Background(), "ws://:secret@"+addr)
+		if err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := mockRPC.NewGlobalStore(client)
+	defer store.Close()
+
+	wantKey := "key"
+	if err := store.Put(common.HexToAddress("123abc"), []byte(wantKey), []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+
+	// keys are streamed from the global store
+	var keys []string
+	err = store.IterateKeys(context.Background(), nil, func(key []byte) (bool, error) {
+		keys = append(keys, string(key))
+		return false, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != wantKey {
+		t.Errorf("got keys %v, want [%s]", keys, wantKey)
+	}
+
+	if _, err := mockRPC.Dial(context.Background(), "ws://:wrong@"+addr); err == nil {
+		t.Error("expected error dialing with wrong token")
+	}
+	if _, err := mockRPC.Dial(context.Background(), "ws://"+addr); err == nil {
+		t.Error("expected error dialing without token")
+	}
+}
+
 // findFreeTCPAddress returns a local address (IP:Port) to which
 // global store can listen on.
 func findFreeTCPAddress(t *testing.T) (addr string) {
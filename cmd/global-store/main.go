@@ -68,6 +68,12 @@ func newApp() (app *cli.App) {
 			Value: nil,
 			Usage: "Chunk explorer CORS origin (can be specified multiple times).",
 		},
+		cli.StringFlag{
+			Name:   "auth-token",
+			Value:  "",
+			Usage:  "Token required from clients to access the global store RPC.",
+			EnvVar: "GLOBAL_STORE_AUTH_TOKEN",
+		},
 	}
 
 	app.Commands = []cli.Command{
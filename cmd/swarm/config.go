@@ -80,11 +80,25 @@ const (
 	SwarmEnvRNSAPI                  = "SWARM_RNS_API"
 	SwarmEnvENSAddr                 = "SWARM_ENS_ADDR"
 	SwarmEnvCORS                    = "SWARM_CORS"
+	SwarmEnvHTTPCacheMaxAge         = "SWARM_HTTP_CACHE_MAX_AGE"
+	SwarmEnvHTTPCacheENSMaxAge      = "SWARM_HTTP_CACHE_ENS_MAX_AGE"
 	SwarmEnvBootnodes               = "SWARM_BOOTNODES"
 	SwarmEnvPSSEnable               = "SWARM_PSS_ENABLE"
 	SwarmEnvStorePath               = "SWARM_STORE_PATH"
 	SwarmEnvStoreCapacity           = "SWARM_STORE_CAPACITY"
 	SwarmEnvStoreCacheCapacity      = "SWARM_STORE_CACHE_CAPACITY"
+	SwarmEnvProxyCacheCapacity      = "SWARM_STORE_PROXY_CACHE_CAPACITY"
+	SwarmEnvStoreSlowStart          = "SWARM_STORE_SLOW_START"
+	SwarmEnvStoreMinFreeSpace       = "SWARM_STORE_MIN_FREE_SPACE"
+	SwarmEnvStoreProximityInterval  = "SWARM_STORE_PROXIMITY_METRICS_INTERVAL"
+	SwarmEnvFetcherTimeout          = "SWARM_FETCHER_TIMEOUT"
+	SwarmEnvSearchTimeout           = "SWARM_SEARCH_TIMEOUT"
+	SwarmEnvUploadMemoryBudget      = "SWARM_UPLOAD_MEMORY_BUDGET"
+	SwarmEnvRecoveryEnabled         = "SWARM_RECOVERY_ENABLE"
+	SwarmEnvRecoveryTargets         = "SWARM_RECOVERY_TARGETS"
+	SwarmEnvRepairEnabled           = "SWARM_REPAIR_ENABLE"
+	SwarmEnvAPIKeysEnabled          = "SWARM_API_KEYS_ENABLE"
+	SwarmEnvAPIAdminKey             = "SWARM_API_ADMIN_KEY"
 	SwarmEnvBootnodeMode            = "SWARM_BOOTNODE_MODE"
 	SwarmEnvNATInterface            = "SWARM_NAT_INTERFACE"
 	SwarmAccessPassword             = "SWARM_ACCESS_PASSWORD"
@@ -252,6 +266,12 @@ func flagsOverride(currentConfig *bzzapi.Config, ctx *cli.Context) *bzzapi.Confi
 	if cors := ctx.GlobalString(CorsStringFlag.Name); cors != "" {
 		currentConfig.Cors = cors
 	}
+	if ctx.GlobalIsSet(SwarmHTTPCacheMaxAgeFlag.Name) {
+		currentConfig.HTTPCacheMaxAge = ctx.GlobalDuration(SwarmHTTPCacheMaxAgeFlag.Name)
+	}
+	if ctx.GlobalIsSet(SwarmHTTPCacheENSMaxAgeFlag.Name) {
+		currentConfig.HTTPCacheENSMaxAge = ctx.GlobalDuration(SwarmHTTPCacheENSMaxAgeFlag.Name)
+	}
 	if storePath := ctx.GlobalString(SwarmStorePath.Name); storePath != "" {
 		currentConfig.ChunkDbPath = storePath
 	}
@@ -261,6 +281,42 @@ func flagsOverride(currentConfig *bzzapi.Config, ctx *cli.Context) *bzzapi.Confi
 	if ctx.GlobalIsSet(SwarmStoreCacheCapacity.Name) {
 		currentConfig.CacheCapacity = ctx.GlobalUint(SwarmStoreCacheCapacity.Name)
 	}
+	if ctx.GlobalIsSet(SwarmProxyCacheCapacity.Name) {
+		currentConfig.ProxyCacheCapacity = ctx.GlobalUint64(SwarmProxyCacheCapacity.Name)
+	}
+	if ctx.GlobalIsSet(SwarmStoreSlowStartFlag.Name) {
+		currentConfig.SlowStart = ctx.GlobalBool(SwarmStoreSlowStartFlag.Name)
+	}
+	if ctx.GlobalIsSet(SwarmStoreMinFreeSpaceFlag.Name) {
+		currentConfig.MinFreeSpace = ctx.GlobalUint64(SwarmStoreMinFreeSpaceFlag.Name) * 1024 * 1024
+	}
+	if ctx.GlobalIsSet(SwarmStoreProximityIntervalFlag.Name) {
+		currentConfig.ProximityMetricsInterval = ctx.GlobalDuration(SwarmStoreProximityIntervalFlag.Name)
+	}
+	if ctx.GlobalIsSet(SwarmFetcherTimeoutFlag.Name) {
+		currentConfig.FetcherTimeout = ctx.GlobalDuration(SwarmFetcherTimeoutFlag.Name)
+	}
+	if ctx.GlobalIsSet(SwarmSearchTimeoutFlag.Name) {
+		currentConfig.SearchTimeout = ctx.GlobalDuration(SwarmSearchTimeoutFlag.Name)
+	}
+	if ctx.GlobalIsSet(SwarmUploadMemoryBudgetFlag.Name) {
+		currentConfig.UploadMemoryBudget = ctx.GlobalInt64(SwarmUploadMemoryBudgetFlag.Name) * 1024 * 1024
+	}
+	if ctx.GlobalIsSet(SwarmRecoveryEnabledFlag.Name) {
+		currentConfig.RecoveryEnabled = ctx.GlobalBool(SwarmRecoveryEnabledFlag.Name)
+	}
+	if targets := ctx.GlobalString(SwarmRecoveryTargetsFlag.Name); targets != "" {
+		currentConfig.RecoveryTargets = strings.Split(targets, ",")
+	}
+	if ctx.GlobalIsSet(SwarmRepairEnabledFlag.Name) {
+		currentConfig.RepairEnabled = ctx.GlobalBool(SwarmRepairEnabledFlag.Name)
+	}
+	if ctx.GlobalIsSet(SwarmAPIKeysEnabledFlag.Name) {
+		currentConfig.APIKeysEnabled = ctx.GlobalBool(SwarmAPIKeysEnabledFlag.Name)
+	}
+	if adminKey := ctx.GlobalString(SwarmAPIAdminKeyFlag.Name); adminKey != "" {
+		currentConfig.APIAdminKey = adminKey
+	}
 	if ctx.GlobalIsSet(SwarmBootnodeModeFlag.Name) {
 		currentConfig.BootnodeMode = ctx.GlobalBool(SwarmBootnodeModeFlag.Name)
 	}
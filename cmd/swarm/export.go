@@ -0,0 +1,135 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/log"
+	swarm "github.com/ethersphere/swarm/api/client"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var exportCommand = cli.Command{
+	Action:             exportContent,
+	CustomHelpTemplate: helpTemplate,
+	Name:               "export",
+	Usage:              "export chunks of content as a tar archive (use - to send to stdout)",
+	ArgsUsage:          "--root <hash> <file>",
+	Description: `
+Export all chunks that belong to the content with the root hash as a tar
+archive (use - to send to stdout). Manifests, files referenced by them
+and the latest updates of referenced feeds are included.
+
+    swarm export --root 2477cc8584cc61091b5cc084cdcdb45bf3c6210c263b0143f030cf7d750e894d content.tar
+
+The archive can be imported to a running node with swarm import, or to
+a local chunk database with swarm db import.
+`,
+	Flags: []cli.Flag{
+		SwarmExportRootFlag,
+	},
+}
+
+var importCommand = cli.Command{
+	Action:             importContent,
+	CustomHelpTemplate: helpTemplate,
+	Name:               "import",
+	Usage:              "import chunks from a tar archive created by swarm export (use - to read from stdin)",
+	ArgsUsage:          "<file>",
+	Description: `
+Import chunks from a tar archive created by swarm export to a running node
+(use - to read from stdin). Imported chunks are uploaded to the network.
+
+    swarm import content.tar
+`,
+}
+
+func exportContent(ctx *cli.Context) {
+	args := ctx.Args()
+	if len(args) != 1 {
+		utils.Fatalf("invalid arguments, please specify <file> (path to write the tar archive to, - for stdout)")
+	}
+	root := ctx.String(SwarmExportRootFlag.Name)
+	if root == "" {
+		utils.Fatalf("please specify the root hash of the content to export with --%s", SwarmExportRootFlag.Name)
+	}
+
+	bzzapi := strings.TrimRight(ctx.GlobalString(SwarmApiFlag.Name), "/")
+	client := swarm.NewClient(bzzapi)
+
+	archive, err := client.Export(root)
+	if err != nil {
+		utils.Fatalf("error exporting content: %s", err)
+	}
+	defer archive.Close()
+
+	var out io.Writer
+	if args[0] == "-" {
+		out = os.Stdout
+	} else {
+		f, err := os.Create(args[0])
+		if err != nil {
+			utils.Fatalf("error opening output file: %s", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	// reading the archive fails if the export is interrupted
+	// on the node, so that an incomplete archive is not reported
+	// as successfully exported
+	n, err := io.Copy(out, archive)
+	if err != nil {
+		utils.Fatalf("error exporting content: %s", err)
+	}
+
+	log.Info(fmt.Sprintf("successfully exported %d bytes of content %s", n, root))
+}
+
+func importContent(ctx *cli.Context) {
+	args := ctx.Args()
+	if len(args) != 1 {
+		utils.Fatalf("invalid arguments, please specify <file> (path to read the tar archive from, - for stdin)")
+	}
+
+	var in io.Reader
+	if args[0] == "-" {
+		in = os.Stdin
+	} else {
+		f, err := os.Open(args[0])
+		if err != nil {
+			utils.Fatalf("error opening input file: %s", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	bzzapi := strings.TrimRight(ctx.GlobalString(SwarmApiFlag.Name), "/")
+	client := swarm.NewClient(bzzapi)
+
+	count, err := client.Import(in)
+	if err != nil {
+		utils.Fatalf("error importing chunks: %s", err)
+	}
+
+	log.Info(fmt.Sprintf("successfully imported %d chunks", count))
+}
@@ -18,7 +18,12 @@
 package main
 
 import (
+	"time"
+
+	"github.com/ethersphere/swarm/api"
 	"github.com/ethersphere/swarm/network"
+	"github.com/ethersphere/swarm/network/timeouts"
+	"github.com/ethersphere/swarm/storage"
 	cli "gopkg.in/urfave/cli.v1"
 )
 
@@ -175,6 +180,17 @@ var (
 		Usage:  "Domain on which to send Access-Control-Allow-Origin header (multiple domains can be supplied separated by a ',')",
 		EnvVar: SwarmEnvCORS,
 	}
+	SwarmHTTPCacheMaxAgeFlag = cli.DurationFlag{
+		Name:   "http.cache.max-age",
+		Usage:  "Cache-Control max-age of HTTP responses for content addressed by a swarm hash, 0 disables caching",
+		EnvVar: SwarmEnvHTTPCacheMaxAge,
+		Value:  api.DefaultHTTPCacheMaxAge,
+	}
+	SwarmHTTPCacheENSMaxAgeFlag = cli.DurationFlag{
+		Name:   "http.cache.ens-max-age",
+		Usage:  "Cache-Control max-age of HTTP responses for content addressed by an ENS name, 0 to revalidate on every request",
+		EnvVar: SwarmEnvHTTPCacheENSMaxAge,
+	}
 	SwarmStorePath = cli.StringFlag{
 		Name:   "store.path",
 		Usage:  "Path to leveldb chunk DB (default <$GETH_ENV_DIR>/swarm/bzz-<$BZZ_KEY>/chunks)",
@@ -191,6 +207,69 @@ var (
 		EnvVar: SwarmEnvStoreCacheCapacity,
 		Value:  10000,
 	}
+	SwarmProxyCacheCapacity = cli.Uint64Flag{
+		Name:   "store.proxy-cache.size",
+		Usage:  "Number of chunks retrieved outside of the area of responsibility kept in a separate cache store, 0 disables it",
+		EnvVar: SwarmEnvProxyCacheCapacity,
+	}
+	SwarmStoreSlowStartFlag = cli.BoolFlag{
+		Name:   "store.slow-start",
+		Usage:  "Serve reads while the chunk DB garbage collection size is rebuilt in the background after an unclean shutdown",
+		EnvVar: SwarmEnvStoreSlowStart,
+	}
+	SwarmStoreMinFreeSpaceFlag = cli.Uint64Flag{
+		Name:   "store.min-free-space",
+		Usage:  "Megabytes of free disk space below which chunks from the network are not stored, 0 to only stop when the disk is full",
+		EnvVar: SwarmEnvStoreMinFreeSpace,
+	}
+	SwarmStoreProximityIntervalFlag = cli.DurationFlag{
+		Name:   "store.proximity-metrics-interval",
+		Usage:  "Period of chunk DB proximity histogram metrics updates, which iterate over all stored chunks, negative to disable them",
+		EnvVar: SwarmEnvStoreProximityInterval,
+	}
+	SwarmFetcherTimeoutFlag = cli.DurationFlag{
+		Name:   "fetcher.timeout",
+		Usage:  "Max time a chunk is searched for on the network",
+		EnvVar: SwarmEnvFetcherTimeout,
+		Value:  timeouts.FetcherGlobalTimeout,
+	}
+	SwarmSearchTimeoutFlag = cli.DurationFlag{
+		Name:   "fetcher.search-timeout",
+		Usage:  "Max time to wait for a single peer to deliver a chunk before another peer is tried",
+		EnvVar: SwarmEnvSearchTimeout,
+		Value:  timeouts.SearchTimeout,
+	}
+	SwarmUploadMemoryBudgetFlag = cli.Int64Flag{
+		Name:   "upload.memory-budget",
+		Usage:  "Max megabytes of chunk data held in memory by all uploads until stored, 0 for unlimited",
+		EnvVar: SwarmEnvUploadMemoryBudget,
+		Value:  storage.DefaultUploadMemoryBudget / (1024 * 1024),
+	}
+	SwarmRecoveryEnabledFlag = cli.BoolFlag{
+		Name:   "recovery",
+		Usage:  "Request chunks that can not be retrieved from repair nodes over pss",
+		EnvVar: SwarmEnvRecoveryEnabled,
+	}
+	SwarmRecoveryTargetsFlag = cli.StringFlag{
+		Name:   "recovery.targets",
+		Usage:  "Hex encoded address prefixes of repair nodes recovery requests are sent to, separated by ','",
+		EnvVar: SwarmEnvRecoveryTargets,
+	}
+	SwarmRepairEnabledFlag = cli.BoolFlag{
+		Name:   "repair",
+		Usage:  "Respond to chunk recovery requests with locally stored chunks",
+		EnvVar: SwarmEnvRepairEnabled,
+	}
+	SwarmAPIKeysEnabledFlag = cli.BoolFlag{
+		Name:   "api-keys",
+		Usage:  "Require API keys for HTTP API requests",
+		EnvVar: SwarmEnvAPIKeysEnabled,
+	}
+	SwarmAPIAdminKeyFlag = cli.StringFlag{
+		Name:   "api-admin-key",
+		Usage:  "API key with privileges to manage other API keys",
+		EnvVar: SwarmEnvAPIAdminKey,
+	}
 	SwarmCompressedFlag = cli.BoolFlag{
 		Name:  "compressed",
 		Usage: "Prints encryption keys in compressed form",
@@ -228,6 +307,10 @@ var (
 		Name:  "legacy",
 		Usage: "Use this flag when importing a db export from a legacy local store database dump (for schemas older than 'sanctuary')",
 	}
+	SwarmExportRootFlag = cli.StringFlag{
+		Name:  "root",
+		Usage: "Root hash or ENS name of the content to export",
+	}
 	SwarmPinFlag = cli.BoolFlag{
 		Name:  "pin",
 		Usage: "Use this flag to pin the file after upload is complete. This flag is used when uploading a file.",
@@ -236,6 +319,19 @@ var (
 		Name:  "enable-pinning",
 		Usage: "Use this flag to enable the pinning feature",
 	}
+	SwarmWatchFlag = cli.BoolFlag{
+		Name:  "watch",
+		Usage: "Watch the uploaded directory and upload the changes until interrupted, printing the new manifest hash on every change",
+	}
+	SwarmWatchIntervalFlag = cli.DurationFlag{
+		Name:  "watch.interval",
+		Usage: "Interval in which the watched directory is checked for changes",
+		Value: 2 * time.Second,
+	}
+	SwarmWatchFeedFlag = cli.BoolFlag{
+		Name:  "watch.feed",
+		Usage: "Publish the manifest hash on every change to the feed with the topic set by --topic and --name, signed by --bzzaccount",
+	}
 	SwarmProgressFlag = cli.BoolFlag{
 		Name:  "progress",
 		Usage: "Use this flag to enable tracking of the upload progress through the CLI",
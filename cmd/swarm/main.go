@@ -17,6 +17,7 @@
 package main
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"encoding/hex"
 	"fmt"
@@ -40,7 +41,6 @@ import (
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/nat"
-	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethersphere/swarm"
 	bzzapi "github.com/ethersphere/swarm/api"
 	"github.com/ethersphere/swarm/internal/debug"
@@ -146,6 +146,9 @@ func init() {
 		fsCommand,
 		// See db.go
 		dbCommand,
+		// See export.go
+		exportCommand,
+		importCommand,
 		// See config.go
 		DumpConfigCommand,
 		// hashesCommand
@@ -176,6 +179,8 @@ func init() {
 		SwarmNATInterfaceFlag,
 		// bzzd-specific flags
 		CorsStringFlag,
+		SwarmHTTPCacheMaxAgeFlag,
+		SwarmHTTPCacheENSMaxAgeFlag,
 		EnsAPIFlag,
 		RnsAPIFlag,
 		SwarmTomlConfigPathFlag,
@@ -213,7 +218,21 @@ func init() {
 		SwarmStorePath,
 		SwarmStoreCapacity,
 		SwarmStoreCacheCapacity,
+		SwarmProxyCacheCapacity,
+		SwarmStoreSlowStartFlag,
+		SwarmStoreMinFreeSpaceFlag,
+		SwarmStoreProximityIntervalFlag,
 		SwarmGlobalStoreAPIFlag,
+		SwarmFetcherTimeoutFlag,
+		SwarmSearchTimeoutFlag,
+		SwarmUploadMemoryBudgetFlag,
+		// chunk recovery flags
+		SwarmRecoveryEnabledFlag,
+		SwarmRecoveryTargetsFlag,
+		SwarmRepairEnabledFlag,
+		// http api flags
+		SwarmAPIKeysEnabledFlag,
+		SwarmAPIAdminKeyFlag,
 		// debugging
 		SwarmMutexProfileFlag,
 		SwarmBlockProfileFlag,
@@ -233,6 +252,7 @@ func init() {
 		runtime.GOMAXPROCS(runtime.NumCPU())
 		if err := debug.Setup(debug.Options{
 			Debug:            ctx.GlobalBool(debugFlag.Name),
+			LogJSON:          ctx.GlobalBool(logJSONFlag.Name),
 			Verbosity:        ctx.GlobalInt(verbosityFlag.Name),
 			Vmodule:          ctx.GlobalString(vmoduleFlag.Name),
 			BacktraceAt:      ctx.GlobalString(backtraceAtFlag.Name),
@@ -385,7 +405,8 @@ func registerBzzService(bzzconfig *bzzapi.Config, stack *node.Node) {
 		var nodeStore *mock.NodeStore
 		if bzzconfig.GlobalStoreAPI != "" {
 			// connect to global store
-			client, err := rpc.Dial(bzzconfig.GlobalStoreAPI)
+			// token for authenticated global store can be provided in the URL user information
+			client, err := mockrpc.Dial(context.Background(), bzzconfig.GlobalStoreAPI)
 			if err != nil {
 				return nil, fmt.Errorf("global store: %v", err)
 			}
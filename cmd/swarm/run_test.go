@@ -59,7 +59,7 @@ func init() {
 const clusterSize = 3
 
 func serverFunc(api *api.API, pinAPI *pin.API) swarmhttp.TestServer {
-	return swarmhttp.NewServer(api, pinAPI, "")
+	return swarmhttp.NewServer(api, pinAPI, nil, "")
 }
 func TestMain(m *testing.M) {
 	// check if we have been reexec'd
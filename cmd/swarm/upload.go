@@ -18,22 +18,26 @@
 package main
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"os/signal"
 	"os/user"
 	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethersphere/swarm/api/client"
 	swarm "github.com/ethersphere/swarm/api/client"
 	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/storage/feed"
 	"github.com/vbauerster/mpb"
 	"github.com/vbauerster/mpb/decor"
 
@@ -48,8 +52,15 @@ var (
 		Name:               "up",
 		Usage:              "uploads a file or directory to swarm using the HTTP API",
 		ArgsUsage:          "<file>",
-		Flags:              []cli.Flag{SwarmEncryptedFlag, SwarmPinFlag, SwarmProgressFlag, SwarmVerboseFlag},
-		Description:        "uploads a file or directory to swarm using the HTTP API and prints the root hash",
+		Flags: []cli.Flag{
+			SwarmEncryptedFlag, SwarmPinFlag, SwarmProgressFlag, SwarmVerboseFlag,
+			SwarmWatchFlag, SwarmWatchIntervalFlag, SwarmWatchFeedFlag, SwarmFeedNameFlag, SwarmFeedTopicFlag,
+		},
+		Description: `uploads a file or directory to swarm using the HTTP API and prints the root hash
+					With --watch, the directory is checked for changes until the command is interrupted.
+					Only changed files are uploaded and the manifest is patched with them, printing
+					the new root hash on every change. With --watch.feed, the new root hash is also
+					published to the feed with the topic set by --topic and --name.`,
 	}
 
 	pollDelay   = 200 * time.Millisecond
@@ -79,6 +90,7 @@ func upload(ctx *cli.Context) {
 		toPin           = ctx.Bool(SwarmPinFlag.Name)
 		progress        = ctx.Bool(SwarmProgressFlag.Name)
 		anon            = ctx.Bool(SwarmAnonymousUploadFlag.Name)
+		watch           = ctx.Bool(SwarmWatchFlag.Name)
 		autoDefaultPath = false
 		file            string
 	)
@@ -175,6 +187,20 @@ func upload(ctx *cli.Context) {
 			return client.Upload(f, "", toEncrypt, toPin, anon)
 		}
 	}
+
+	// snapshot the directory before the upload
+	// not to miss changes made during it
+	var snapshot swarm.DirectorySnapshot
+	if watch {
+		if !stat.IsDir() {
+			utils.Fatalf("Only directories can be watched")
+		}
+		snapshot, err = swarm.SnapshotDirectory(file)
+		if err != nil {
+			utils.Fatalf("Error reading directory: %s", err)
+		}
+	}
+
 	start := time.Now()
 
 	hash, err := doUpload()
@@ -182,6 +208,12 @@ func upload(ctx *cli.Context) {
 		utils.Fatalf("Upload failed: %s", err)
 	}
 
+	if watch {
+		fmt.Println(hash)
+		watchDirectory(ctx, client, file, defaultPath, hash, snapshot, toEncrypt, toPin, anon)
+		return
+	}
+
 	// dont show the progress bar if `progress` flag is not set
 	if !progress {
 		fmt.Println(hash)
@@ -211,6 +243,90 @@ func upload(ctx *cli.Context) {
 	fmt.Println("Your Swarm hash should now be retrievable from other nodes!")
 }
 
+// watchDirectory periodically checks the directory for changes since the
+// snapshot and patches the manifest with the given hash with them, until
+// the process is interrupted. New manifest hashes are printed and optionally
+// published to a feed.
+func watchDirectory(ctx *cli.Context, client *client.Client, dir, defaultPath, hash string, snapshot swarm.DirectorySnapshot, toEncrypt, toPin, anon bool) {
+	var publish func(hash string) error
+	if ctx.Bool(SwarmWatchFeedFlag.Name) {
+		signer := NewGenericSigner(ctx)
+		topic := getTopic(ctx)
+		publish = func(hash string) error {
+			return publishToFeed(client, signer, topic, hash)
+		}
+		if err := publish(hash); err != nil {
+			utils.Fatalf("Error publishing to feed: %s", err)
+		}
+	}
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+
+	ticker := time.NewTicker(ctx.Duration(SwarmWatchIntervalFlag.Name))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-sigc:
+			return
+		}
+
+		s, err := swarm.SnapshotDirectory(dir)
+		if err != nil {
+			// files may be removed while the directory is read
+			log.Warn("Error reading watched directory", "dir", dir, "err", err)
+			continue
+		}
+		changed, removed := s.Changes(snapshot)
+		if len(changed) == 0 && len(removed) == 0 {
+			continue
+		}
+		log.Debug("Watched directory changed", "dir", dir, "changed", len(changed), "removed", len(removed))
+
+		// the snapshot is not updated on errors,
+		// so that the changes are retried
+		newHash, err := client.UpdateDirectory(dir, defaultPath, hash, changed, removed, toEncrypt, toPin, anon)
+		if err != nil {
+			log.Error("Error uploading changes", "dir", dir, "err", err)
+			continue
+		}
+		snapshot = s
+		hash = newHash
+		fmt.Println(hash)
+
+		if publish != nil {
+			if err := publish(hash); err != nil {
+				log.Error("Error publishing to feed", "hash", hash, "err", err)
+			}
+		}
+	}
+}
+
+// publishToFeed publishes the hex encoded hash as a new
+// update of the feed with the signer as its user.
+func publishToFeed(client *client.Client, signer feed.Signer, topic feed.Topic, hash string) error {
+	data, err := hex.DecodeString(hash)
+	if err != nil {
+		return err
+	}
+	query := new(feed.Query)
+	query.User = signer.Address()
+	query.Topic = topic
+
+	request, err := client.GetFeedRequest(query, "")
+	if err != nil {
+		return err
+	}
+	request.SetData(data)
+	if err := request.Sign(signer); err != nil {
+		return err
+	}
+	return client.UpdateFeed(request)
+}
+
 func pollTag(client *client.Client, hash string, tag *chunk.Tag, bars map[string]*mpb.Bar) {
 	oldTag := tag
 	lastTime := time.Now()
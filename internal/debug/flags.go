@@ -61,6 +61,7 @@ func rotatingFileHandler(logdir string) (log.Handler, error) {
 
 type Options struct {
 	Debug            bool
+	LogJSON          bool
 	Verbosity        int
 	Vmodule          string
 	BacktraceAt      string
@@ -79,15 +80,22 @@ type Options struct {
 func Setup(o Options) error {
 	// logging
 	log.PrintOrigins(o.Debug)
+	if o.LogJSON {
+		ostream = log.StreamHandler(os.Stderr, log.JSONFormatOrderedEx(false, true))
+	}
+	handler := ostream
 	if o.LogDirectory != "" {
 		rfh, err := rotatingFileHandler(o.LogDirectory)
 		if err != nil {
 			return err
 		}
-		glogger.SetHandler(log.MultiHandler(ostream, rfh))
+		handler = log.MultiHandler(ostream, rfh)
+	}
+	glogger.SetHandler(handler)
+	setVerbosity(log.Lvl(o.Verbosity))
+	if err := setVmodule(o.Vmodule); err != nil {
+		return err
 	}
-	glogger.Verbosity(log.Lvl(o.Verbosity))
-	glogger.Vmodule(o.Vmodule)
 	glogger.BacktraceAt(o.BacktraceAt)
 	log.Root().SetHandler(glogger)
 
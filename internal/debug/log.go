@@ -0,0 +1,176 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package debug
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// levels keeps track of the global verbosity and the verbosity of
+// individual packages and source files set on glogger, as the handler
+// does not expose them.
+var levels = struct {
+	mu        sync.Mutex
+	verbosity log.Lvl
+	modules   map[string]log.Lvl
+}{
+	verbosity: log.LvlInfo,
+	modules:   make(map[string]log.Lvl),
+}
+
+// LogLevels is the current log verbosity configuration.
+type LogLevels struct {
+	Verbosity int            `json:"verbosity"`
+	Modules   map[string]int `json:"modules"`
+}
+
+// LogAPI provides runtime control of the log verbosity of individual
+// packages and source files over RPC.
+type LogAPI struct{}
+
+// NewLogAPI creates a new LogAPI.
+func NewLogAPI() *LogAPI {
+	return &LogAPI{}
+}
+
+// Levels returns the global verbosity and the verbosity of all
+// package and source file patterns.
+func (*LogAPI) Levels() LogLevels {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	l := LogLevels{
+		Verbosity: int(levels.verbosity),
+		Modules:   make(map[string]int, len(levels.modules)),
+	}
+	for pattern, lvl := range levels.modules {
+		l.Modules[pattern] = int(lvl)
+	}
+	return l
+}
+
+// Verbosity sets the global log verbosity.
+func (*LogAPI) Verbosity(level int) {
+	setVerbosity(log.Lvl(level))
+}
+
+// SetLevel sets the log verbosity of packages or source files that
+// match the pattern (e.g. "network/*" or "netstore.go"),
+// leaving the verbosity of other patterns unchanged. The level
+// raises the global verbosity for matching log lines, it does not
+// lower it.
+func (*LogAPI) SetLevel(pattern string, level int) error {
+	if pattern == "" {
+		return errors.New("empty pattern")
+	}
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	modules := make(map[string]log.Lvl, len(levels.modules)+1)
+	for p, l := range levels.modules {
+		modules[p] = l
+	}
+	modules[pattern] = log.Lvl(level)
+	return applyModules(modules)
+}
+
+// ResetLevel removes the log verbosity set for the pattern, so that
+// the global verbosity applies to matching packages or source files.
+func (*LogAPI) ResetLevel(pattern string) error {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	if _, ok := levels.modules[pattern]; !ok {
+		return fmt.Errorf("no level set for pattern %q", pattern)
+	}
+	modules := make(map[string]log.Lvl, len(levels.modules))
+	for p, l := range levels.modules {
+		if p != pattern {
+			modules[p] = l
+		}
+	}
+	return applyModules(modules)
+}
+
+// setVerbosity sets the global log verbosity.
+func setVerbosity(level log.Lvl) {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	glogger.Verbosity(level)
+	levels.verbosity = level
+}
+
+// setVmodule replaces the verbosity of all packages and source files
+// with the ones in the vmodule pattern (e.g. "network/*=5,pss=4").
+func setVmodule(vmodule string) error {
+	modules := make(map[string]log.Lvl)
+	for _, rule := range strings.Split(vmodule, ",") {
+		if len(rule) == 0 {
+			continue
+		}
+		parts := strings.Split(rule, "=")
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid vmodule rule %q", rule)
+		}
+		level, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return fmt.Errorf("invalid vmodule rule %q: %v", rule, err)
+		}
+		modules[strings.TrimSpace(parts[0])] = log.Lvl(level)
+	}
+
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	return applyModules(modules)
+}
+
+// applyModules sets the verbosity of packages and source files on
+// glogger and records them if they are valid. It must be called with
+// levels.mu locked.
+func applyModules(modules map[string]log.Lvl) error {
+	patterns := make([]string, 0, len(modules))
+	for p := range modules {
+		patterns = append(patterns, p)
+	}
+	// the first matching pattern applies, so more specific patterns
+	// have to come first
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+
+	rules := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		rules = append(rules, fmt.Sprintf("%s=%d", p, modules[p]))
+	}
+	if err := glogger.Vmodule(strings.Join(rules, ",")); err != nil {
+		return err
+	}
+	levels.modules = modules
+	return nil
+}
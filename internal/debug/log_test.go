@@ -0,0 +1,66 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package debug
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestLogAPI validates setting and resetting verbosity of packages
+// on top of the vmodule pattern.
+func TestLogAPI(t *testing.T) {
+	defer setVmodule("")
+
+	if err := setVmodule("network/*=4,pss=5"); err != nil {
+		t.Fatal(err)
+	}
+	api := NewLogAPI()
+	if err := api.SetLevel("network/stream/*", 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.SetLevel("pss", 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.SetLevel("", 3); err == nil {
+		t.Error("expected error for empty pattern")
+	}
+	if err := api.SetLevel("a=b", 3); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+	if err := api.ResetLevel("network/*"); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.ResetLevel("network/*"); err == nil {
+		t.Error("expected error resetting unset pattern")
+	}
+
+	want := map[string]int{
+		"network/stream/*": 5,
+		"pss":              3,
+	}
+	if got := api.Levels().Modules; !reflect.DeepEqual(got, want) {
+		t.Errorf("got levels %v, want %v", got, want)
+	}
+
+	if err := setVmodule("invalid"); err == nil {
+		t.Error("expected error for invalid vmodule")
+	}
+	if got := api.Levels().Modules; !reflect.DeepEqual(got, want) {
+		t.Errorf("got levels %v after invalid vmodule, want %v", got, want)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package log
+
+import (
+	"context"
+
+	l "github.com/ethereum/go-ethereum/log"
+)
+
+type contextKey struct{}
+
+// WithContext returns a copy of the context that carries the provided key/value
+// pairs in addition to the ones already set by the parent context. They are
+// added to every line logged by the logger returned by FromContext, which
+// correlates log lines that belong to the same request.
+func WithContext(ctx context.Context, kv ...interface{}) context.Context {
+	parent := contextValues(ctx)
+	values := make([]interface{}, 0, len(parent)+len(kv))
+	values = append(values, parent...)
+	values = append(values, kv...)
+	return context.WithValue(ctx, contextKey{}, values)
+}
+
+// FromContext returns a logger with the key/value pairs set by WithContext
+// on the provided context.
+func FromContext(ctx context.Context) Logger {
+	return l.New(contextValues(ctx)...)
+}
+
+// contextValues returns the key/value pairs set by WithContext.
+func contextValues(ctx context.Context) []interface{} {
+	v, ok := ctx.Value(contextKey{}).([]interface{})
+	if ok {
+		return v
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package log
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	l "github.com/ethereum/go-ethereum/log"
+)
+
+// TestFromContext validates that the key/value pairs set on a context
+// and its parents are added to the lines logged by its logger.
+func TestFromContext(t *testing.T) {
+	var records []*l.Record
+	h := GetHandler()
+	defer l.Root().SetHandler(h)
+	l.Root().SetHandler(l.FuncHandler(func(r *l.Record) error {
+		records = append(records, r)
+		return nil
+	}))
+
+	parent := WithContext(context.Background(), "ruid", "abcd")
+	ctx := WithContext(parent, "trace", "1234")
+
+	FromContext(context.Background()).Info("background")
+	FromContext(parent).Info("parent", "key", "value")
+	FromContext(ctx).Info("child")
+
+	want := [][]interface{}{
+		{},
+		{"ruid", "abcd", "key", "value"},
+		{"ruid", "abcd", "trace", "1234"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %v records, want %v", len(records), len(want))
+	}
+	for i, r := range records {
+		if len(r.Ctx) == 0 && len(want[i]) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(r.Ctx, want[i]) {
+			t.Errorf("record %q: got context %v, want %v", r.Msg, r.Ctx, want[i])
+		}
+	}
+}
@@ -29,7 +29,7 @@ func NewBaseAddressLogger(baseAddr string, ctx ...interface{}) l.Logger {
 
 // New creates new swarm logger
 func New(ctx ...interface{}) Logger {
-	return l.New(ctx)
+	return l.New(ctx...)
 }
 
 // EnableBaseAddress enables the logging of the base address
@@ -911,6 +911,12 @@ func (k *Kademlia) IsClosestTo(addr []byte, filter func(*BzzPeer) bool) (closest
 	return closest
 }
 
+// IsStorer returns true if the local node advertises that it stores
+// chunks within its area of responsibility
+func (k *Kademlia) IsStorer() bool {
+	return hasCapability(k.Capabilities, capabilitiesStorer)
+}
+
 // IsWithinDepth checks whether a given address falls within
 // this node's saturation depth
 func (k *Kademlia) IsWithinDepth(addr []byte) bool {
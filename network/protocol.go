@@ -107,6 +107,32 @@ func isFullCapability(c *capability.Capability) bool {
 	return fullCapability.IsSameAs(c)
 }
 
+// hasCapability checks if the bit with index idx is set in the bzz capability.
+// Addresses without the bzz capability predate capability advertising, and are
+// assumed to have all capabilities, as full nodes used to be the only kind of node.
+func hasCapability(caps *capability.Capabilities, idx int) bool {
+	if caps == nil {
+		return true
+	}
+	c := caps.Get(CapabilityID)
+	if c == nil {
+		return true
+	}
+	return idx < len(c.Cap) && c.Cap[idx]
+}
+
+// IsStorer returns true if the address advertises that the node stores
+// chunks within its area of responsibility
+func (b *BzzAddr) IsStorer() bool {
+	return hasCapability(b.Capabilities, capabilitiesStorer)
+}
+
+// IsRetrieveRelay returns true if the address advertises that the node
+// forwards retrieve requests of other nodes
+func (b *BzzAddr) IsRetrieveRelay() bool {
+	return hasCapability(b.Capabilities, capabilitiesRelayRetrieve)
+}
+
 // BzzConfig captures the config params used by the hive
 type BzzConfig struct {
 	Address      *BzzAddr
@@ -414,3 +414,31 @@ func TestBzzHandshakeLightNode(t *testing.T) {
 		})
 	}
 }
+
+// TestBzzAddrCapabilities checks that storage and relay capabilities
+// are derived from the advertised light/full capability presets
+func TestBzzAddrCapabilities(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		cap           *capability.Capability
+		storer        bool
+		retrieveRelay bool
+	}{
+		{"full", newFullCapability(), true, true},
+		{"light", newLightCapability(), false, false},
+		{"none", nil, true, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			addr := RandomBzzAddr()
+			if tc.cap != nil {
+				addr.Capabilities.Add(tc.cap)
+			}
+			if got := addr.IsStorer(); got != tc.storer {
+				t.Errorf("got storer %v, want %v", got, tc.storer)
+			}
+			if got := addr.IsRetrieveRelay(); got != tc.retrieveRelay {
+				t.Errorf("got retrieve relay %v, want %v", got, tc.retrieveRelay)
+			}
+		})
+	}
+}
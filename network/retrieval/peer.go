@@ -34,6 +34,7 @@ type Peer struct {
 	logger     log.Logger             // logger with base and peer address
 	mtx        sync.Mutex             // synchronize retrievals
 	retrievals map[uint]chunk.Address // current ongoing retrievals
+	quit       chan struct{}          // closed when the peer disconnects
 }
 
 // NewPeer is the constructor for Peer
@@ -42,6 +43,7 @@ func NewPeer(peer *network.BzzPeer, baseKey *network.BzzAddr) *Peer {
 		BzzPeer:    peer,
 		logger:     log.NewBaseAddressLogger(baseKey.ShortString(), "peer", peer.BzzAddr.ShortString()),
 		retrievals: make(map[uint]chunk.Address),
+		quit:       make(chan struct{}),
 	}
 }
 
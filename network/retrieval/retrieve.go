@@ -37,7 +37,6 @@ import (
 	"github.com/ethersphere/swarm/chunk"
 	"github.com/ethersphere/swarm/log"
 	"github.com/ethersphere/swarm/network"
-	"github.com/ethersphere/swarm/network/timeouts"
 	"github.com/ethersphere/swarm/p2p/protocols"
 	"github.com/ethersphere/swarm/spancontext"
 	"github.com/ethersphere/swarm/storage"
@@ -58,7 +57,7 @@ var (
 
 	spec = &protocols.Spec{
 		Name:       "bzz-retrieve",
-		Version:    2,
+		Version:    3,
 		MaxMsgSize: 10 * 1024 * 1024,
 		Messages: []interface{}{
 			ChunkDelivery{},
@@ -69,6 +68,10 @@ var (
 	ErrNoPeerFound = errors.New("no peer found")
 )
 
+// DefaultHopLimit is the max number of hops of retrieve requests without
+// a hop limit.
+const DefaultHopLimit = storage.DefaultHopLimit
+
 // Price is the method through which a message type marks itself
 // as implementing the protocols.Price protocol and thus
 // as swap-enabled message
@@ -149,6 +152,8 @@ func (r *Retrieval) Run(bp *network.BzzPeer) error {
 	sp := NewPeer(bp, r.baseAddress)
 	r.addPeer(sp)
 	defer r.removePeer(sp)
+	// cancel pending handlers of the peer's retrieve requests on disconnect
+	defer close(sp.quit)
 
 	return sp.Peer.Run(r.handleMsg(sp))
 }
@@ -224,6 +229,11 @@ func (r *Retrieval) findPeerLB(ctx context.Context, req *storage.Request) (retPe
 				continue
 			}
 
+			// skip peer that neither stores chunks nor forwards requests, like light nodes
+			if !lbPeer.Peer.IsStorer() && !lbPeer.Peer.IsRetrieveRelay() {
+				continue
+			}
+
 			// do not send request back to peer who asked us. maybe merge with SkipPeer at some point
 			if bytes.Equal(req.Origin.Bytes(), id.Bytes()) {
 				continue
@@ -306,14 +316,41 @@ func (r *Retrieval) handleRetrieveRequest(ctx context.Context, p *Peer, msg *Ret
 
 	defer osp.Finish()
 
-	ctx, cancel := context.WithTimeout(ctx, timeouts.FetcherGlobalTimeout)
+	// the requester is not waiting for the delivery after its timeout
+	timeout := r.netStore.FetcherTimeout
+	if t := time.Duration(msg.Timeout) * time.Millisecond; t > 0 && t < timeout {
+		timeout = t
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	req := &storage.Request{
-		Addr:   msg.Addr,
-		Origin: p.ID(),
+	// there is no point in fetching the chunk if the requesting peer is gone
+	go func() {
+		select {
+		case <-p.quit:
+		case <-r.quit:
+		case <-ctx.Done():
+			return
+		}
+		cancel()
+	}()
+
+	var (
+		ch  chunk.Chunk
+		err error
+	)
+	if msg.HopLimit == 0 {
+		// the request can not be forwarded, serve it only from the local store
+		ch, err = r.netStore.Store.Get(ctx, chunk.ModeGetRequest, msg.Addr)
+	} else {
+		req := &storage.Request{
+			Addr:     msg.Addr,
+			Origin:   p.ID(),
+			HopLimit: msg.HopLimit,
+			Deadline: time.Now().Add(timeout),
+		}
+		ch, err = r.netStore.Get(ctx, chunk.ModeGetRequest, req)
 	}
-	chunk, err := r.netStore.Get(ctx, chunk.ModeGetRequest, req)
 	if err != nil {
 		retrieveChunkFail.Inc(1)
 		return fmt.Errorf("netstore.Get can not retrieve chunk for ref %s: %w", msg.Addr, err)
@@ -323,8 +360,8 @@ func (r *Retrieval) handleRetrieveRequest(ctx context.Context, p *Peer, msg *Ret
 
 	deliveryMsg := &ChunkDelivery{
 		Ruid:  msg.Ruid,
-		Addr:  chunk.Address(),
-		SData: chunk.Data(),
+		Addr:  ch.Address(),
+		SData: ch.Data(),
 	}
 
 	err = p.Send(ctx, deliveryMsg)
@@ -392,6 +429,11 @@ func (r *Retrieval) RequestFromPeers(ctx context.Context, req *storage.Request,
 	const maxFindPeerRetries = 5
 	retries := 0
 
+	ret, err := newRetrieveRequest(req)
+	if err != nil {
+		return nil, func() {}, err
+	}
+
 FINDPEER:
 	sp, err := r.findPeerLB(ctx, req)
 	if err != nil {
@@ -412,10 +454,6 @@ FINDPEER:
 		goto FINDPEER
 	}
 
-	ret := &RetrieveRequest{
-		Ruid: uint(rand.Uint32()),
-		Addr: req.Addr,
-	}
 	protoPeer.logger.Trace("sending retrieve request", "ref", ret.Addr, "origin", localID, "ruid", ret.Ruid)
 	protoPeer.addRetrieval(ret.Ruid, ret.Addr)
 	cleanup := func() {
@@ -432,6 +470,31 @@ FINDPEER:
 	return &spID, cleanup, nil
 }
 
+// newRetrieveRequest constructs the retrieve request message for the request,
+// with the hop limit reduced by the hop to the peer and the time left until
+// the request deadline.
+func newRetrieveRequest(req *storage.Request) (*RetrieveRequest, error) {
+	hopLimit, deadline := req.Limits()
+	if hopLimit == 0 {
+		hopLimit = DefaultHopLimit
+	}
+	var timeout uint64
+	if !deadline.IsZero() {
+		t := time.Until(deadline)
+		if t <= 0 {
+			return nil, context.DeadlineExceeded
+		}
+		// round up, so that zero, meaning no timeout, is never sent
+		timeout = uint64((t + time.Millisecond - 1) / time.Millisecond)
+	}
+	return &RetrieveRequest{
+		Ruid:     uint(rand.Uint32()),
+		Addr:     req.Addr,
+		HopLimit: hopLimit - 1,
+		Timeout:  timeout,
+	}, nil
+}
+
 func (r *Retrieval) Start(server *p2p.Server) error {
 	r.logger.Info("starting bzz-retrieve")
 	return nil
@@ -43,6 +43,7 @@ import (
 	"github.com/ethersphere/swarm/chunk"
 	chunktesting "github.com/ethersphere/swarm/chunk/testing"
 	"github.com/ethersphere/swarm/network"
+	"github.com/ethersphere/swarm/network/capability"
 	"github.com/ethersphere/swarm/network/simulation"
 	"github.com/ethersphere/swarm/p2p/protocols"
 	p2ptest "github.com/ethersphere/swarm/p2p/testing"
@@ -519,6 +520,37 @@ func TestRequestFromPeers(t *testing.T) {
 	}
 }
 
+// RequestFromPeers should not select peers that neither store chunks nor relay requests
+func TestRequestFromPeersSkipsLightNodes(t *testing.T) {
+	dummyPeerID := enode.HexID("3431c3939e1ee2a6345e976a8234f9870152d64879f30bc272a074f6859e75e8")
+
+	addr := network.RandomBzzAddr()
+	to := network.NewKademlia(addr.OAddr, network.NewKadParams())
+
+	// light node capability, only retrieving and pushing own chunks
+	lightCap := capability.NewCapability(network.CapabilityID, 16)
+	lightCap.Set(0)
+	lightCap.Set(1)
+	lightCaps := capability.NewCapabilities()
+	lightCaps.Add(lightCap)
+
+	protocolsPeer := protocols.NewPeer(p2p.NewPeer(dummyPeerID, "dummy", []p2p.Cap{{Name: "bzz-retrieve", Version: 1}}), nil, nil)
+	peer := network.NewPeer(&network.BzzPeer{
+		BzzAddr: network.RandomBzzAddr().WithCapabilities(lightCaps),
+		Peer:    protocolsPeer,
+	}, to)
+
+	to.On(peer)
+
+	s := New(to, nil, addr, nil)
+
+	req := storage.NewRequest(storage.Address(hash0[:]))
+	_, err := s.findPeerLB(context.Background(), req)
+	if err != ErrNoPeerFound {
+		t.Fatalf("got error %v, want %v", err, ErrNoPeerFound)
+	}
+}
+
 //TestHasPriceImplementation is to check that Retrieval provides priced messages
 func TestHasPriceImplementation(t *testing.T) {
 	price := (&ChunkDelivery{}).Price()
@@ -717,3 +749,118 @@ func newTestNetstore(t *testing.T) (prvkey *ecdsa.PrivateKey, netStore *storage.
 	}
 	return prvkey, netStore, cleanup
 }
+
+// TestNewRetrieveRequest tests that the hop limit of retrieve requests is
+// reduced on every hop and that the timeout is the time left until the deadline.
+func TestNewRetrieveRequest(t *testing.T) {
+	addr := storage.Address(hash0[:])
+
+	ret, err := newRetrieveRequest(storage.NewRequest(addr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ret.HopLimit != DefaultHopLimit-1 {
+		t.Errorf("got hop limit %v, want %v", ret.HopLimit, DefaultHopLimit-1)
+	}
+	if ret.Timeout != 0 {
+		t.Errorf("got timeout %v, want 0", ret.Timeout)
+	}
+
+	req := storage.NewRequest(addr)
+	req.HopLimit = 3
+	req.Deadline = time.Now().Add(2 * time.Second)
+	ret, err = newRetrieveRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ret.HopLimit != 2 {
+		t.Errorf("got hop limit %v, want 2", ret.HopLimit)
+	}
+	if ret.Timeout == 0 || ret.Timeout > 2000 {
+		t.Errorf("got timeout %v, want at most 2000", ret.Timeout)
+	}
+
+	req.Deadline = time.Now().Add(-time.Second)
+	if _, err := newRetrieveRequest(req); err != context.DeadlineExceeded {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+// TestRetrieveRequestHopLimit tests that retrieve requests which reached their
+// hop limit are not forwarded and that the hop limit and the timeout of other
+// requests are passed to the NetStore.
+func TestRetrieveRequestHopLimit(t *testing.T) {
+	pk, ns, cleanup := newTestNetstore(t)
+	defer cleanup()
+	bzzAddr := network.PrivateKeyToBzzKey(pk)
+
+	kad := network.NewKademlia(bzzAddr, network.NewKadParams())
+
+	requests := make(chan *storage.Request, 10)
+	ns.RemoteGet = func(ctx context.Context, req *storage.Request, localID enode.ID) (*enode.ID, func(), error) {
+		requests <- req
+		return nil, func() {}, ErrNoPeerFound
+	}
+
+	tester, _, teardown, err := newRetrievalTester(t, pk, ns, kad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	node := tester.Nodes[0]
+
+	notForwarded := chunktesting.GenerateTestRandomChunk().Address()
+	forwarded := chunktesting.GenerateTestRandomChunk().Address()
+
+	start := time.Now()
+	err = tester.TestExchanges(
+		p2ptest.Exchange{
+			Label: "retrieve requests with hop limits",
+			Triggers: []p2ptest.Trigger{
+				{
+					Code: 1,
+					Msg: &RetrieveRequest{
+						Ruid:     1,
+						Addr:     notForwarded,
+						HopLimit: 0,
+					},
+					Peer: node.ID(),
+				},
+				{
+					Code: 1,
+					Msg: &RetrieveRequest{
+						Ruid:     2,
+						Addr:     forwarded,
+						HopLimit: 3,
+						Timeout:  1000,
+					},
+					Peer: node.ID(),
+				},
+			},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case req := <-requests:
+		if !bytes.Equal(req.Addr, forwarded) {
+			t.Fatalf("got forwarded request for %s, want %s", req.Addr, forwarded)
+		}
+		if req.HopLimit != 3 {
+			t.Errorf("got hop limit %v, want 3", req.HopLimit)
+		}
+		if req.Deadline.Before(start) || req.Deadline.After(time.Now().Add(time.Second)) {
+			t.Errorf("got deadline %v, want within a second", req.Deadline)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for forwarded request")
+	}
+
+	select {
+	case req := <-requests:
+		t.Fatalf("got unexpected forwarded request for %s", req.Addr)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
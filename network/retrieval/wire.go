@@ -20,8 +20,10 @@ import "github.com/ethersphere/swarm/storage"
 
 // RetrieveRequest is the protocol msg for chunk retrieve requests
 type RetrieveRequest struct {
-	Ruid uint
-	Addr storage.Address
+	Ruid     uint
+	Addr     storage.Address
+	HopLimit uint8  // number of times the request may still be forwarded by the receiving peer
+	Timeout  uint64 // milliseconds the requester waits for the delivery, 0 for the receiving peer's default
 }
 
 // ChunkDelivery is the protocol msg for delivering a solicited chunk to a peer
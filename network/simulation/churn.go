@@ -0,0 +1,125 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulation
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// ChurnOptions defines a schedule for Simulation.Churn.
+type ChurnOptions struct {
+	// Interval is the time between two churn rounds.
+	Interval time.Duration
+	// Downtime is the time that stopped nodes are kept down
+	// before they are started again.
+	Downtime time.Duration
+	// Count is the number of nodes that are stopped in a single round.
+	Count int
+	// Protect is a list of nodes that are never stopped.
+	Protect []enode.ID
+}
+
+// ChurnEvent is the type of the channel returned by Simulation.Churn.
+type ChurnEvent struct {
+	// NodeID is the ID of the node that is stopped or started.
+	NodeID enode.ID
+	// Up is true if the node is started and false if it is stopped.
+	Up bool
+	// Error is the error that may have happened while stopping
+	// or starting the node.
+	Error error
+}
+
+// Churn periodically stops random nodes that are up and starts them again after
+// the configured downtime, until the context is done or the simulation is
+// closed. Node buckets are preserved between restarts, so services that keep
+// their state in buckets are restarted with it. Events for every stopped and
+// started node are sent to the returned channel which must be read from. The
+// channel is closed when churning is done and all stopped nodes are started.
+func (s *Simulation) Churn(ctx context.Context, o ChurnOptions) <-chan ChurnEvent {
+	eventC := make(chan ChurnEvent)
+
+	s.shutdownWG.Add(1)
+	go func() {
+		defer s.shutdownWG.Done()
+		defer close(eventC)
+
+		send := func(e ChurnEvent) {
+			select {
+			case eventC <- e:
+			case <-s.done:
+			}
+		}
+
+		ticker := time.NewTicker(o.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			case <-s.done:
+				return
+			}
+
+			ids := s.churnCandidates(o.Count, o.Protect)
+			for _, id := range ids {
+				send(ChurnEvent{NodeID: id, Up: false, Error: s.StopNode(id)})
+			}
+
+			// stopped nodes are always started, even if
+			// the context is done during the downtime
+			select {
+			case <-time.After(o.Downtime):
+			case <-ctx.Done():
+			case <-s.done:
+				return
+			}
+
+			for _, id := range ids {
+				send(ChurnEvent{NodeID: id, Up: true, Error: s.StartNode(id)})
+			}
+		}
+	}()
+
+	return eventC
+}
+
+// churnCandidates returns up to count random nodes that are up
+// and are not in the protect list.
+func (s *Simulation) churnCandidates(count int, protect []enode.ID) (ids []enode.ID) {
+	protected := make(map[enode.ID]struct{}, len(protect))
+	for _, id := range protect {
+		protected[id] = struct{}{}
+	}
+	up := s.UpNodeIDs()
+	for _, i := range rand.Perm(len(up)) {
+		if len(ids) >= count {
+			break
+		}
+		if _, ok := protected[up[i]]; ok {
+			continue
+		}
+		ids = append(ids, up[i])
+	}
+	return ids
+}
@@ -0,0 +1,122 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulation
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/simulations/adapters"
+)
+
+// TestRestartNodeBucket validates that items in node bucket
+// are preserved when the node is restarted.
+func TestRestartNodeBucket(t *testing.T) {
+	var constructions int
+	var mu sync.Mutex
+	sim := NewInProc(map[string]ServiceFunc{
+		"noop": func(_ *adapters.ServiceContext, b *sync.Map) (node.Service, func(), error) {
+			mu.Lock()
+			constructions++
+			mu.Unlock()
+			b.LoadOrStore("state", "persisted")
+			return newNoopService(), nil, nil
+		},
+	})
+	defer sim.Close()
+
+	id, err := sim.AddNode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sim.SetNodeItem(id, "state", "changed")
+
+	if err := sim.RestartNode(id); err != nil {
+		t.Fatal(err)
+	}
+
+	if !sim.Net.GetNode(id).Up() {
+		t.Fatal("node is not up after restart")
+	}
+
+	mu.Lock()
+	if constructions != 2 {
+		t.Errorf("got %v service constructions, want %v", constructions, 2)
+	}
+	mu.Unlock()
+
+	v, ok := sim.NodeItem(id, "state")
+	if !ok {
+		t.Fatal("bucket item not found")
+	}
+	if v != "changed" {
+		t.Errorf("got bucket item %v, want %v", v, "changed")
+	}
+}
+
+// TestChurn validates that Churn stops and starts nodes,
+// never touches protected nodes and leaves all nodes up when done.
+func TestChurn(t *testing.T) {
+	sim := NewInProc(noopServiceFuncMap)
+	defer sim.Close()
+
+	ids, err := sim.AddNodes(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	protected := ids[0]
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	events := sim.Churn(ctx, ChurnOptions{
+		Interval: 50 * time.Millisecond,
+		Downtime: 20 * time.Millisecond,
+		Count:    2,
+		Protect:  []enode.ID{protected},
+	})
+
+	var stopped, started int
+	for e := range events {
+		if e.Error != nil {
+			t.Fatal(e.Error)
+		}
+		if e.NodeID == protected {
+			t.Fatal("protected node churned")
+		}
+		if e.Up {
+			started++
+		} else {
+			stopped++
+		}
+	}
+
+	if stopped == 0 {
+		t.Error("no nodes stopped")
+	}
+	if stopped != started {
+		t.Errorf("got %v stopped and %v started nodes", stopped, started)
+	}
+	if got := len(sim.UpNodeIDs()); got != len(ids) {
+		t.Errorf("got %v up nodes, want %v", got, len(ids))
+	}
+}
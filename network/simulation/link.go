@@ -0,0 +1,367 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulation
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/simulations"
+	"github.com/ethereum/go-ethereum/p2p/simulations/adapters"
+)
+
+// ErrPartitioned is returned by protocols on conditioned links
+// when the two nodes are in different network partitions.
+var ErrPartitioned = errors.New("nodes are partitioned")
+
+// LinkConditions defines the quality of a link between two nodes.
+// Latency and Jitter are applied to every received message and Loss
+// is the probability in range [0,1] that a received message is dropped.
+type LinkConditions struct {
+	Latency time.Duration
+	Jitter  time.Duration
+	Loss    float64
+}
+
+// delay returns the duration that a single message should be delayed.
+func (c LinkConditions) delay() time.Duration {
+	d := c.Latency
+	if c.Jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(c.Jitter)))
+	}
+	return d
+}
+
+// link is an unordered pair of nodes used as a key
+// for link conditions.
+type link struct {
+	one, other enode.ID
+}
+
+func newLink(one, other enode.ID) link {
+	if one.String() > other.String() {
+		one, other = other, one
+	}
+	return link{one: one, other: other}
+}
+
+// Partition splits nodes into provided groups. Existing connections between
+// nodes from different groups are dropped and new ones are disconnected as soon
+// as they are established, until Heal is called. Nodes that are not in any of
+// the groups are not affected. Calling Partition again replaces the previous
+// partitions.
+func (s *Simulation) Partition(groups ...[]enode.ID) (err error) {
+	s.linksMu.Lock()
+	s.partitions = make(map[enode.ID]int)
+	for i, g := range groups {
+		for _, id := range g {
+			s.partitions[id] = i
+		}
+	}
+	s.linksMu.Unlock()
+
+	s.partitionWatch.Do(s.watchPartitions)
+
+	for i, g := range groups {
+		for _, other := range groups[i+1:] {
+			for _, one := range g {
+				for _, o := range other {
+					if err := s.disconnect(one, o); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Heal removes all network partitions. Dropped connections are not
+// reestablished by this method.
+func (s *Simulation) Heal() {
+	s.linksMu.Lock()
+	defer s.linksMu.Unlock()
+
+	s.partitions = nil
+}
+
+// partitioned returns true if two nodes are in different partitions.
+func (s *Simulation) partitioned(one, other enode.ID) bool {
+	s.linksMu.RLock()
+	defer s.linksMu.RUnlock()
+
+	p1, ok := s.partitions[one]
+	if !ok {
+		return false
+	}
+	p2, ok := s.partitions[other]
+	if !ok {
+		return false
+	}
+	return p1 != p2
+}
+
+// disconnect removes peers from both nodes if they are up, so that none of
+// them dials the other one again. Unlike Network.Disconnect, it does not
+// require the connection to be tracked by the network.
+func (s *Simulation) disconnect(one, other enode.ID) (err error) {
+	n := s.Net.GetNode(one)
+	p := s.Net.GetNode(other)
+	if n == nil || p == nil || !n.Up() || !p.Up() {
+		return nil
+	}
+	for _, c := range [][2]*simulations.Node{{n, p}, {p, n}} {
+		client, err := c[0].Client()
+		if err != nil {
+			return err
+		}
+		if err := client.Call(nil, "admin_removePeer", string(c[1].Addr())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watchPartitions starts a goroutine that disconnects nodes
+// that are connected across different partitions.
+func (s *Simulation) watchPartitions() {
+	events := make(chan *simulations.Event)
+	sub := s.Net.Events().Subscribe(events)
+
+	s.shutdownWG.Add(1)
+	go func() {
+		defer s.shutdownWG.Done()
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case e := <-events:
+				if e.Type != simulations.EventTypeConn || e.Control || !e.Conn.Up {
+					continue
+				}
+				if !s.partitioned(e.Conn.One, e.Conn.Other) {
+					continue
+				}
+				// disconnect in a separate goroutine not to block
+				// the network event feed
+				go func(one, other enode.ID) {
+					if err := s.disconnect(one, other); err != nil {
+						log.Debug("simulation partition disconnect", "one", one, "other", other, "err", err)
+					}
+				}(e.Conn.One, e.Conn.Other)
+			case <-sub.Err():
+				return
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+// SetLinkConditions sets conditions on links between every node in the first
+// group and every node in the second group. Conditions are applied on messages
+// received by nodes of in-process simulations. Conditions are changed for
+// established connections too.
+func (s *Simulation) SetLinkConditions(one, other []enode.ID, c LinkConditions) {
+	s.linksMu.Lock()
+	defer s.linksMu.Unlock()
+
+	if s.links == nil {
+		s.links = make(map[link]LinkConditions)
+	}
+	for _, o := range one {
+		for _, p := range other {
+			if o == p {
+				continue
+			}
+			s.links[newLink(o, p)] = c
+		}
+	}
+}
+
+// ResetLinkConditions removes conditions from all links.
+func (s *Simulation) ResetLinkConditions() {
+	s.linksMu.Lock()
+	defer s.linksMu.Unlock()
+
+	for l := range s.links {
+		delete(s.links, l)
+	}
+}
+
+// linkConditions returns conditions set on a link between two nodes.
+func (s *Simulation) linkConditions(one, other enode.ID) (c LinkConditions) {
+	s.linksMu.RLock()
+	defer s.linksMu.RUnlock()
+
+	return s.links[newLink(one, other)]
+}
+
+// conditionedService wraps protocols of a service so that link conditions
+// and partitions are enforced on messages that the node receives. Services
+// are wrapped when they are constructed, before the node p2p server is
+// started and protocols are read from them.
+type conditionedService struct {
+	node.Service
+	sim *Simulation
+	id  enode.ID
+}
+
+// Protocols returns service protocols that read messages
+// through conditionedMsgReadWriter.
+func (c *conditionedService) Protocols() []p2p.Protocol {
+	protocols := c.Service.Protocols()
+	for i := range protocols {
+		run := protocols[i].Run
+		protocols[i].Run = func(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+			return run(p, &conditionedMsgReadWriter{
+				MsgReadWriter: rw,
+				sim:           c.sim,
+				one:           c.id,
+				other:         p.ID(),
+			})
+		}
+	}
+	return protocols
+}
+
+// node.Node requires services on the same node to be of different types,
+// so the wrapper type is chosen by the index of the service on the node.
+type (
+	conditionedService1 struct{ *conditionedService }
+	conditionedService2 struct{ *conditionedService }
+	conditionedService3 struct{ *conditionedService }
+	conditionedService4 struct{ *conditionedService }
+	conditionedService5 struct{ *conditionedService }
+	conditionedService6 struct{ *conditionedService }
+	conditionedService7 struct{ *conditionedService }
+)
+
+// conditionService wraps a service constructed on a node that is started.
+// Services must be constructed in the order of node config services, as
+// node.Node does. Duplicate service types are reported in the same way as
+// node.Node does, as wrapping hides them. The service is returned unchanged
+// if there are more services on a node than wrapper types. It must be
+// called with the simulation mutex locked.
+func (s *Simulation) conditionService(ctx *adapters.ServiceContext, name string, service node.Service) (node.Service, error) {
+	id := ctx.Config.ID
+	index := -1
+	for i, n := range ctx.Config.Services {
+		if n == name {
+			index = i
+			break
+		}
+	}
+	if index <= 0 || s.serviceTypes[id] == nil {
+		s.serviceTypes[id] = make(map[reflect.Type]struct{})
+	}
+	kind := reflect.TypeOf(service)
+	if _, ok := s.serviceTypes[id][kind]; ok {
+		return nil, &node.DuplicateServiceError{Kind: kind}
+	}
+	s.serviceTypes[id][kind] = struct{}{}
+
+	c := &conditionedService{
+		Service: service,
+		sim:     s,
+		id:      id,
+	}
+	switch index {
+	case 0:
+		return c, nil
+	case 1:
+		return &conditionedService1{c}, nil
+	case 2:
+		return &conditionedService2{c}, nil
+	case 3:
+		return &conditionedService3{c}, nil
+	case 4:
+		return &conditionedService4{c}, nil
+	case 5:
+		return &conditionedService5{c}, nil
+	case 6:
+		return &conditionedService6{c}, nil
+	case 7:
+		return &conditionedService7{c}, nil
+	}
+	log.Warn("simulation link conditions not applied", "node", id, "service", name)
+	return service, nil
+}
+
+// unconditionService returns the service wrapped by conditionService.
+func unconditionService(service node.Service) node.Service {
+	switch c := service.(type) {
+	case *conditionedService:
+		return c.Service
+	case *conditionedService1:
+		return c.Service
+	case *conditionedService2:
+		return c.Service
+	case *conditionedService3:
+		return c.Service
+	case *conditionedService4:
+		return c.Service
+	case *conditionedService5:
+		return c.Service
+	case *conditionedService6:
+		return c.Service
+	case *conditionedService7:
+		return c.Service
+	}
+	return service
+}
+
+// conditionedMsgReadWriter applies link conditions and partitions
+// on messages read from the underlying MsgReadWriter.
+type conditionedMsgReadWriter struct {
+	p2p.MsgReadWriter
+	sim        *Simulation
+	one, other enode.ID
+}
+
+// ReadMsg drops or delays messages according to the link conditions and
+// returns ErrPartitioned if nodes are in different partitions.
+func (rw *conditionedMsgReadWriter) ReadMsg() (msg p2p.Msg, err error) {
+	for {
+		msg, err = rw.MsgReadWriter.ReadMsg()
+		if err != nil {
+			return msg, err
+		}
+		if rw.sim.partitioned(rw.one, rw.other) {
+			msg.Discard()
+			return p2p.Msg{}, ErrPartitioned
+		}
+		c := rw.sim.linkConditions(rw.one, rw.other)
+		if c.Loss > 0 && rand.Float64() < c.Loss {
+			msg.Discard()
+			continue
+		}
+		if d := c.delay(); d > 0 {
+			select {
+			case <-time.After(d):
+			case <-rw.sim.done:
+			}
+		}
+		return msg, nil
+	}
+}
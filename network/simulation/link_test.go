@@ -0,0 +1,204 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/simulations/adapters"
+)
+
+// TestPartition validates that connections between partitions are
+// dropped and not allowed until the partitions are healed.
+func TestPartition(t *testing.T) {
+	sim := NewInProc(noopServiceFuncMap)
+	defer sim.Close()
+
+	ids, err := sim.AddNodesAndConnectFull(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	left, right := ids[:2], ids[2:]
+
+	if err := sim.Partition(left, right); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, one := range left {
+		for _, other := range right {
+			waitConn(t, sim, one, other, false)
+		}
+	}
+	waitConn(t, sim, left[0], left[1], true)
+	waitConn(t, sim, right[0], right[1], true)
+
+	// a new connection across partitions must be dropped,
+	// dial from nodes that did not dial before, as p2p server
+	// does not redial the same node for some time
+	addPeer(t, sim, right[0], left[0])
+	// give some time to the connection to be established
+	time.Sleep(100 * time.Millisecond)
+	waitConn(t, sim, right[0], left[0], false)
+
+	sim.Heal()
+
+	addPeer(t, sim, right[1], left[1])
+	waitConn(t, sim, right[1], left[1], true)
+}
+
+// addPeer connects two nodes by calling admin_addPeer directly, bypassing
+// connection tracking in the simulation network.
+func addPeer(t *testing.T, sim *Simulation, one, other enode.ID) {
+	t.Helper()
+
+	client, err := sim.Net.GetNode(one).Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call(nil, "admin_addPeer", string(sim.Net.GetNode(other).Addr())); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitConn waits for the connection between two nodes
+// to be in the required state.
+func waitConn(t *testing.T, sim *Simulation, one, other enode.ID, up bool) {
+	t.Helper()
+
+	srv := sim.Net.GetNode(one).Node.(*adapters.SimNode).Server()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		var connected bool
+		for _, p := range srv.Peers() {
+			if p.ID() == other {
+				connected = true
+				break
+			}
+		}
+		if connected == up {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("connection between %s and %s is not up %v", one, other, up)
+}
+
+// TestConditionedMsgReadWriter validates that messages are delayed
+// and dropped according to link conditions and that partitioned
+// links return an error.
+func TestConditionedMsgReadWriter(t *testing.T) {
+	sim := NewInProc(noopServiceFuncMap)
+	defer sim.Close()
+
+	one, other := enode.ID{1}, enode.ID{2}
+
+	newPipe := func() (w p2p.MsgReadWriter, r p2p.MsgReadWriter) {
+		w, rw := p2p.MsgPipe()
+		return w, &conditionedMsgReadWriter{
+			MsgReadWriter: rw,
+			sim:           sim,
+			one:           one,
+			other:         other,
+		}
+	}
+
+	t.Run("latency", func(t *testing.T) {
+		latency := 50 * time.Millisecond
+		sim.SetLinkConditions([]enode.ID{one}, []enode.ID{other}, LinkConditions{Latency: latency})
+		defer sim.ResetLinkConditions()
+
+		w, r := newPipe()
+		go p2p.Send(w, 0, []byte{})
+
+		start := time.Now()
+		if _, err := r.ReadMsg(); err != nil {
+			t.Fatal(err)
+		}
+		if d := time.Since(start); d < latency {
+			t.Errorf("message delayed %s, want at least %s", d, latency)
+		}
+	})
+
+	t.Run("loss", func(t *testing.T) {
+		sim.SetLinkConditions([]enode.ID{one}, []enode.ID{other}, LinkConditions{Loss: 1})
+
+		w, r := newPipe()
+		go func() {
+			p2p.Send(w, 0, []byte{})
+			// only the message sent after conditions are
+			// reset should be received
+			sim.ResetLinkConditions()
+			p2p.Send(w, 1, []byte{})
+		}()
+
+		msg, err := r.ReadMsg()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.Code != 1 {
+			t.Errorf("got message code %v, want %v", msg.Code, 1)
+		}
+	})
+
+	t.Run("partition", func(t *testing.T) {
+		if err := sim.Partition([]enode.ID{one}, []enode.ID{other}); err != nil {
+			t.Fatal(err)
+		}
+		defer sim.Heal()
+
+		w, r := newPipe()
+		go p2p.Send(w, 0, []byte{})
+
+		if _, err := r.ReadMsg(); err != ErrPartitioned {
+			t.Errorf("got error %v, want %v", err, ErrPartitioned)
+		}
+	})
+}
+
+// TestConditionedService validates that services of in-process nodes
+// are wrapped before nodes are started and that simulation service
+// getters return the wrapped services.
+func TestConditionedService(t *testing.T) {
+	sim := NewInProc(map[string]ServiceFunc{
+		"noop1": noopServiceFunc,
+		"noop2": noopService2Func,
+	})
+	defer sim.Close()
+
+	id, err := sim.AddNode(AddNodeWithService("noop1"), AddNodeWithService("noop2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := sim.Net.GetNode(id).Node.(*adapters.SimNode)
+	if _, ok := n.Service("noop1").(*conditionedService); !ok {
+		t.Errorf("got service noop1 of type %T, want conditioned", n.Service("noop1"))
+	}
+	if _, ok := n.Service("noop2").(*conditionedService1); !ok {
+		t.Errorf("got service noop2 of type %T, want conditioned", n.Service("noop2"))
+	}
+
+	if _, ok := sim.Service("noop1", id).(*noopService); !ok {
+		t.Errorf("got service noop1 of type %T, want %T", sim.Service("noop1", id), &noopService{})
+	}
+	if _, ok := sim.Services("noop2")[id].(*noopService2); !ok {
+		t.Errorf("got service noop2 of type %T, want %T", sim.Services("noop2")[id], &noopService2{})
+	}
+}
@@ -131,7 +131,7 @@ func (s *Simulation) AddNode(opts ...AddNodeOption) (id enode.ID, err error) {
 	s.buckets[node.ID()] = new(sync.Map)
 	s.SetNodeItem(node.ID(), BucketKeyBzzPrivateKey, bzzPrivateKey)
 
-	return node.ID(), s.Net.Start(node.ID())
+	return node.ID(), s.StartNode(node.ID())
 }
 
 // AddNodes creates new nodes with random configurations,
@@ -272,7 +272,7 @@ func (s *Simulation) StartRandomNode() (id enode.ID, err error) {
 	if n == nil {
 		return id, ErrNodeNotFound
 	}
-	return n.ID(), s.Net.Start(n.ID())
+	return n.ID(), s.StartNode(n.ID())
 }
 
 // StartRandomNodes starts random nodes.
@@ -283,7 +283,7 @@ func (s *Simulation) StartRandomNodes(count int) (ids []enode.ID, err error) {
 		if n == nil {
 			return nil, ErrNodeNotFound
 		}
-		err = s.Net.Start(n.ID())
+		err = s.StartNode(n.ID())
 		if err != nil {
 			return nil, err
 		}
@@ -297,6 +297,16 @@ func (s *Simulation) StopNode(id enode.ID) (err error) {
 	return s.Net.Stop(id)
 }
 
+// RestartNode stops and starts a node by NodeID. Items in the node
+// bucket are preserved, so services that keep their state there,
+// like data directories, are constructed with it again.
+func (s *Simulation) RestartNode(id enode.ID) (err error) {
+	if err := s.Net.Stop(id); err != nil {
+		return err
+	}
+	return s.StartNode(id)
+}
+
 // StopRandomNode stops a random node.
 func (s *Simulation) StopRandomNode(protect ...enode.ID) (id enode.ID, err error) {
 	found := false
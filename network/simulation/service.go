@@ -33,7 +33,7 @@ func (s *Simulation) Service(name string, id enode.ID) node.Service {
 	if len(services) == 0 {
 		return nil
 	}
-	return services[name]
+	return unconditionService(services[name])
 }
 
 // RandomService returns a single Service by name on a
@@ -43,7 +43,7 @@ func (s *Simulation) RandomService(name string) node.Service {
 	if n == nil {
 		return nil
 	}
-	return n.Service(name)
+	return unconditionService(n.Service(name))
 }
 
 // Services returns all services with a provided name
@@ -59,7 +59,7 @@ func (s *Simulation) Services(name string) (services map[enode.ID]node.Service)
 		if !ok {
 			continue
 		}
-		services[node.ID()] = simNode.Service(name)
+		services[node.ID()] = unconditionService(simNode.Service(name))
 	}
 	return services
 }
@@ -22,6 +22,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"reflect"
 	"sync"
 	"time"
 
@@ -53,6 +54,7 @@ type Simulation struct {
 	serviceNames      []string
 	cleanupFuncs      []func()
 	buckets           map[enode.ID]*sync.Map
+	serviceTypes      map[enode.ID]map[reflect.Type]struct{}
 	shutdownWG        sync.WaitGroup
 	done              chan struct{}
 	mu                sync.RWMutex
@@ -63,6 +65,12 @@ type Simulation struct {
 	httpSrv *http.Server        //attach a HTTP server via SimulationOptions
 	handler *simulations.Server //HTTP handler for the server
 	runC    chan struct{}       //channel where frontend signals it is ready
+
+	// network partitions and link conditions between nodes
+	linksMu        sync.RWMutex
+	partitions     map[enode.ID]int
+	links          map[link]LinkConditions
+	partitionWatch sync.Once
 }
 
 // ServiceFunc is used in New to declare new service constructor.
@@ -83,6 +91,7 @@ type ServiceFunc func(ctx *adapters.ServiceContext, bucket *sync.Map) (s node.Se
 func NewInProc(services map[string]ServiceFunc) (s *Simulation) {
 	s = &Simulation{
 		buckets:           make(map[enode.ID]*sync.Map),
+		serviceTypes:      make(map[enode.ID]map[reflect.Type]struct{}),
 		done:              make(chan struct{}),
 		neighbourhoodSize: network.NewKadParams().NeighbourhoodSize,
 		typ:               SimulationTypeInproc,
@@ -179,6 +188,9 @@ func (s *Simulation) toAdapterServices(services map[string]ServiceFunc) map[stri
 				s.cleanupFuncs = append(s.cleanupFuncs, cleanup)
 			}
 			s.buckets[ctx.Config.ID] = b
+			if s.typ == SimulationTypeInproc {
+				return s.conditionService(ctx, name, service)
+			}
 			return service, nil
 		}
 	}
@@ -137,14 +137,16 @@ func (s *syncProvider) NeedData(ctx context.Context, addrs ...chunk.Address) ([]
 				continue
 			}
 
-			go func() {
+			go func(ref chunk.Address) {
+				defer s.netStore.ReleaseFetcher(ref, fi)
+
 				select {
 				case <-fi.Delivered:
 					metrics.GetOrRegisterResettingTimer(fmt.Sprintf("fetcher/%s/syncer", fi.CreatedBy), nil).UpdateSince(start)
 				case <-time.After(timeouts.SyncerClientWaitTimeout):
 					metrics.GetOrRegisterCounter("fetcher/syncer/timeout", nil).Inc(1)
 				}
-			}()
+			}(check[i])
 		} else {
 			// if we have it - we dont want it
 			wants[indexes[i]] = false
@@ -48,11 +48,19 @@ func isPssPeer(bp *network.BzzPeer) bool {
 	return bp.HasCap(protocolName)
 }
 
+func isPssStorerPeer(bp *network.BzzPeer) bool {
+	return isPssPeer(bp) && bp.IsStorer()
+}
+
 // IsClosestTo returns true is self is the closest known node to addr
 // as uniquely defined by the MSB XOR distance
-// among pss capable peers
+// among pss capable peers that store chunks.
+// Nodes that do not store chunks are never the closest.
 func (p *PubSub) IsClosestTo(addr []byte) bool {
-	return p.pss.IsClosestTo(addr, isPssPeer)
+	if !p.pss.IsStorer() {
+		return false
+	}
+	return p.pss.IsClosestTo(addr, isPssStorerPeer)
 }
 
 // Register registers a handler
@@ -23,11 +23,13 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethersphere/swarm/chunk"
 )
 
 const (
-	pssChunkTopic   = "PUSHSYNC_CHUNKS"   // pss topic for chunks
-	pssReceiptTopic = "PUSHSYNC_RECEIPTS" // pss topic for statement of custody receipts
+	pssChunkTopic        = "PUSHSYNC_CHUNKS"   // pss topic for chunks
+	pssStampedChunkTopic = "PUSHSYNC_CHUNKS_2" // pss topic for chunks with postage stamps
+	pssReceiptTopic      = "PUSHSYNC_RECEIPTS" // pss topic for statement of custody receipts
 )
 
 // PubSub is a Postal Service interface needed to send/receive chunks and receipts for push syncing
@@ -44,6 +46,48 @@ type chunkMsg struct {
 	Data   []byte // chunk data
 	Origin []byte // originator - need this for sending receipt back to origin
 	Nonce  []byte // nonce to make multiple instances of send immune to deduplication cache
+	Stamp  []byte `rlp:"-"` // postage stamp, only sent in stampedChunkMsg
+}
+
+// stampedChunkMsg is the version of the chunk message with the postage stamp
+// of the chunk. Nodes that do not know about stamps can not decode it, so it is
+// sent on pssStampedChunkTopic, which they do not handle. Chunks without stamps
+// are still sent as chunkMsg on pssChunkTopic.
+type stampedChunkMsg struct {
+	Addr   []byte
+	Data   []byte
+	Origin []byte
+	Nonce  []byte
+	Stamp  []byte
+}
+
+// newChunkMsg returns a chunk message for the chunk with the given
+// originator and the postage stamp of the chunk, if it is stamped.
+func newChunkMsg(origin []byte, ch chunk.Chunk) *chunkMsg {
+	return &chunkMsg{
+		Origin: origin,
+		Addr:   ch.Address(),
+		Data:   ch.Data(),
+		Nonce:  newNonce(),
+		Stamp:  ch.Stamp(),
+	}
+}
+
+// encodeChunkMsg encodes the chunk message and returns the topic
+// it must be sent on, which depends on whether the chunk is stamped.
+func encodeChunkMsg(m *chunkMsg) (topic string, msg []byte, err error) {
+	if len(m.Stamp) == 0 {
+		msg, err = rlp.EncodeToBytes(m)
+		return pssChunkTopic, msg, err
+	}
+	msg, err = rlp.EncodeToBytes(&stampedChunkMsg{
+		Addr:   m.Addr,
+		Data:   m.Data,
+		Origin: m.Origin,
+		Nonce:  m.Nonce,
+		Stamp:  m.Stamp,
+	})
+	return pssStampedChunkTopic, msg, err
 }
 
 // receiptMsg is a statement of custody response to receiving a push-synced chunk
@@ -63,6 +107,21 @@ func decodeChunkMsg(msg []byte) (*chunkMsg, error) {
 	return &chmsg, nil
 }
 
+func decodeStampedChunkMsg(msg []byte) (*chunkMsg, error) {
+	var smsg stampedChunkMsg
+	err := rlp.DecodeBytes(msg, &smsg)
+	if err != nil {
+		return nil, err
+	}
+	return &chunkMsg{
+		Addr:   smsg.Addr,
+		Data:   smsg.Data,
+		Origin: smsg.Origin,
+		Nonce:  smsg.Nonce,
+		Stamp:  smsg.Stamp,
+	}, nil
+}
+
 func decodeReceiptMsg(msg []byte) (*receiptMsg, error) {
 	var rmsg receiptMsg
 	err := rlp.DecodeBytes(msg, &rmsg)
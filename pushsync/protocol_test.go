@@ -17,6 +17,7 @@
 package pushsync
 
 import (
+	"bytes"
 	"context"
 	"encoding/binary"
 	"sync"
@@ -24,6 +25,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethersphere/swarm/chunk"
 	"github.com/ethersphere/swarm/log"
 )
@@ -111,3 +113,116 @@ func (t *testStore) Put(_ context.Context, _ chunk.ModePut, chs ...chunk.Chunk)
 	}
 	return exists, nil
 }
+
+// TestStorerStamp tests that postage stamps sent with chunks
+// are stored with them and that invalid stamps are rejected
+func TestStorerStamp(t *testing.T) {
+	store := &stampStore{}
+	isClosestTo := func([]byte) bool { return false }
+	lb := newLoopBack()
+	s := NewStorer(store, &testPubSub{lb, isClosestTo})
+	defer s.Close()
+
+	stamp := bytes.Repeat([]byte{1}, chunk.StampSize)
+	for _, tc := range []struct {
+		name    string
+		stamp   []byte
+		wantErr error
+	}{
+		{name: "unstamped"},
+		{name: "stamped", stamp: stamp},
+		{name: "invalid", stamp: stamp[1:], wantErr: chunk.ErrInvalidStamp},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store.chunk = nil
+			topic, msg, err := encodeChunkMsg(newChunkMsg(nil, chunk.NewChunk(make([]byte, 32), []byte("data")).WithStamp(tc.stamp)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = lb.Send(nil, topic, msg)
+			if err != tc.wantErr {
+				t.Fatalf("got error %v, want %v", err, tc.wantErr)
+			}
+			if tc.wantErr != nil {
+				if store.chunk != nil {
+					t.Fatal("chunk with invalid stamp stored")
+				}
+				return
+			}
+			if store.chunk == nil {
+				t.Fatal("chunk not stored")
+			}
+			if got := store.chunk.Stamp(); !bytes.Equal(got, tc.stamp) {
+				t.Errorf("got stamp %x, want %x", got, tc.stamp)
+			}
+		})
+	}
+}
+
+// TestChunkMsgCompatibility tests that chunk messages without postage stamps
+// are encoded as before stamps were added and sent on the same topic, while
+// messages with stamps are sent on their own topic as nodes that do not know
+// about stamps can not decode them.
+func TestChunkMsgCompatibility(t *testing.T) {
+	// chunk message type before postage stamps were added
+	type legacyChunkMsg struct {
+		Addr   []byte
+		Data   []byte
+		Origin []byte
+		Nonce  []byte
+	}
+
+	ch := chunk.NewChunk(make([]byte, 32), []byte("data"))
+	msg := newChunkMsg([]byte("origin"), ch)
+	topic, got, err := encodeChunkMsg(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topic != pssChunkTopic {
+		t.Errorf("got topic %q, want %q", topic, pssChunkTopic)
+	}
+	want, err := rlp.EncodeToBytes(&legacyChunkMsg{
+		Addr:   msg.Addr,
+		Data:   msg.Data,
+		Origin: msg.Origin,
+		Nonce:  msg.Nonce,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("got encoded message %x, want %x", got, want)
+	}
+
+	stamp := bytes.Repeat([]byte{1}, chunk.StampSize)
+	topic, encoded, err := encodeChunkMsg(newChunkMsg([]byte("origin"), ch.WithStamp(stamp)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topic != pssStampedChunkTopic {
+		t.Errorf("got topic %q, want %q", topic, pssStampedChunkTopic)
+	}
+	if err := rlp.DecodeBytes(encoded, new(legacyChunkMsg)); err == nil {
+		t.Error("stamped chunk message decoded as legacy chunk message")
+	}
+	decoded, err := decodeStampedChunkMsg(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.Stamp, stamp) {
+		t.Errorf("got stamp %x, want %x", decoded.Stamp, stamp)
+	}
+	if !bytes.Equal(decoded.Data, ch.Data()) {
+		t.Errorf("got data %x, want %x", decoded.Data, ch.Data())
+	}
+}
+
+// stampStore keeps the last stored chunk
+type stampStore struct {
+	chunk chunk.Chunk
+}
+
+func (s *stampStore) Put(_ context.Context, _ chunk.ModePut, chs ...chunk.Chunk) ([]bool, error) {
+	s.chunk = chs[len(chs)-1]
+	return make([]bool, len(chs)), nil
+}
@@ -25,7 +25,6 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/p2p"
-	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethersphere/swarm/chunk"
 	"github.com/ethersphere/swarm/spancontext"
 	"github.com/ethersphere/swarm/storage"
@@ -300,13 +299,7 @@ func (p *Pusher) pushReceipt(addr []byte) {
 func (p *Pusher) sendChunkMsg(ch chunk.Chunk) error {
 	rlpTimer := time.Now()
 
-	cmsg := &chunkMsg{
-		Origin: p.ps.BaseAddr(),
-		Addr:   ch.Address(),
-		Data:   ch.Data(),
-		Nonce:  newNonce(),
-	}
-	msg, err := rlp.EncodeToBytes(cmsg)
+	topic, msg, err := encodeChunkMsg(newChunkMsg(p.ps.BaseAddr(), ch))
 	if err != nil {
 		return err
 	}
@@ -315,7 +308,7 @@ func (p *Pusher) sendChunkMsg(ch chunk.Chunk) error {
 	metrics.GetOrRegisterResettingTimer("pusher/send/chunk/rlp", nil).UpdateSince(rlpTimer)
 
 	defer metrics.GetOrRegisterResettingTimer("pusher/send/chunk/pss", nil).UpdateSince(time.Now())
-	return p.ps.Send(ch.Address()[:], pssChunkTopic, msg)
+	return p.ps.Send(ch.Address()[:], topic, msg)
 }
 
 // needToSync checks if a chunk needs to be push-synced:
@@ -56,9 +56,16 @@ func NewStorer(store Store, ps PubSub) *Storer {
 		ps:     ps,
 		logger: log.New("self", label(ps.BaseAddr())),
 	}
-	s.deregister = ps.Register(pssChunkTopic, true, func(msg []byte, _ *p2p.Peer) error {
+	deregisterChunks := ps.Register(pssChunkTopic, true, func(msg []byte, _ *p2p.Peer) error {
 		return s.handleChunkMsg(msg)
 	})
+	deregisterStampedChunks := ps.Register(pssStampedChunkTopic, true, func(msg []byte, _ *p2p.Peer) error {
+		return s.handleStampedChunkMsg(msg)
+	})
+	s.deregister = func() {
+		deregisterChunks()
+		deregisterStampedChunks()
+	}
 	return s
 }
 
@@ -75,7 +82,22 @@ func (s *Storer) handleChunkMsg(msg []byte) error {
 	if err != nil {
 		return err
 	}
+	return s.receiveChunkMsg(chmsg)
+}
+
+// handleStampedChunkMsg is called by the pss dispatcher on pssStampedChunkTopic msgs
+// - deserialises stampedChunkMsg and
+// - calls storer.processChunkMsg function
+func (s *Storer) handleStampedChunkMsg(msg []byte) error {
+	chmsg, err := decodeStampedChunkMsg(msg)
+	if err != nil {
+		return err
+	}
+	return s.receiveChunkMsg(chmsg)
+}
 
+// receiveChunkMsg traces the received chunk message and processes it
+func (s *Storer) receiveChunkMsg(chmsg *chunkMsg) error {
 	ctx, osp := spancontext.StartSpan(context.Background(), "handle.chunk.msg")
 	defer osp.Finish()
 	hexaddr := hex.EncodeToString(chmsg.Addr)
@@ -86,14 +108,22 @@ func (s *Storer) handleChunkMsg(msg []byte) error {
 	return s.processChunkMsg(ctx, chmsg)
 }
 
-// processChunkMsg processes a chunk received via pss pssChunkTopic
+// processChunkMsg processes a chunk received via pss pssChunkTopic or pssStampedChunkTopic
 // these chunk messages are sent to their address as destination
 // using neighbourhood addressing. Therefore nodes only handle
 // chunks that fall within their area of responsibility.
 // Upon receiving the chunk is saved and a statement of custody
 // receipt message is sent as a response to the originator.
+// Postage stamps are only checked for their size and are not validated
+// against postage batches, so they are stored, but must not be trusted.
 func (s *Storer) processChunkMsg(ctx context.Context, chmsg *chunkMsg) error {
 	ch := storage.NewChunk(chmsg.Addr, chmsg.Data)
+	if len(chmsg.Stamp) > 0 {
+		if len(chmsg.Stamp) != chunk.StampSize {
+			return chunk.ErrInvalidStamp
+		}
+		ch = ch.WithStamp(chmsg.Stamp)
+	}
 	if _, err := s.store.Put(ctx, chunk.ModePutSync, ch); err != nil {
 		return err
 	}
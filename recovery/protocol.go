@@ -0,0 +1,124 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package recovery implements the recovery of chunks that can not be retrieved
+// from the network.
+//
+// When retrieval of a chunk fails, the node broadcasts a recovery request over
+// pss to the neighbourhood of the chunk and to optionally configured targets,
+// the address prefixes of repair nodes. Repair nodes, typically run by
+// publishers that keep their content stored locally, respond by pushing the
+// chunk to the originator of the request. The recovered chunk is stored as an
+// upload, so that it is synced again to its neighbourhood.
+package recovery
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	pssRequestTopic = "RECOVERY_REQUESTS" // pss topic for chunk recovery requests
+	pssChunkTopic   = "RECOVERY_CHUNKS"   // pss topic for chunks pushed by repair nodes
+)
+
+// PubSub is a Postal Service interface needed to send/receive recovery requests and chunks
+type PubSub interface {
+	Register(topic string, prox bool, handler func(msg []byte, p *p2p.Peer) error) func()
+	Send(to []byte, topic string, msg []byte) error
+	BaseAddr() []byte
+}
+
+// requestMsg is the message construct to request a chunk from repair nodes
+type requestMsg struct {
+	Addr      []byte // chunk address
+	Origin    []byte // originator - the chunk is pushed back to it
+	Nonce     []byte // nonce to make multiple instances of send immune to deduplication cache
+	Timestamp uint64 // unix time in seconds when the request was sent, so that it can not be replayed later
+	Signature []byte // signature of the originator, so that chunks are not pushed to other nodes
+}
+
+// sign sets the request signature with the originator private key.
+func (m *requestMsg) sign(key *ecdsa.PrivateKey) (err error) {
+	m.Signature, err = crypto.Sign(m.hash(), key)
+	return err
+}
+
+// verify returns true if the request is signed by the originator,
+// the node with the overlay address derived from the signer public key.
+func (m *requestMsg) verify() bool {
+	pub, err := crypto.SigToPub(m.hash(), m.Signature)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(crypto.Keccak256(crypto.FromECDSAPub(pub)), m.Origin)
+}
+
+// hash returns the hash of the signed request fields.
+func (m *requestMsg) hash() []byte {
+	timestamp := make([]byte, 8)
+	binary.BigEndian.PutUint64(timestamp, m.Timestamp)
+	return crypto.Keccak256(m.Addr, m.Origin, m.Nonce, timestamp)
+}
+
+// chunkMsg is the repair response pushing the chunk to the originator of the request
+type chunkMsg struct {
+	Addr  []byte // chunk address
+	Data  []byte // chunk data
+	Nonce []byte // nonce to make multiple instances of send immune to deduplication cache
+}
+
+func decodeRequestMsg(msg []byte) (*requestMsg, error) {
+	var rmsg requestMsg
+	err := rlp.DecodeBytes(msg, &rmsg)
+	if err != nil {
+		return nil, err
+	}
+	return &rmsg, nil
+}
+
+func decodeChunkMsg(msg []byte) (*chunkMsg, error) {
+	var chmsg chunkMsg
+	err := rlp.DecodeBytes(msg, &chmsg)
+	if err != nil {
+		return nil, err
+	}
+	return &chmsg, nil
+}
+
+// newNonce creates a random nonce;
+// it is important otherwise resending a request is deduplicated by pss
+func newNonce() []byte {
+	buf := make([]byte, 32)
+	io.ReadFull(rand.Reader, buf)
+	return buf
+}
+
+func label(b []byte) string {
+	l := len(b)
+	if l > 8 {
+		l = 8
+	}
+	return hexutil.Encode(b[:l])
+}
@@ -0,0 +1,146 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package recovery
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethersphere/swarm/chunk"
+)
+
+// pendingTTL is the time a repair response is accepted after a request is sent.
+var pendingTTL = time.Minute
+
+// Store is the storage interface to save recovered chunks
+// NetStore implements this interface
+type Store interface {
+	Put(context.Context, chunk.ModePut, ...chunk.Chunk) ([]bool, error)
+}
+
+// Recovery sends recovery requests for chunks that can not be retrieved
+// and stores the chunks pushed by repair nodes in response.
+// Only chunks that were requested are accepted.
+type Recovery struct {
+	store      Store                // store to put recovered chunks in
+	ps         PubSub               // pubsub interface to send requests and receive chunks
+	targets    [][]byte             // address prefixes of repair nodes
+	key        *ecdsa.PrivateKey    // key to sign requests with, its overlay address is the originator
+	pending    map[string]time.Time // requested chunk addresses and response deadlines
+	pendingMu  sync.Mutex
+	deregister func()     // deregister the registered handler when Recovery is closed
+	logger     log.Logger // custom logger
+}
+
+// New constructs a Recovery
+// Recovery requests are sent to the neighbourhood of the chunk and to all
+// targets, address prefixes of repair nodes. Requests are signed with the key
+// of the node, that must correspond to the pubsub base address.
+func New(store Store, ps PubSub, targets [][]byte, key *ecdsa.PrivateKey) *Recovery {
+	r := &Recovery{
+		store:   store,
+		ps:      ps,
+		targets: targets,
+		key:     key,
+		pending: make(map[string]time.Time),
+		logger:  log.New("self", label(ps.BaseAddr())),
+	}
+	r.deregister = ps.Register(pssChunkTopic, false, func(msg []byte, _ *p2p.Peer) error {
+		return r.handleChunkMsg(msg)
+	})
+	return r
+}
+
+// Close needs to be called to deregister the handler
+func (r *Recovery) Close() {
+	r.deregister()
+}
+
+// Recover sends the recovery request for the chunk with the address.
+// It implements the storage.RecoverFunc.
+func (r *Recovery) Recover(ctx context.Context, addr chunk.Address) error {
+	metrics.GetOrRegisterCounter("recovery/request", nil).Inc(1)
+
+	rmsg := &requestMsg{
+		Addr:      addr,
+		Origin:    r.ps.BaseAddr(),
+		Nonce:     newNonce(),
+		Timestamp: uint64(time.Now().Unix()),
+	}
+	if err := rmsg.sign(r.key); err != nil {
+		return err
+	}
+	msg, err := rlp.EncodeToBytes(rmsg)
+	if err != nil {
+		return err
+	}
+
+	r.pendingMu.Lock()
+	r.pending[addr.String()] = time.Now().Add(pendingTTL)
+	r.pendingMu.Unlock()
+	time.AfterFunc(pendingTTL, func() {
+		r.pendingMu.Lock()
+		defer r.pendingMu.Unlock()
+		if deadline, ok := r.pending[addr.String()]; ok && !time.Now().Before(deadline) {
+			delete(r.pending, addr.String())
+		}
+	})
+
+	r.logger.Trace("recovery request", "ref", addr, "targets", len(r.targets))
+	if err := r.ps.Send(addr, pssRequestTopic, msg); err != nil {
+		return err
+	}
+	for _, target := range r.targets {
+		if err := r.ps.Send(target, pssRequestTopic, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleChunkMsg is called by the pss dispatcher on pssChunkTopic msgs
+// it stores the pushed chunk if it was requested
+func (r *Recovery) handleChunkMsg(msg []byte) error {
+	chmsg, err := decodeChunkMsg(msg)
+	if err != nil {
+		return err
+	}
+	addr := chunk.Address(chmsg.Addr)
+
+	r.pendingMu.Lock()
+	_, ok := r.pending[addr.String()]
+	delete(r.pending, addr.String())
+	r.pendingMu.Unlock()
+	if !ok {
+		metrics.GetOrRegisterCounter("recovery/chunk/unsolicited", nil).Inc(1)
+		r.logger.Trace("unsolicited repair response", "ref", addr)
+		return nil
+	}
+
+	metrics.GetOrRegisterCounter("recovery/chunk", nil).Inc(1)
+	r.logger.Trace("repair response", "ref", addr)
+	// the chunk is stored as an upload to be synced to its neighbourhood again,
+	// the store validates it
+	_, err = r.store.Put(context.Background(), chunk.ModePutUpload, chunk.NewChunk(addr, chmsg.Data))
+	return err
+}
@@ -0,0 +1,239 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package recovery
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/storage"
+)
+
+// TestRecovery tests that a chunk requested by Recover is pushed by
+// the repair node that stores it and that it is stored as an upload.
+func TestRecovery(t *testing.T) {
+	key, ps := newTestKeyLoopBack(t)
+
+	repairStore := &testStore{}
+	ch := storage.GenerateRandomChunk(chunk.DefaultSize)
+	if _, err := repairStore.Put(context.Background(), chunk.ModePutUpload, ch); err != nil {
+		t.Fatal(err)
+	}
+	repairer := NewRepairer(repairStore, ps)
+	defer repairer.Close()
+
+	store := &testStore{}
+	target := []byte{0xaa}
+	r := New(store, ps, [][]byte{target}, key)
+	defer r.Close()
+
+	if err := r.Recover(context.Background(), ch.Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the request is sent to the chunk and to the target
+	// but only the first response is accepted
+	if got := ps.sentTo(pssRequestTopic); len(got) != 2 || !bytes.Equal(got[0], ch.Address()) || !bytes.Equal(got[1], target) {
+		t.Errorf("got requests sent to %x", got)
+	}
+	if len(store.chunks) != 1 {
+		t.Fatalf("got %v stored chunks, want 1", len(store.chunks))
+	}
+	if !bytes.Equal(store.chunks[0].Address(), ch.Address()) || !bytes.Equal(store.chunks[0].Data(), ch.Data()) {
+		t.Error("got wrong stored chunk")
+	}
+	if store.modes[0] != chunk.ModePutUpload {
+		t.Errorf("got put mode %v, want %v", store.modes[0], chunk.ModePutUpload)
+	}
+
+	// chunks missing on the repair node are not pushed
+	missing := storage.GenerateRandomChunk(chunk.DefaultSize)
+	if err := r.Recover(context.Background(), missing.Address()); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.chunks) != 1 {
+		t.Fatalf("got %v stored chunks, want 1", len(store.chunks))
+	}
+}
+
+// TestRecoveryUnsolicited tests that chunks which were not
+// requested are not stored.
+func TestRecoveryUnsolicited(t *testing.T) {
+	key, ps := newTestKeyLoopBack(t)
+	store := &testStore{}
+	r := New(store, ps, nil, key)
+	defer r.Close()
+
+	ch := storage.GenerateRandomChunk(chunk.DefaultSize)
+	msg, err := rlp.EncodeToBytes(&chunkMsg{
+		Addr:  ch.Address(),
+		Data:  ch.Data(),
+		Nonce: newNonce(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ps.Send(nil, pssChunkTopic, msg); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.chunks) != 0 {
+		t.Fatalf("got %v stored chunks, want none", len(store.chunks))
+	}
+}
+
+// TestRepairerInvalidOrigin tests that chunks are not pushed
+// for requests that are not signed by their originator
+// or that are too old or too far in the future.
+func TestRepairerInvalidOrigin(t *testing.T) {
+	key, ps := newTestKeyLoopBack(t)
+
+	repairStore := &testStore{}
+	ch := storage.GenerateRandomChunk(chunk.DefaultSize)
+	if _, err := repairStore.Put(context.Background(), chunk.ModePutUpload, ch); err != nil {
+		t.Fatal(err)
+	}
+	repairer := NewRepairer(repairStore, ps)
+	defer repairer.Close()
+
+	for _, tc := range []struct {
+		name   string
+		origin []byte
+		sign   bool
+		age    time.Duration
+	}{
+		{name: "unsigned", origin: ps.BaseAddr(), sign: false},
+		{name: "other origin", origin: bytes.Repeat([]byte{0xaa}, 32), sign: true},
+		{name: "expired", origin: ps.BaseAddr(), sign: true, age: 2 * requestMaxAge},
+		{name: "future", origin: ps.BaseAddr(), sign: true, age: -2 * requestMaxAge},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rmsg := &requestMsg{
+				Addr:      ch.Address(),
+				Origin:    tc.origin,
+				Nonce:     newNonce(),
+				Timestamp: uint64(time.Now().Add(-tc.age).Unix()),
+			}
+			if tc.sign {
+				if err := rmsg.sign(key); err != nil {
+					t.Fatal(err)
+				}
+			}
+			msg, err := rlp.EncodeToBytes(rmsg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ps.Send(ch.Address(), pssRequestTopic, msg); err != nil {
+				t.Fatal(err)
+			}
+			if got := ps.sentTo(pssChunkTopic); len(got) != 0 {
+				t.Errorf("got chunks pushed to %x", got)
+			}
+		})
+	}
+}
+
+// testStore records the chunks put in it and returns them on Get
+type testStore struct {
+	mu     sync.Mutex
+	chunks []chunk.Chunk
+	modes  []chunk.ModePut
+}
+
+func (s *testStore) Put(_ context.Context, mode chunk.ModePut, chs ...chunk.Chunk) ([]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ch := range chs {
+		s.chunks = append(s.chunks, ch)
+		s.modes = append(s.modes, mode)
+	}
+	return make([]bool, len(chs)), nil
+}
+
+func (s *testStore) Get(_ context.Context, _ chunk.ModeGet, addr chunk.Address) (chunk.Chunk, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ch := range s.chunks {
+		if bytes.Equal(ch.Address(), addr) {
+			return ch, nil
+		}
+	}
+	return nil, chunk.ErrChunkNotFound
+}
+
+// loopBack implements PubSub as a central subscription engine,
+// ie a msg sent is received by all handlers registered for the topic
+type loopBack struct {
+	baseAddr []byte
+	handlers map[string][]func(msg []byte, p *p2p.Peer) error
+	sent     map[string][][]byte
+}
+
+func newLoopBack(baseAddr []byte) *loopBack {
+	return &loopBack{
+		baseAddr: baseAddr,
+		handlers: make(map[string][]func(msg []byte, p *p2p.Peer) error),
+		sent:     make(map[string][][]byte),
+	}
+}
+
+// newTestKeyLoopBack returns a new private key and
+// the loopBack with the base address derived from it.
+func newTestKeyLoopBack(t *testing.T) (*ecdsa.PrivateKey, *loopBack) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, newLoopBack(crypto.Keccak256(crypto.FromECDSAPub(&key.PublicKey)))
+}
+
+// Register subscribes to a topic with a handler
+func (lb *loopBack) Register(topic string, _ bool, handler func(msg []byte, p *p2p.Peer) error) func() {
+	lb.handlers[topic] = append(lb.handlers[topic], handler)
+	return func() {}
+}
+
+// Send publishes a msg with a topic and directly calls registered handlers with
+// that topic
+func (lb *loopBack) Send(to []byte, topic string, msg []byte) error {
+	lb.sent[topic] = append(lb.sent[topic], to)
+	p := p2p.NewPeer(enode.ID{}, "", nil)
+	for _, handler := range lb.handlers[topic] {
+		if err := handler(msg, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BaseAddr needed to implement PubSub interface
+func (lb *loopBack) BaseAddr() []byte {
+	return lb.baseAddr
+}
+
+func (lb *loopBack) sentTo(topic string) [][]byte {
+	return lb.sent[topic]
+}
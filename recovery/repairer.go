@@ -0,0 +1,111 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package recovery
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethersphere/swarm/chunk"
+)
+
+// requestMaxAge is the maximal difference between the request timestamp
+// and the local time for which requests are accepted, so that captured
+// requests can not be replayed to push chunks to the originator again.
+var requestMaxAge = time.Minute
+
+// Getter is the storage interface to look up chunks to repair
+// localstore implements this interface
+type Getter interface {
+	Get(context.Context, chunk.ModeGet, chunk.Address) (chunk.Chunk, error)
+}
+
+// Repairer is the object used by repair nodes to respond to recovery requests
+// with chunks from their local store.
+type Repairer struct {
+	store      Getter     // local store to retrieve chunks from
+	ps         PubSub     // pubsub interface to receive requests and push chunks
+	deregister func()     // deregister the registered handler when Repairer is closed
+	logger     log.Logger // custom logger
+}
+
+// NewRepairer constructs a Repairer
+// Requests for chunks that are not in the store, requests that are not
+// signed by their originator and requests older than requestMaxAge
+// are ignored.
+func NewRepairer(store Getter, ps PubSub) *Repairer {
+	r := &Repairer{
+		store:  store,
+		ps:     ps,
+		logger: log.New("self", label(ps.BaseAddr())),
+	}
+	r.deregister = ps.Register(pssRequestTopic, true, func(msg []byte, _ *p2p.Peer) error {
+		return r.handleRequestMsg(msg)
+	})
+	return r
+}
+
+// Close needs to be called to deregister the handler
+func (r *Repairer) Close() {
+	r.deregister()
+}
+
+// handleRequestMsg is called by the pss dispatcher on pssRequestTopic msgs
+// it pushes the requested chunk to the originator if it is stored locally
+func (r *Repairer) handleRequestMsg(msg []byte) error {
+	rmsg, err := decodeRequestMsg(msg)
+	if err != nil {
+		return err
+	}
+	addr := chunk.Address(rmsg.Addr)
+
+	// only the originator can request chunks to be pushed to it,
+	// otherwise repair nodes could be used to flood other nodes
+	if !rmsg.verify() {
+		metrics.GetOrRegisterCounter("recovery/repair/invalid", nil).Inc(1)
+		r.logger.Trace("recovery request with invalid signature", "ref", addr, "origin", label(rmsg.Origin))
+		return nil
+	}
+	if age := time.Since(time.Unix(int64(rmsg.Timestamp), 0)); age > requestMaxAge || age < -requestMaxAge {
+		metrics.GetOrRegisterCounter("recovery/repair/expired", nil).Inc(1)
+		r.logger.Trace("recovery request expired", "ref", addr, "origin", label(rmsg.Origin), "age", age)
+		return nil
+	}
+
+	ch, err := r.store.Get(context.Background(), chunk.ModeGetRequest, addr)
+	if err != nil {
+		r.logger.Trace("recovery request for missing chunk", "ref", addr, "origin", label(rmsg.Origin))
+		return nil
+	}
+
+	metrics.GetOrRegisterCounter("recovery/repair", nil).Inc(1)
+	chmsg := &chunkMsg{
+		Addr:  ch.Address(),
+		Data:  ch.Data(),
+		Nonce: newNonce(),
+	}
+	msg, err = rlp.EncodeToBytes(chmsg)
+	if err != nil {
+		return err
+	}
+	r.logger.Trace("repair response", "ref", addr, "to", label(rmsg.Origin))
+	return r.ps.Send(rmsg.Origin, pssChunkTopic, msg)
+}
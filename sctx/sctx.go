@@ -6,6 +6,8 @@ type (
 	HTTPRequestIDKey struct{}
 	requestHostKey   struct{}
 	tagKey           struct{}
+	hopLimitKey      struct{}
+	stampKey         struct{}
 )
 
 // SetHost sets the http request host in the context
@@ -35,3 +37,31 @@ func GetTag(ctx context.Context) uint32 {
 	}
 	return 0
 }
+
+// SetHopLimit sets the max number of hops chunk retrieve requests travel in the context
+func SetHopLimit(ctx context.Context, hops uint8) context.Context {
+	return context.WithValue(ctx, hopLimitKey{}, hops)
+}
+
+// GetHopLimit gets the max number of hops chunk retrieve requests travel from the context
+func GetHopLimit(ctx context.Context) uint8 {
+	v, ok := ctx.Value(hopLimitKey{}).(uint8)
+	if ok {
+		return v
+	}
+	return 0
+}
+
+// SetStamp sets the postage stamp attached to uploaded chunks in the context
+func SetStamp(ctx context.Context, stamp []byte) context.Context {
+	return context.WithValue(ctx, stampKey{}, stamp)
+}
+
+// GetStamp gets the postage stamp attached to uploaded chunks from the context
+func GetStamp(ctx context.Context) []byte {
+	v, ok := ctx.Value(stampKey{}).([]byte)
+	if ok {
+		return v
+	}
+	return nil
+}
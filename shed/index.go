@@ -43,6 +43,7 @@ type Item struct {
 	BinID           uint64
 	PinCounter      uint64 // maintains the no of time a chunk is pinned
 	Tag             uint32
+	Stamp           []byte // postage stamp referencing a prepaid batch
 }
 
 // Merge is a helper method to construct a new
@@ -70,6 +71,9 @@ func (i Item) Merge(i2 Item) (new Item) {
 	if i.Tag == 0 {
 		i.Tag = i2.Tag
 	}
+	if i.Stamp == nil {
+		i.Stamp = i2.Stamp
+	}
 	return i
 }
 
@@ -287,6 +291,20 @@ type IterateOptions struct {
 // Iterate function iterates over keys of the Index.
 // If IterateOptions is nil, the iterations is over all keys.
 func (f Index) Iterate(fn IndexIterFunc, options *IterateOptions) (err error) {
+	return f.iterate(fn, options, false)
+}
+
+// IterateKeys iterates over keys of the Index in the same way as Iterate,
+// but Items passed to fn have only the fields decoded from keys. Values are
+// not copied and decoded, which is much cheaper for indexes with large
+// values, like chunk data.
+func (f Index) IterateKeys(fn IndexIterFunc, options *IterateOptions) (err error) {
+	return f.iterate(fn, options, true)
+}
+
+// iterate iterates over keys of the Index, decoding values
+// only if keysOnly is false.
+func (f Index) iterate(fn IndexIterFunc, options *IterateOptions, keysOnly bool) (err error) {
 	if options == nil {
 		options = new(IterateOptions)
 	}
@@ -316,7 +334,12 @@ func (f Index) Iterate(fn IndexIterFunc, options *IterateOptions) (err error) {
 		ok = it.Next()
 	}
 	for ; ok; ok = it.Next() {
-		item, err := f.itemFromIterator(it, prefix)
+		var item Item
+		if keysOnly {
+			item, err = f.keyItemFromIterator(it, prefix)
+		} else {
+			item, err = f.itemFromIterator(it, prefix)
+		}
 		if err != nil {
 			if err == leveldb.ErrNotFound {
 				break
@@ -369,6 +392,18 @@ func (f Index) itemFromIterator(it iterator.Iterator, totalPrefix []byte) (i Ite
 	return keyItem.Merge(valueItem), it.Error()
 }
 
+// keyItemFromIterator returns the Item with fields decoded only from the key
+// at the current iterator position. If the complete encoded key does not
+// start with totalPrefix, leveldb.ErrNotFound is returned.
+func (f Index) keyItemFromIterator(it iterator.Iterator, totalPrefix []byte) (i Item, err error) {
+	key := it.Key()
+	if !bytes.HasPrefix(key, totalPrefix) {
+		return i, leveldb.ErrNotFound
+	}
+	// create a copy of key byte slice not to share leveldb underlaying slice array
+	return f.decodeKeyFunc(append([]byte(nil), key...))
+}
+
 // Last returns the last item in the Index which encoded key starts with a prefix.
 // If the prefix is nil, the last element of the whole index is returned.
 // If Index has no elements, a leveldb.ErrNotFound error is returned.
@@ -418,6 +418,25 @@ func TestIndex_Iterate(t *testing.T) {
 		}
 	})
 
+	t.Run("keys", func(t *testing.T) {
+		var i int
+		err := index.IterateKeys(func(item Item) (stop bool, err error) {
+			if i > len(items)-1 {
+				return true, fmt.Errorf("got unexpected index item: %#v", item)
+			}
+			want := Item{Address: items[i].Address}
+			checkItem(t, item, want)
+			i++
+			return false, nil
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i != len(items) {
+			t.Fatalf("got %v items, want %v", i, len(items))
+		}
+	})
+
 	t.Run("start from", func(t *testing.T) {
 		startIndex := 2
 		i := startIndex
@@ -0,0 +1,193 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/ethersphere/swarm/bmt"
+	"github.com/ethersphere/swarm/chunk"
+	"golang.org/x/crypto/sha3"
+)
+
+var (
+	// ErrInvalidAuditProof is returned by VerifyAuditProof
+	// when the proof does not prove storage of the content.
+	ErrInvalidAuditProof = errors.New("invalid audit proof")
+	// ErrAuditEncrypted is returned when an audit proof is
+	// requested for encrypted content.
+	ErrAuditEncrypted = errors.New("audit proofs are not supported for encrypted content")
+)
+
+const (
+	auditBranches     = chunk.DefaultSize / AddressLength
+	auditSegmentCount = chunk.DefaultSize / AddressLength
+)
+
+// auditHasher is the BMT base hash function used for audit proofs.
+var auditHasher = sha3.NewLegacyKeccak256
+
+// AuditProof proves that a data chunk of the content, selected by a challenge,
+// is available to the prover. It holds BMT inclusion proofs for all chunks on
+// the path from the root chunk to the selected data chunk. Every intermediate
+// chunk proof proves the reference of the next chunk on the path, and the last
+// proof proves a data segment selected by the challenge.
+type AuditProof struct {
+	Challenge []byte       `json:"challenge"`
+	Proofs    []*bmt.Proof `json:"proofs"`
+}
+
+// NewAuditProof constructs the AuditProof for the content with root address
+// and the challenge from the chunks in the store. Only locally stored chunks
+// are used, ErrChunkNotFound is returned if any of the chunks is missing.
+func NewAuditProof(ctx context.Context, store ChunkStore, root Address, challenge []byte) (*AuditProof, error) {
+	if len(root) != AddressLength {
+		return nil, ErrAuditEncrypted
+	}
+	p := &AuditProof{
+		Challenge: challenge,
+	}
+	var t auditTree
+	addr := root
+	for level := 0; ; level++ {
+		has, err := store.Has(ctx, addr)
+		if err != nil {
+			return nil, err
+		}
+		if !has {
+			return nil, ErrChunkNotFound
+		}
+		ch, err := store.Get(ctx, chunk.ModeGetSync, addr)
+		if err != nil {
+			return nil, err
+		}
+		data := ch.Data()
+		if len(data) < 8 {
+			return nil, ErrChunkInvalid
+		}
+		size := int64(binary.LittleEndian.Uint64(data[:8]))
+		if level == 0 {
+			t = newAuditTree(size)
+		}
+		index := t.index(challenge, level, size)
+		proof, err := bmt.NewProof(auditHasher, auditSegmentCount, data[:8], data[8:], index)
+		if err != nil {
+			return nil, err
+		}
+		p.Proofs = append(p.Proofs, proof)
+		if t.depth == 0 {
+			return p, nil
+		}
+		addr = proof.Segment
+		t.down()
+	}
+}
+
+// VerifyAuditProof checks that the proof is constructed for the content with
+// root address and that the proven chunks are selected by the proof challenge.
+// If the proof is not valid, ErrInvalidAuditProof is returned.
+func VerifyAuditProof(root Address, p *AuditProof) error {
+	if len(root) != AddressLength {
+		return ErrAuditEncrypted
+	}
+	var t auditTree
+	addr := root
+	var wantSize int64
+	for level, proof := range p.Proofs {
+		if !proof.Verify(auditHasher, auditSegmentCount, addr) {
+			return ErrInvalidAuditProof
+		}
+		size := int64(proof.Length())
+		if level == 0 {
+			t = newAuditTree(size)
+		} else if size != wantSize {
+			return ErrInvalidAuditProof
+		}
+		if proof.Index != t.index(p.Challenge, level, size) {
+			return ErrInvalidAuditProof
+		}
+		if t.depth == 0 {
+			if level != len(p.Proofs)-1 {
+				return ErrInvalidAuditProof
+			}
+			return nil
+		}
+		// the size of the next chunk on the path
+		wantSize = size - int64(proof.Index)*t.treeSize
+		if wantSize > t.treeSize {
+			wantSize = t.treeSize
+		}
+		addr = proof.Segment
+		t.down()
+	}
+	return ErrInvalidAuditProof
+}
+
+// auditTree tracks the position on the path from the root chunk to
+// a data chunk in the same way as LazyChunkReader does when joining.
+type auditTree struct {
+	depth    int   // number of levels under the current chunk
+	treeSize int64 // maximal size of data under a child of the current chunk
+}
+
+// newAuditTree returns auditTree for the root chunk
+// of the content with size.
+func newAuditTree(size int64) (t auditTree) {
+	treeSize := int64(chunk.DefaultSize)
+	// size is limited to prevent overflow with sizes from invalid proofs
+	for ; treeSize < size && treeSize <= math.MaxInt64/auditBranches; treeSize *= auditBranches {
+		t.depth++
+	}
+	t.treeSize = treeSize / auditBranches
+	return t
+}
+
+// index returns the index of the segment in the chunk with data size
+// at the level that is selected by the challenge. For intermediate
+// chunks it is the index of the child reference and for data chunks
+// the index of a data segment.
+func (t *auditTree) index(challenge []byte, level int, size int64) int {
+	for size < t.treeSize && t.depth > 0 {
+		t.treeSize /= auditBranches
+		t.depth--
+	}
+	var n int64
+	if t.depth == 0 {
+		n = (size + AddressLength - 1) / AddressLength
+	} else {
+		n = (size + t.treeSize - 1) / t.treeSize
+	}
+	if n < 1 {
+		n = 1
+	}
+
+	h := sha3.NewLegacyKeccak256()
+	h.Write(challenge)
+	var l [8]byte
+	binary.BigEndian.PutUint64(l[:], uint64(level))
+	h.Write(l[:])
+	return int(binary.BigEndian.Uint64(h.Sum(nil)[:8]) % uint64(n))
+}
+
+// down moves the position to the child of the current chunk.
+func (t *auditTree) down() {
+	t.depth--
+	t.treeSize /= auditBranches
+}
@@ -0,0 +1,100 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/storage/localstore"
+	"github.com/ethersphere/swarm/testutil"
+)
+
+// TestAuditProof validates construction and verification
+// of audit proofs for content of different sizes.
+func TestAuditProof(t *testing.T) {
+	dir, err := ioutil.TempDir("", "swarm-storage-audit-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	localStore, err := localstore.New(dir, make([]byte, 32), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer localStore.Close()
+
+	fileStore := NewFileStore(localStore, localStore, NewFileStoreParams(), chunk.NewTags())
+
+	for _, size := range []int{1, 4095, 4096, 4097, 4096 * 128, 4096*128 + 1, 4096 * 130} {
+		t.Run(fmt.Sprintf("size %v", size), func(t *testing.T) {
+			ctx := context.Background()
+			root, wait, err := fileStore.Store(ctx, bytes.NewReader(testutil.RandomBytes(size, size)), int64(size), false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := wait(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < 10; i++ {
+				challenge := testutil.RandomBytes(i, 32)
+				p, err := NewAuditProof(ctx, fileStore.ChunkStore, root, challenge)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := VerifyAuditProof(root, p); err != nil {
+					t.Fatalf("challenge %x: %v", challenge, err)
+				}
+
+				if err := VerifyAuditProof(make([]byte, AddressLength), p); err != ErrInvalidAuditProof {
+					t.Errorf("got error %v for other root, want %v", err, ErrInvalidAuditProof)
+				}
+
+				truncated := &AuditProof{
+					Challenge: p.Challenge,
+					Proofs:    p.Proofs[:len(p.Proofs)-1],
+				}
+				if err := VerifyAuditProof(root, truncated); err != ErrInvalidAuditProof {
+					t.Errorf("got error %v for truncated proof, want %v", err, ErrInvalidAuditProof)
+				}
+
+				leaf := p.Proofs[len(p.Proofs)-1]
+				leaf.Segment = append([]byte{}, leaf.Segment...)
+				leaf.Segment[0]++
+				if err := VerifyAuditProof(root, p); err != ErrInvalidAuditProof {
+					t.Errorf("got error %v for modified segment, want %v", err, ErrInvalidAuditProof)
+				}
+			}
+		})
+	}
+
+	_, err = NewAuditProof(context.Background(), fileStore.ChunkStore, make([]byte, AddressLength), nil)
+	if err != ErrChunkNotFound {
+		t.Errorf("got error %v for missing chunk, want %v", err, ErrChunkNotFound)
+	}
+
+	_, err = NewAuditProof(context.Background(), fileStore.ChunkStore, make([]byte, 2*AddressLength), nil)
+	if err != ErrAuditEncrypted {
+		t.Errorf("got error %v for encrypted content, want %v", err, ErrAuditEncrypted)
+	}
+}
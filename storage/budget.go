@@ -0,0 +1,137 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// DefaultUploadMemoryBudget is the default number of bytes of chunk
+// data that all uploads can hold in memory until the chunks are stored.
+const DefaultUploadMemoryBudget = 256 * 1024 * 1024
+
+// MemoryBudget limits the total size of chunk data held in memory.
+// Acquire blocks until enough of the budget is released, providing
+// backpressure to the producers of chunks. A nil MemoryBudget does
+// not limit memory.
+type MemoryBudget struct {
+	size    int64
+	used    int64
+	chunks  int64
+	waiting int64
+	changed chan struct{} // closed and replaced when budget is released
+	mu      sync.Mutex
+}
+
+// NewMemoryBudget returns a new MemoryBudget of size bytes.
+// If size is not positive, nil is returned for unlimited budget.
+func NewMemoryBudget(size int64) *MemoryBudget {
+	if size <= 0 {
+		return nil
+	}
+	return &MemoryBudget{
+		size:    size,
+		changed: make(chan struct{}),
+	}
+}
+
+// Acquire reserves n bytes of the budget for a single chunk, blocking
+// until they are available or the context is done. Chunks larger than
+// the whole budget reserve the whole budget.
+func (b *MemoryBudget) Acquire(ctx context.Context, n int64) error {
+	if b == nil {
+		return nil
+	}
+	if n > b.size {
+		n = b.size
+	}
+	var start time.Time
+	for {
+		b.mu.Lock()
+		if b.used+n <= b.size {
+			b.used += n
+			b.chunks++
+			if !start.IsZero() {
+				b.waiting--
+			}
+			b.updateMetrics()
+			b.mu.Unlock()
+			if !start.IsZero() {
+				metrics.GetOrRegisterResettingTimer("storage/upload/budget/wait", nil).UpdateSince(start)
+			}
+			return nil
+		}
+		if start.IsZero() {
+			start = time.Now()
+			b.waiting++
+			b.updateMetrics()
+		}
+		changed := b.changed
+		b.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			b.mu.Lock()
+			b.waiting--
+			b.updateMetrics()
+			b.mu.Unlock()
+			return ctx.Err()
+		}
+	}
+}
+
+// Release returns n bytes of a single chunk to the budget.
+// It must be called once for every successful Acquire with the same n.
+func (b *MemoryBudget) Release(n int64) {
+	if b == nil {
+		return
+	}
+	if n > b.size {
+		n = b.size
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.used -= n
+	b.chunks--
+	b.updateMetrics()
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// Used returns the number of bytes reserved by chunks in memory.
+func (b *MemoryBudget) Used() int64 {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.used
+}
+
+// updateMetrics must be called under the mu lock.
+func (b *MemoryBudget) updateMetrics() {
+	metrics.GetOrRegisterGauge("storage/upload/budget/bytes", nil).Update(b.used)
+	metrics.GetOrRegisterGauge("storage/upload/budget/chunks", nil).Update(b.chunks)
+	metrics.GetOrRegisterGauge("storage/upload/budget/waiting", nil).Update(b.waiting)
+}
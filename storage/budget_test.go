@@ -0,0 +1,129 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestMemoryBudget validates that Acquire blocks when the budget
+// is exhausted and continues when the budget is released.
+func TestMemoryBudget(t *testing.T) {
+	b := NewMemoryBudget(100)
+
+	ctx := context.Background()
+	if err := b.Acquire(ctx, 60); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Acquire(ctx, 40); err != nil {
+		t.Fatal(err)
+	}
+	if used := b.Used(); used != 100 {
+		t.Fatalf("got used %v, want %v", used, 100)
+	}
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- b.Acquire(ctx, 50)
+	}()
+
+	select {
+	case err := <-acquired:
+		t.Fatalf("acquired over budget with error %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// releasing less than needed must not unblock
+	b.Release(40)
+	select {
+	case err := <-acquired:
+		t.Fatalf("acquired over budget with error %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	b.Release(60)
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for acquire")
+	}
+	if used := b.Used(); used != 50 {
+		t.Fatalf("got used %v, want %v", used, 50)
+	}
+}
+
+// TestMemoryBudget_contextDone validates that Acquire returns
+// the context error when the context is done while waiting.
+func TestMemoryBudget_contextDone(t *testing.T) {
+	b := NewMemoryBudget(10)
+
+	if err := b.Acquire(context.Background(), 10); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := b.Acquire(ctx, 1); err != context.DeadlineExceeded {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if used := b.Used(); used != 10 {
+		t.Fatalf("got used %v, want %v", used, 10)
+	}
+}
+
+// TestMemoryBudget_oversized validates that a chunk larger than
+// the whole budget does not block forever.
+func TestMemoryBudget_oversized(t *testing.T) {
+	b := NewMemoryBudget(10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := b.Acquire(ctx, 100); err != nil {
+		t.Fatal(err)
+	}
+	if used := b.Used(); used != 10 {
+		t.Fatalf("got used %v, want %v", used, 10)
+	}
+	b.Release(100)
+	if used := b.Used(); used != 0 {
+		t.Fatalf("got used %v, want %v", used, 0)
+	}
+}
+
+// TestMemoryBudget_unlimited validates that a nil budget never blocks.
+func TestMemoryBudget_unlimited(t *testing.T) {
+	b := NewMemoryBudget(0)
+	if b != nil {
+		t.Fatal("expected nil budget")
+	}
+	for i := 0; i < 10; i++ {
+		if err := b.Acquire(context.Background(), 1<<30); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b.Release(1 << 30)
+	if used := b.Used(); used != 0 {
+		t.Fatalf("got used %v, want %v", used, 0)
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethersphere/swarm/chunk"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// CacheTier is a chunk.Store that keeps chunks received as a result of
+// retrieve requests outside of the area of responsibility, mostly on behalf
+// of proxy requests, in a separate cache store. The cache store has its own
+// capacity and garbage collection, so that such chunks do not compete with
+// the chunks in the main store for garbage collection.
+//
+// Chunks are read from the main store first and from the cache store if they
+// are not found there. Cached chunks that are within the area of
+// responsibility are reported as not stored, so that they are synced to the
+// main store. Pinning moves cached chunks to the main store. Pull syncing and
+// other Setter modes are served by the main store.
+type CacheTier struct {
+	chunk.Store
+	cache         chunk.Store
+	isWithinDepth func([]byte) bool
+}
+
+// NewCacheTier returns a new CacheTier with the main store and the cache
+// store for retrieved chunks. The isWithinDepth function reports whether a
+// chunk address is within the area of responsibility. If it is nil, no
+// chunks are considered to be within the area of responsibility.
+func NewCacheTier(store, cache chunk.Store, isWithinDepth func([]byte) bool) *CacheTier {
+	if isWithinDepth == nil {
+		isWithinDepth = func(_ []byte) bool { return false }
+	}
+	return &CacheTier{
+		Store:         store,
+		cache:         cache,
+		isWithinDepth: isWithinDepth,
+	}
+}
+
+// Get returns the chunk from the main store,
+// or from the cache store if it is not in the main store.
+func (c *CacheTier) Get(ctx context.Context, mode chunk.ModeGet, addr chunk.Address) (ch chunk.Chunk, err error) {
+	ch, err = c.Store.Get(ctx, mode, addr)
+	if err != chunk.ErrChunkNotFound {
+		return ch, err
+	}
+	ch, err = c.cache.Get(ctx, mode, addr)
+	if err != nil {
+		return nil, err
+	}
+	metrics.GetOrRegisterCounter("storage/cachetier/get/hit", nil).Inc(1)
+	return ch, nil
+}
+
+// GetMulti returns chunks from the main store, or from
+// the cache store if any of them is not in the main store.
+func (c *CacheTier) GetMulti(ctx context.Context, mode chunk.ModeGet, addrs ...chunk.Address) (chs []chunk.Chunk, err error) {
+	chs, err = c.Store.GetMulti(ctx, mode, addrs...)
+	if err != chunk.ErrChunkNotFound && err != leveldb.ErrNotFound {
+		return chs, err
+	}
+	chs = make([]chunk.Chunk, len(addrs))
+	for i, addr := range addrs {
+		chs[i], err = c.Get(ctx, mode, addr)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return chs, nil
+}
+
+// Put stores chunks received with ModePutRequest that are outside of the
+// area of responsibility in the cache store, if they are not already in the
+// main store. All other chunks are stored in the main store.
+func (c *CacheTier) Put(ctx context.Context, mode chunk.ModePut, chs ...chunk.Chunk) (exist []bool, err error) {
+	if mode != chunk.ModePutRequest {
+		return c.Store.Put(ctx, mode, chs...)
+	}
+
+	exist = make([]bool, len(chs))
+	var main, cache []int // indexes of chunks for the main and the cache store
+	for i, ch := range chs {
+		if c.isWithinDepth(ch.Address()) {
+			main = append(main, i)
+			continue
+		}
+		has, err := c.Store.Has(ctx, ch.Address())
+		if err != nil {
+			return nil, err
+		}
+		if has {
+			exist[i] = true
+			continue
+		}
+		cache = append(cache, i)
+	}
+	if err := putIndexes(ctx, c.Store, mode, chs, main, exist); err != nil {
+		return nil, err
+	}
+	if err := putIndexes(ctx, c.cache, mode, chs, cache, exist); err != nil {
+		return nil, err
+	}
+	metrics.GetOrRegisterCounter("storage/cachetier/put", nil).Inc(int64(len(cache)))
+	return exist, nil
+}
+
+// putIndexes puts chunks with provided indexes to the store
+// and sets their exist values.
+func putIndexes(ctx context.Context, store chunk.Store, mode chunk.ModePut, chs []chunk.Chunk, indexes []int, exist []bool) error {
+	if len(indexes) == 0 {
+		return nil
+	}
+	put := make([]chunk.Chunk, len(indexes))
+	for i, j := range indexes {
+		put[i] = chs[j]
+	}
+	e, err := store.Put(ctx, mode, put...)
+	if err != nil {
+		return err
+	}
+	for i, j := range indexes {
+		exist[j] = e[i]
+	}
+	return nil
+}
+
+// Has returns true if the chunk is in the main store, or if it is in the
+// cache store and it is not within the area of responsibility.
+func (c *CacheTier) Has(ctx context.Context, addr chunk.Address) (bool, error) {
+	has, err := c.Store.Has(ctx, addr)
+	if err != nil || has || c.isWithinDepth(addr) {
+		return has, err
+	}
+	return c.cache.Has(ctx, addr)
+}
+
+// HasMulti returns the same as Has for every address.
+func (c *CacheTier) HasMulti(ctx context.Context, addrs ...chunk.Address) ([]bool, error) {
+	have, err := c.Store.HasMulti(ctx, addrs...)
+	if err != nil {
+		return nil, err
+	}
+	var check []chunk.Address
+	var indexes []int
+	for i, addr := range addrs {
+		if !have[i] && !c.isWithinDepth(addr) {
+			check = append(check, addr)
+			indexes = append(indexes, i)
+		}
+	}
+	if len(check) == 0 {
+		return have, nil
+	}
+	cached, err := c.cache.HasMulti(ctx, check...)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := range indexes {
+		have[j] = cached[i]
+	}
+	return have, nil
+}
+
+// Set moves chunks that are only in the cache store to the main store before
+// they are pinned, and sets the mode on the main store.
+func (c *CacheTier) Set(ctx context.Context, mode chunk.ModeSet, addrs ...chunk.Address) error {
+	if mode == chunk.ModeSetPin {
+		for _, addr := range addrs {
+			if err := c.moveToMain(ctx, addr); err != nil {
+				return err
+			}
+		}
+	}
+	return c.Store.Set(ctx, mode, addrs...)
+}
+
+// moveToMain puts the chunk from the cache store to the main store
+// and removes it from the cache store, if it is not in the main store.
+func (c *CacheTier) moveToMain(ctx context.Context, addr chunk.Address) error {
+	has, err := c.Store.Has(ctx, addr)
+	if err != nil || has {
+		return err
+	}
+	ch, err := c.cache.Get(ctx, chunk.ModeGetLookup, addr)
+	if err != nil {
+		if err == chunk.ErrChunkNotFound {
+			// let the main store report the missing chunk
+			return nil
+		}
+		return err
+	}
+	if _, err := c.Store.Put(ctx, chunk.ModePutRequest, ch); err != nil {
+		return err
+	}
+	metrics.GetOrRegisterCounter("storage/cachetier/move", nil).Inc(1)
+	return c.cache.Set(ctx, chunk.ModeSetRemove, addr)
+}
+
+// Close closes both the main and the cache store.
+func (c *CacheTier) Close() (err error) {
+	if err := c.cache.Close(); err != nil {
+		c.Store.Close()
+		return err
+	}
+	return c.Store.Close()
+}
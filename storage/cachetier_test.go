@@ -0,0 +1,198 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/storage/localstore"
+)
+
+// TestCacheTier validates that chunks are stored in and retrieved from
+// the main or the cache store depending on the put mode and the area of
+// responsibility.
+func TestCacheTier(t *testing.T) {
+	main, cleanupMain := newTestCacheTierLocalStore(t)
+	defer cleanupMain()
+	cache, cleanupCache := newTestCacheTierLocalStore(t)
+	defer cleanupCache()
+
+	withinDepth := make(map[string]bool)
+	tier := NewCacheTier(main, cache, func(addr []byte) bool {
+		return withinDepth[string(addr)]
+	})
+
+	ctx := context.Background()
+
+	has := func(t *testing.T, store chunk.Store, addr chunk.Address, want bool) {
+		t.Helper()
+
+		got, err := store.Has(ctx, addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("got has %v, want %v", got, want)
+		}
+	}
+
+	get := func(t *testing.T, ch chunk.Chunk) {
+		t.Helper()
+
+		got, err := tier.Get(ctx, chunk.ModeGetRequest, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.Data(), ch.Data()) {
+			t.Error("got invalid chunk data")
+		}
+	}
+
+	t.Run("request outside of depth", func(t *testing.T) {
+		ch := GenerateRandomChunk(chunk.DefaultSize)
+
+		exist, err := tier.Put(ctx, chunk.ModePutRequest, ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exist[0] {
+			t.Error("chunk reported as existing")
+		}
+		has(t, main, ch.Address(), false)
+		has(t, cache, ch.Address(), true)
+		has(t, tier, ch.Address(), true)
+		get(t, ch)
+
+		// cached chunk that becomes within depth should be synced
+		withinDepth[string(ch.Address())] = true
+		has(t, tier, ch.Address(), false)
+		got, err := tier.HasMulti(ctx, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got[0] {
+			t.Error("cached chunk within depth reported as stored")
+		}
+	})
+
+	t.Run("request within depth", func(t *testing.T) {
+		ch := GenerateRandomChunk(chunk.DefaultSize)
+		withinDepth[string(ch.Address())] = true
+
+		if _, err := tier.Put(ctx, chunk.ModePutRequest, ch); err != nil {
+			t.Fatal(err)
+		}
+		has(t, main, ch.Address(), true)
+		has(t, cache, ch.Address(), false)
+		get(t, ch)
+	})
+
+	t.Run("sync", func(t *testing.T) {
+		ch := GenerateRandomChunk(chunk.DefaultSize)
+
+		if _, err := tier.Put(ctx, chunk.ModePutSync, ch); err != nil {
+			t.Fatal(err)
+		}
+		has(t, main, ch.Address(), true)
+		has(t, cache, ch.Address(), false)
+		get(t, ch)
+	})
+
+	t.Run("request stored in main", func(t *testing.T) {
+		ch := GenerateRandomChunk(chunk.DefaultSize)
+
+		if _, err := tier.Put(ctx, chunk.ModePutUpload, ch); err != nil {
+			t.Fatal(err)
+		}
+		exist, err := tier.Put(ctx, chunk.ModePutRequest, ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !exist[0] {
+			t.Error("chunk not reported as existing")
+		}
+		has(t, cache, ch.Address(), false)
+	})
+
+	t.Run("multiple chunks", func(t *testing.T) {
+		chs := GenerateRandomChunks(chunk.DefaultSize, 3)
+		withinDepth[string(chs[1].Address())] = true
+
+		if _, err := tier.Put(ctx, chunk.ModePutRequest, chs...); err != nil {
+			t.Fatal(err)
+		}
+		has(t, cache, chs[0].Address(), true)
+		has(t, main, chs[1].Address(), true)
+		has(t, cache, chs[2].Address(), true)
+
+		got, err := tier.GetMulti(ctx, chunk.ModeGetRequest, chs[0].Address(), chs[1].Address(), chs[2].Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, ch := range chs {
+			if !bytes.Equal(got[i].Data(), ch.Data()) {
+				t.Errorf("got invalid chunk %v data", i)
+			}
+		}
+	})
+
+	t.Run("pin", func(t *testing.T) {
+		ch := GenerateRandomChunk(chunk.DefaultSize)
+
+		if _, err := tier.Put(ctx, chunk.ModePutRequest, ch); err != nil {
+			t.Fatal(err)
+		}
+		if err := tier.Set(ctx, chunk.ModeSetPin, ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+		has(t, main, ch.Address(), true)
+		has(t, cache, ch.Address(), false)
+
+		got, err := tier.Get(ctx, chunk.ModeGetPin, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.PinCounter() != 1 {
+			t.Errorf("got pin counter %v, want 1", got.PinCounter())
+		}
+	})
+}
+
+// newTestCacheTierLocalStore returns a localstore in a temporary directory
+// and a cleanup function.
+func newTestCacheTierLocalStore(t *testing.T) (db *localstore.DB, cleanup func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "swarm-storage-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err = localstore.New(dir, make([]byte, 32), nil)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
@@ -172,6 +172,16 @@ func (h *Handler) Lookup(ctx context.Context, query *Query) (*cacheEntry, error)
 		}
 	}
 
+	request, err := h.lookup(ctx, &query.Feed, timeLimit, query.Hint)
+	if err != nil {
+		return nil, err
+	}
+	return h.updateCache(request)
+}
+
+// lookup finds the feed update with the highest time that is lower or equal
+// than timeLimit, without updating the cache.
+func (h *Handler) lookup(ctx context.Context, feed *Feed, timeLimit uint64, hint lookup.Epoch) (*Request, error) {
 	// we can't look for anything without a store
 	if h.chunkStore == nil {
 		return nil, NewError(ErrInit, "Call Handler.SetStore() before performing lookups")
@@ -181,30 +191,14 @@ func (h *Handler) Lookup(ctx context.Context, query *Query) (*cacheEntry, error)
 
 	// Invoke the lookup engine.
 	// The callback will be called every time the lookup algorithm needs to guess
-	requestPtr, err := lookup.Lookup(ctx, timeLimit, query.Hint, func(ctx context.Context, epoch lookup.Epoch, now uint64) (interface{}, error) {
+	requestPtr, err := lookup.Lookup(ctx, timeLimit, hint, func(ctx context.Context, epoch lookup.Epoch, now uint64) (interface{}, error) {
 		atomic.AddInt32(&readCount, 1)
-		id := ID{
-			Feed:  query.Feed,
-			Epoch: epoch,
-		}
-		ctx, cancel := context.WithTimeout(ctx, defaultRetrieveTimeout)
-		defer cancel()
-
-		r := storage.NewRequest(id.Addr())
-		ch, err := h.chunkStore.Get(ctx, chunk.ModeGetLookup, r)
-		if err != nil {
-			if err == context.DeadlineExceeded || err == storage.ErrNoSuitablePeer { // chunk not found
-				return nil, nil
-			}
+		request, err := h.read(ctx, feed, epoch)
+		if err != nil || request == nil {
 			return nil, err
 		}
-
-		var request Request
-		if err := request.fromChunk(ch); err != nil {
-			return nil, nil
-		}
 		if request.Time <= timeLimit {
-			return &request, nil
+			return request, nil
 		}
 		return nil, nil
 	})
@@ -218,8 +212,33 @@ func (h *Handler) Lookup(ctx context.Context, query *Query) (*cacheEntry, error)
 	if request == nil {
 		return nil, NewError(ErrNotFound, "no feed updates found")
 	}
-	return h.updateCache(request)
+	return request, nil
+}
+
+// read returns the feed update at the epoch,
+// or nil if it is not found or it is not valid.
+func (h *Handler) read(ctx context.Context, feed *Feed, epoch lookup.Epoch) (*Request, error) {
+	id := ID{
+		Feed:  *feed,
+		Epoch: epoch,
+	}
+	ctx, cancel := context.WithTimeout(ctx, defaultRetrieveTimeout)
+	defer cancel()
 
+	r := storage.NewRequest(id.Addr())
+	ch, err := h.chunkStore.Get(ctx, chunk.ModeGetLookup, r)
+	if err != nil {
+		if err == context.DeadlineExceeded || err == storage.ErrNoSuitablePeer { // chunk not found
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var request Request
+	if err := request.fromChunk(ch); err != nil {
+		return nil, nil
+	}
+	return &request, nil
 }
 
 // update feed updates cache with specified content
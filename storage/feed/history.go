@@ -0,0 +1,103 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package feed
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethersphere/swarm/storage"
+	"github.com/ethersphere/swarm/storage/feed/lookup"
+)
+
+// HistoryEntry is a feed update returned by Handler.History.
+type HistoryEntry struct {
+	Epoch   lookup.Epoch    `json:"epoch"`   // epoch of the update, with the time of the update
+	Address storage.Address `json:"address"` // address of the update chunk
+	Data    hexutil.Bytes   `json:"data"`    // update payload
+}
+
+// History returns feed updates with times between from and to, inclusive,
+// ordered from the oldest to the newest. If to is 0, the current time is used.
+// If limit is greater than 0, at most limit newest updates in the range are
+// returned, and older updates can be retrieved by setting to to the time
+// before the time of the oldest returned update.
+//
+// The history is walked backwards from the newest update with the lookup
+// algorithm, finding the latest update before the time of the previous one.
+// Updates published in the same second as the previous one are found at the
+// epoch one level higher than the previous one, as placed by GetNextEpoch.
+func (h *Handler) History(ctx context.Context, feed *Feed, from, to uint64, limit int) ([]*HistoryEntry, error) {
+	if feed == nil {
+		return nil, NewError(ErrInvalidValue, "feed is nil")
+	}
+	if to == 0 {
+		to = TimestampProvider.Now().Time
+	}
+	if from > to {
+		return nil, NewError(ErrInvalidValue, "history start time is after its end time")
+	}
+
+	var entries []*HistoryEntry
+	var last *Request
+	timeLimit := to
+	for limit <= 0 || len(entries) < limit {
+		var request *Request
+		if last != nil && last.Epoch.Level < lookup.HighestLevel {
+			r, err := h.read(ctx, feed, lookup.Epoch{
+				Time:  last.Epoch.Time,
+				Level: last.Epoch.Level + 1,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if r != nil && r.Epoch.Time == last.Epoch.Time {
+				request = r
+			}
+		}
+		if request == nil {
+			if last != nil && (last.Epoch.Time == 0 || last.Epoch.Time-1 < from) {
+				// no updates with lower times are in range
+				break
+			}
+			r, err := h.lookup(ctx, feed, timeLimit, lookup.NoClue)
+			if err != nil {
+				if e, ok := err.(*Error); ok && e.Code() == ErrNotFound {
+					break
+				}
+				return nil, err
+			}
+			if r.Epoch.Time < from {
+				break
+			}
+			request = r
+		}
+		entries = append(entries, &HistoryEntry{
+			Epoch:   request.Epoch,
+			Address: request.idAddr,
+			Data:    request.data,
+		})
+		last = request
+		timeLimit = request.Epoch.Time - 1
+	}
+
+	// reverse to the chronological order
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package feed
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ethersphere/swarm/storage/feed/lookup"
+)
+
+// TestHistory validates that Handler.History returns all updates
+// in the requested time range, including updates published in
+// the same second.
+func TestHistory(t *testing.T) {
+	timeProvider := &fakeTimeProvider{
+		currentTime: startTime.Time,
+	}
+	signer := newAliceSigner()
+
+	fh, _, teardownTest, err := setupTest(timeProvider, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardownTest()
+
+	ctx := context.Background()
+	topic, _ := NewTopic("history", nil)
+	fd := Feed{
+		Topic: topic,
+		User:  signer.Address(),
+	}
+
+	entries, err := fh.History(ctx, &fd, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("got %v entries for a feed without updates, want 0", len(entries))
+	}
+
+	// update times, with two updates in the same second
+	times := []uint64{4200, 4242, 4284, 4284, 4285, 5000, 100000}
+	var epoch lookup.Epoch
+	for i, tm := range times {
+		request := NewFirstRequest(fd.Topic)
+		request.Epoch = lookup.GetNextEpoch(epoch, tm)
+		request.data = historyData(i)
+		if err := request.Sign(signer); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fh.Update(ctx, request); err != nil {
+			t.Fatal(err)
+		}
+		epoch = request.Epoch
+	}
+	timeProvider.Set(200000)
+
+	for _, tc := range []struct {
+		name      string
+		from, to  uint64
+		limit     int
+		wantIndex []int
+	}{
+		{name: "all", from: 0, to: 0, wantIndex: []int{0, 1, 2, 3, 4, 5, 6}},
+		{name: "range", from: 4242, to: 4285, wantIndex: []int{1, 2, 3, 4}},
+		{name: "between updates", from: 4243, to: 4999, wantIndex: []int{2, 3, 4}},
+		{name: "limit", from: 0, to: 0, limit: 3, wantIndex: []int{4, 5, 6}},
+		{name: "limit in same second", from: 0, to: 4285, limit: 2, wantIndex: []int{3, 4}},
+		{name: "before updates", from: 0, to: 4199, wantIndex: nil},
+		{name: "after updates", from: 100001, to: 0, wantIndex: nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := fh.History(ctx, &fd, tc.from, tc.to, tc.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != len(tc.wantIndex) {
+				t.Fatalf("got %v entries, want %v", len(entries), len(tc.wantIndex))
+			}
+			for i, e := range entries {
+				want := tc.wantIndex[i]
+				if e.Epoch.Time != times[want] {
+					t.Errorf("entry %v: got time %v, want %v", i, e.Epoch.Time, times[want])
+				}
+				if !bytes.Equal(e.Data, historyData(want)) {
+					t.Errorf("entry %v: got data %q, want %q", i, e.Data, historyData(want))
+				}
+				id := ID{Feed: fd, Epoch: e.Epoch}
+				if !bytes.Equal(e.Address, id.Addr()) {
+					t.Errorf("entry %v: got address %s, want %s", i, e.Address, id.Addr())
+				}
+			}
+		})
+	}
+
+	if _, err := fh.History(ctx, &fd, 5000, 4000, 0); err == nil {
+		t.Error("expected error for start time after end time")
+	}
+
+	// history must not change the cached latest update
+	if _, err := fh.Lookup(ctx, NewQueryLatest(&fd, lookup.NoClue)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fh.History(ctx, &fd, 0, 4242, 0); err != nil {
+		t.Fatal(err)
+	}
+	_, content, err := fh.GetContent(&fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content, historyData(len(times)-1)) {
+		t.Errorf("got cached content %q, want %q", content, historyData(len(times)-1))
+	}
+}
+
+func historyData(i int) []byte {
+	return []byte(fmt.Sprintf("update %d", i))
+}
@@ -23,6 +23,7 @@ import (
 	"sync"
 
 	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/sctx"
 	"github.com/ethersphere/swarm/storage/localstore"
 )
 
@@ -45,18 +46,23 @@ const (
 
 type FileStore struct {
 	ChunkStore
-	putterStore ChunkStore
-	hashFunc    SwarmHasher
-	tags        *chunk.Tags
+	putterStore  ChunkStore
+	hashFunc     SwarmHasher
+	tags         *chunk.Tags
+	uploadBudget *MemoryBudget // limits chunk data held in memory by all uploads
 }
 
 type FileStoreParams struct {
 	Hash string
+	// UploadMemoryBudget is the maximal number of bytes of chunk data held in
+	// memory by all uploads until the chunks are stored, 0 for unlimited
+	UploadMemoryBudget int64
 }
 
 func NewFileStoreParams() *FileStoreParams {
 	return &FileStoreParams{
-		Hash: DefaultHash,
+		Hash:               DefaultHash,
+		UploadMemoryBudget: DefaultUploadMemoryBudget,
 	}
 }
 
@@ -75,10 +81,11 @@ func NewLocalFileStore(datadir string, basekey []byte, tags *chunk.Tags) (*FileS
 func NewFileStore(store ChunkStore, putterStore ChunkStore, params *FileStoreParams, tags *chunk.Tags) *FileStore {
 	hashFunc := MakeHashFunc(params.Hash)
 	return &FileStore{
-		ChunkStore:  store,
-		putterStore: putterStore,
-		hashFunc:    hashFunc,
-		tags:        tags,
+		ChunkStore:   store,
+		putterStore:  putterStore,
+		hashFunc:     hashFunc,
+		tags:         tags,
+		uploadBudget: NewMemoryBudget(params.UploadMemoryBudget),
 	}
 }
 
@@ -112,6 +119,8 @@ func (f *FileStore) Store(ctx context.Context, data io.Reader, size int64, toEnc
 		//return nil, nil, err
 	}
 	putter := NewHasherStore(f.putterStore, f.hashFunc, toEncrypt, tag)
+	putter.budget = f.uploadBudget
+	putter.stamp = sctx.GetStamp(ctx)
 	return PyramidSplit(ctx, data, putter, putter, tag)
 }
 
@@ -200,3 +200,49 @@ func TestGetAllReferences(t *testing.T) {
 		}
 	}
 }
+
+// TestFileStoreUploadMemoryBudget validates that content larger than
+// the upload memory budget is stored and can be retrieved.
+func TestFileStoreUploadMemoryBudget(t *testing.T) {
+	dir, err := ioutil.TempDir("", "swarm-storage-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	localStore, err := localstore.New(dir, make([]byte, 32), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer localStore.Close()
+
+	params := NewFileStoreParams()
+	params.UploadMemoryBudget = 3 * chunk.DefaultSize
+	fileStore := NewFileStore(localStore, localStore, params, chunk.NewTags())
+
+	size := int64(50 * chunk.DefaultSize)
+	slice := testutil.RandomBytes(1, int(size))
+	ctx := context.TODO()
+	key, wait, err := fileStore.Store(ctx, bytes.NewReader(slice), size, false)
+	if err != nil {
+		t.Fatalf("Store error: %v", err)
+	}
+	if err := wait(ctx); err != nil {
+		t.Fatalf("Store waitforstorage error: %v", err)
+	}
+	if used := fileStore.uploadBudget.Used(); used != 0 {
+		t.Fatalf("got upload budget used %v after store, want 0", used)
+	}
+
+	resultReader, _ := fileStore.Retrieve(context.TODO(), key)
+	resultSlice := make([]byte, size)
+	n, err := resultReader.ReadAt(resultSlice, 0)
+	if err != io.EOF {
+		t.Fatalf("Retrieve error: %v", err)
+	}
+	if int64(n) != size {
+		t.Fatalf("Slice size error got %d, expected %d.", n, size)
+	}
+	if !bytes.Equal(slice, resultSlice) {
+		t.Fatal("Comparison error.")
+	}
+}
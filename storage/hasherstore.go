@@ -39,6 +39,7 @@ type hasherStore struct {
 	nrChunks  uint64 // number of chunks to store
 	store     ChunkStore
 	tag       *chunk.Tag
+	stamp     []byte // postage stamp attached to created chunks, nil for none
 	toEncrypt bool
 	doWait    sync.Once
 	hashFunc  SwarmHasher
@@ -49,6 +50,7 @@ type hasherStore struct {
 	doneC     chan struct{} // closed by Close() call to indicate that count is the final number of chunks
 	quitC     chan struct{} // closed to quit unterminated routines
 	workers   chan Chunk    // back pressure for limiting storage workers goroutines
+	budget    *MemoryBudget // back pressure for limiting chunk data in memory across hasherStores, nil for unlimited
 }
 
 // NewHasherStore creates a hasherStore object, which implements Putter and Getter interfaces.
@@ -91,7 +93,9 @@ func (h *hasherStore) Put(ctx context.Context, chunkData ChunkData) (Reference,
 		}
 	}
 	chunk := h.createChunk(c)
-	h.storeChunk(ctx, chunk)
+	if err := h.storeChunk(ctx, chunk); err != nil {
+		return nil, err
+	}
 
 	// Start the wait function which will detect completion of put
 	h.doWait.Do(func() {
@@ -192,7 +196,7 @@ func (h *hasherStore) createHash(chunkData ChunkData) Address {
 
 func (h *hasherStore) createChunk(chunkData ChunkData) Chunk {
 	hash := h.createHash(chunkData)
-	chunk := NewChunk(hash, chunkData).WithTagID(h.tag.Uid)
+	chunk := NewChunk(hash, chunkData).WithTagID(h.tag.Uid).WithStamp(h.stamp)
 	return chunk
 }
 
@@ -273,7 +277,13 @@ func (h *hasherStore) newDataEncryption(key encryption.Key) encryption.Encryptio
 	return encryption.New(key, int(chunk.DefaultSize), 0, sha3.NewLegacyKeccak256)
 }
 
-func (h *hasherStore) storeChunk(ctx context.Context, ch Chunk) {
+// storeChunk stores the chunk asynchronously. It blocks until a storage worker
+// and the memory budget for the chunk data are available, or the context is done.
+func (h *hasherStore) storeChunk(ctx context.Context, ch Chunk) error {
+	size := int64(len(ch.Data()))
+	if err := h.budget.Acquire(ctx, size); err != nil {
+		return err
+	}
 	h.workers <- ch
 	atomic.AddUint64(&h.nrChunks, 1)
 	go func() {
@@ -281,6 +291,7 @@ func (h *hasherStore) storeChunk(ctx context.Context, ch Chunk) {
 			<-h.workers
 		}()
 		seen, err := h.store.Put(ctx, chunk.ModePutUpload, ch)
+		h.budget.Release(size)
 		h.tag.Inc(chunk.StateStored)
 		if err == nil && seen[0] {
 			h.tag.Inc(chunk.StateSeen)
@@ -290,6 +301,7 @@ func (h *hasherStore) storeChunk(ctx context.Context, ch Chunk) {
 		case <-h.quitC:
 		}
 	}()
+	return nil
 }
 
 func parseReference(ref Reference, hashSize int) (Address, encryption.Key, error) {
@@ -20,7 +20,7 @@ import (
 	"context"
 
 	"github.com/ethersphere/swarm/chunk"
-	"github.com/ethersphere/swarm/network/timeouts"
+	"github.com/ethersphere/swarm/sctx"
 )
 
 // LNetStore is a wrapper of NetStore, which implements the chunk.Store interface. It is used only by the FileStore,
@@ -37,10 +37,14 @@ func NewLNetStore(store *NetStore) *LNetStore {
 }
 
 // Get converts a chunk reference to a chunk Request (with empty Origin), handled by the NetStore, and
-// returns the requested chunk, or error.
+// returns the requested chunk, or error. The request deadline is the earlier of the context deadline
+// and the FetcherTimeout, and its hop limit is taken from the context, if set.
 func (n *LNetStore) Get(ctx context.Context, mode chunk.ModeGet, ref Address) (ch Chunk, err error) {
-	ctx, cancel := context.WithTimeout(ctx, timeouts.FetcherGlobalTimeout)
+	ctx, cancel := context.WithTimeout(ctx, n.FetcherTimeout)
 	defer cancel()
 
-	return n.NetStore.Get(ctx, mode, NewRequest(ref))
+	req := NewRequest(ref)
+	req.HopLimit = sctx.GetHopLimit(ctx)
+	req.Deadline, _ = ctx.Deadline()
+	return n.NetStore.Get(ctx, mode, req)
 }
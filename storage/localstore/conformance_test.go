@@ -0,0 +1,37 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"testing"
+
+	"github.com/ethersphere/swarm/chunk"
+	chunktest "github.com/ethersphere/swarm/chunk/test"
+)
+
+// TestStoreConformance runs the chunk.Store conformance tests on DB.
+func TestStoreConformance(t *testing.T) {
+	// base key must be the same when the DB is opened again
+	// in the same directory, so that bins do not change
+	baseKey := make([]byte, 32)
+
+	chunktest.RunAll(t, func(t *testing.T, dir string, capacity uint64) (chunk.Store, error) {
+		return New(dir, baseKey, &Options{
+			Capacity: capacity,
+		})
+	})
+}
@@ -0,0 +1,216 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"context"
+	"encoding/hex"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethersphere/swarm/shed"
+)
+
+const (
+	// defaultDebugSampleSize is the number of items sampled
+	// at the start and at the end of every index
+	// if the sample size is not provided.
+	defaultDebugSampleSize = 5
+	// maxDebugSampleSize limits the number of sampled items
+	// to protect the node from too large responses.
+	maxDebugSampleSize = 100
+)
+
+// StorageIndices holds debugging information about localstore indexes.
+type StorageIndices struct {
+	Schema           string                 `json:"schema"`
+	MigrationHistory []MigrationRecord      `json:"migrationHistory"`
+	GCSize           uint64                 `json:"gcSize"`
+	Indices          map[string]IndexSample `json:"indices"`
+}
+
+// IndexSample holds the number of items in an index and
+// items sampled at its start and at its end.
+type IndexSample struct {
+	Count int         `json:"count"`
+	Head  []IndexItem `json:"head"`
+	Tail  []IndexItem `json:"tail"`
+}
+
+// IndexItem is a representation of shed.Item without chunk data.
+// Only the fields that are stored in a particular index are set.
+type IndexItem struct {
+	Address         string `json:"address,omitempty"`
+	StoreTimestamp  int64  `json:"storeTimestamp,omitempty"`
+	AccessTimestamp int64  `json:"accessTimestamp,omitempty"`
+	BinID           uint64 `json:"binID,omitempty"`
+	PinCounter      uint64 `json:"pinCounter,omitempty"`
+	Tag             uint32 `json:"tag,omitempty"`
+}
+
+func newIndexItem(i shed.Item) IndexItem {
+	return IndexItem{
+		Address:         hex.EncodeToString(i.Address),
+		StoreTimestamp:  i.StoreTimestamp,
+		AccessTimestamp: i.AccessTimestamp,
+		BinID:           i.BinID,
+		PinCounter:      i.PinCounter,
+		Tag:             i.Tag,
+	}
+}
+
+// DebugStorageIndices returns the schema name, migration history and
+// for every index the number of items and up to sampleSize items from
+// its start and its end. Head and tail samples do not overlap.
+// Retrieval data index is not included as iterating over it reads
+// the data of all chunks.
+func (db *DB) DebugStorageIndices(sampleSize int) (info *StorageIndices, err error) {
+	info = &StorageIndices{
+		Indices: make(map[string]IndexSample),
+	}
+	info.Schema, err = db.schemaName.Get()
+	if err != nil {
+		return nil, err
+	}
+	info.MigrationHistory, err = db.MigrationHistory()
+	if err != nil {
+		return nil, err
+	}
+	info.GCSize, err = db.gcSize.Get()
+	if err != nil {
+		return nil, err
+	}
+	for name, index := range db.indices() {
+		if name == "retrievalDataIndex" {
+			continue
+		}
+		info.Indices[name], err = sampleIndex(index, sampleSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return info, nil
+}
+
+// sampleIndex counts items in the index and collects
+// up to sampleSize items from its start and its end
+// in a single iteration.
+func sampleIndex(index shed.Index, sampleSize int) (s IndexSample, err error) {
+	s.Head = make([]IndexItem, 0)
+	s.Tail = make([]IndexItem, 0)
+	// tail is collected in a ring buffer
+	// that starts at the position count%sampleSize
+	err = index.Iterate(func(item shed.Item) (stop bool, err error) {
+		switch {
+		case len(s.Head) < sampleSize:
+			s.Head = append(s.Head, newIndexItem(item))
+		case len(s.Tail) < sampleSize:
+			s.Tail = append(s.Tail, newIndexItem(item))
+		case sampleSize > 0:
+			s.Tail[(s.Count-sampleSize)%sampleSize] = newIndexItem(item)
+		}
+		s.Count++
+		return false, nil
+	}, nil)
+	if err != nil {
+		return s, err
+	}
+	if sampleSize > 0 && len(s.Tail) == sampleSize {
+		start := (s.Count - sampleSize) % sampleSize
+		s.Tail = append(s.Tail[start:], s.Tail[:start]...)
+	}
+	return s, nil
+}
+
+// DebugAPI provides localstore debugging information over RPC.
+type DebugAPI struct {
+	db *DB
+}
+
+// NewDebugAPI returns a new DebugAPI for the database.
+func NewDebugAPI(db *DB) *DebugAPI {
+	return &DebugAPI{db: db}
+}
+
+// StorageIndices returns the schema name, migration history and counts
+// and samples of items from all indexes. Optional sampleSize is the number
+// of items that are sampled at the start and at the end of every index.
+func (a *DebugAPI) StorageIndices(sampleSize *int) (*StorageIndices, error) {
+	size := defaultDebugSampleSize
+	if sampleSize != nil {
+		size = *sampleSize
+	}
+	if size < 0 {
+		size = 0
+	}
+	if size > maxDebugSampleSize {
+		size = maxDebugSampleSize
+	}
+	return a.db.DebugStorageIndices(size)
+}
+
+// HealthReport returns the report of the health check done
+// when the database was opened.
+func (a *DebugAPI) HealthReport() HealthReport {
+	return a.db.HealthReport()
+}
+
+// ProximityHistogram returns the number of stored chunks by proximity
+// order of their addresses to the node and the estimated storage radius.
+func (a *DebugAPI) ProximityHistogram() (*ProximityHistogram, error) {
+	return a.db.ProximityHistogram()
+}
+
+// DiskStatus returns the free disk space and
+// whether the database is in degraded mode.
+func (a *DebugAPI) DiskStatus() DiskStatus {
+	return a.db.DiskStatus()
+}
+
+// DiskStatusChanges is a subscription that sends the disk status, first the
+// current one and then every time the database enters or leaves degraded
+// mode, so that operators can be notified about it.
+func (a *DebugAPI) DiskStatusChanges(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+
+	statuses, stop := a.db.SubscribeDiskStatus()
+	go func() {
+		defer stop()
+
+		for {
+			select {
+			case s, ok := <-statuses:
+				if !ok {
+					return
+				}
+				if err := notifier.Notify(sub.ID, s); err != nil {
+					log.Error("localstore disk status notify", "err", err)
+					return
+				}
+			case <-sub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
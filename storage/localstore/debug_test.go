@@ -0,0 +1,167 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"context"
+	"encoding/hex"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/shed"
+)
+
+// TestDB_DebugStorageIndices validates counts and samples
+// of index items returned by DebugStorageIndices.
+func TestDB_DebugStorageIndices(t *testing.T) {
+	db, cleanupFunc := newTestDB(t, nil)
+	defer cleanupFunc()
+
+	for _, count := range []int{0, 1, 3, 4, 5, 11} {
+		if count > 0 {
+			// upload only new chunks to have the count in total
+			_, err := db.Put(context.Background(), chunk.ModePutUpload, generateTestChunks(count-countIndex(t, db.pushIndex))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		for _, sampleSize := range []int{0, 2, 5} {
+			info, err := db.DebugStorageIndices(sampleSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Schema != DbSchemaCurrent {
+				t.Errorf("got schema %q, want %q", info.Schema, DbSchemaCurrent)
+			}
+			if len(info.MigrationHistory) != 0 {
+				t.Errorf("got migration history %v, want none", info.MigrationHistory)
+			}
+			if _, ok := info.Indices["retrievalDataIndex"]; ok {
+				t.Error("retrieval data index sampled")
+			}
+
+			var addrs []string
+			err = db.pushIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+				addrs = append(addrs, hex.EncodeToString(item.Address))
+				return false, nil
+			}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s := info.Indices["pushIndex"]
+			if s.Count != count {
+				t.Fatalf("count %v sample %v: got count %v", count, sampleSize, s.Count)
+			}
+			wantHead := sampleSize
+			if wantHead > count {
+				wantHead = count
+			}
+			wantTail := count - wantHead
+			if wantTail > sampleSize {
+				wantTail = sampleSize
+			}
+			if len(s.Head) != wantHead || len(s.Tail) != wantTail {
+				t.Fatalf("count %v sample %v: got head %v tail %v, want head %v tail %v", count, sampleSize, len(s.Head), len(s.Tail), wantHead, wantTail)
+			}
+			for i, item := range s.Head {
+				if item.Address != addrs[i] {
+					t.Errorf("count %v sample %v: got head item %v address %s, want %s", count, sampleSize, i, item.Address, addrs[i])
+				}
+			}
+			for i, item := range s.Tail {
+				want := addrs[count-wantTail+i]
+				if item.Address != want {
+					t.Errorf("count %v sample %v: got tail item %v address %s, want %s", count, sampleSize, i, item.Address, want)
+				}
+			}
+		}
+	}
+}
+
+// TestDebugAPI_DiskStatusChanges validates that the debug RPC
+// subscription sends disk status changes.
+func TestDebugAPI_DiskStatusChanges(t *testing.T) {
+	// keep the disk space worker from leaving the degraded mode set by the test
+	defer func(s uint64) { diskFullRecoverySpace = s }(diskFullRecoverySpace)
+	diskFullRecoverySpace = math.MaxUint64
+
+	db, cleanupFunc := newTestDB(t, nil)
+	defer cleanupFunc()
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("debug", NewDebugAPI(db)); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	statuses := make(chan DiskStatus)
+	sub, err := client.Subscribe(ctx, "debug", statuses, "diskStatusChanges")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	receive := func(wantDegraded bool) {
+		t.Helper()
+
+		select {
+		case s := <-statuses:
+			if s.Degraded != wantDegraded {
+				t.Errorf("got degraded %v, want %v", s.Degraded, wantDegraded)
+			}
+		case err := <-sub.Err():
+			t.Fatal(err)
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+		}
+	}
+
+	receive(false)
+
+	db.diskMu.Lock()
+	db.setDegraded(degradedReasonDiskFull)
+	db.diskMu.Unlock()
+
+	receive(true)
+}
+
+func generateTestChunks(count int) (chunks []chunk.Chunk) {
+	for i := 0; i < count; i++ {
+		chunks = append(chunks, generateTestRandomChunk())
+	}
+	return chunks
+}
+
+func countIndex(t *testing.T, i shed.Index) int {
+	t.Helper()
+
+	count, err := i.Count()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
@@ -0,0 +1,260 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"errors"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethersphere/swarm/chunk"
+)
+
+var (
+	// diskSpaceCheckInterval is the period in which free
+	// disk space is checked.
+	diskSpaceCheckInterval = 30 * time.Second
+	// diskFullRecoverySpace is the minimal free disk space in bytes
+	// required to leave the degraded mode entered because the disk
+	// was full, if it is larger than the configured minimal free
+	// disk space.
+	diskFullRecoverySpace uint64 = 64 * 1024 * 1024
+	// diskFullRetryDelay is the time after which the degraded mode entered
+	// because the disk was full is left, if free disk space can not be
+	// determined. If the disk is still full, the next write enters it again.
+	diskFullRetryDelay = 5 * time.Minute
+	// errDiskSpaceUnsupported is returned by diskFree on platforms
+	// where free disk space can not be determined.
+	errDiskSpaceUnsupported = errors.New("free disk space not supported")
+)
+
+const (
+	degradedReasonDiskFull     = "no space left on device"
+	degradedReasonLowDiskSpace = "free disk space below minimum"
+)
+
+// DiskStatus holds information about the free disk space
+// and whether the database is in degraded mode.
+type DiskStatus struct {
+	// Degraded is true if the database does not accept
+	// chunks received from the network.
+	Degraded bool `json:"degraded"`
+	// Reason describes why the database is in degraded mode.
+	Reason string `json:"reason,omitempty"`
+	// Since is the time when the database entered degraded mode.
+	Since time.Time `json:"since,omitempty"`
+	// FreeSpace is the free disk space in bytes measured
+	// on the last check, if it can be determined.
+	FreeSpace uint64 `json:"freeSpace"`
+	// MinFreeSpace is the configured minimal free disk space.
+	MinFreeSpace uint64 `json:"minFreeSpace"`
+}
+
+// DiskStatus returns information about the free disk space
+// and whether the database is in degraded mode.
+func (db *DB) DiskStatus() DiskStatus {
+	db.diskMu.Lock()
+	defer db.diskMu.Unlock()
+
+	return db.disk
+}
+
+// degraded returns chunk.ErrDiskFull if the database is in degraded mode.
+func (db *DB) degraded() error {
+	db.diskMu.Lock()
+	defer db.diskMu.Unlock()
+
+	if db.disk.Degraded {
+		return chunk.ErrDiskFull
+	}
+	return nil
+}
+
+// setDegraded enters degraded mode with the provided reason,
+// or leaves it if the reason is empty. It must be called
+// with diskMu locked.
+func (db *DB) setDegraded(reason string) {
+	degraded := reason != ""
+	if db.disk.Degraded == degraded && db.disk.Reason == reason {
+		return
+	}
+	if degraded {
+		if !db.disk.Degraded {
+			db.disk.Since = time.Now()
+		}
+		metrics.GetOrRegisterCounter("localstore/degraded", nil).Inc(1)
+		metrics.GetOrRegisterGauge("localstore/degraded/active", nil).Update(1)
+		log.Error("localstore degraded, rejecting chunks from the network", "reason", reason, "free space", db.disk.FreeSpace, "min free space", db.disk.MinFreeSpace)
+	} else {
+		db.disk.Since = time.Time{}
+		metrics.GetOrRegisterGauge("localstore/degraded/active", nil).Update(0)
+		log.Info("localstore recovered from degraded mode", "free space", db.disk.FreeSpace)
+	}
+	db.disk.Degraded = degraded
+	db.disk.Reason = reason
+
+	for _, t := range db.diskTriggers {
+		select {
+		case t <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// SubscribeDiskStatus returns a channel that provides the disk status,
+// first the current one and then every time the database enters or leaves
+// degraded mode, so that operators can be notified about it. If changes
+// happen faster than they are received, only the latest status is provided.
+// Returned stop function terminates the subscription and closes the channel,
+// which is also closed when the database is closed.
+func (db *DB) SubscribeDiskStatus() (c <-chan DiskStatus, stop func()) {
+	statuses := make(chan DiskStatus)
+	trigger := make(chan struct{}, 1)
+
+	db.diskMu.Lock()
+	db.diskTriggers = append(db.diskTriggers, trigger)
+	db.diskMu.Unlock()
+
+	// send signal for the current status
+	trigger <- struct{}{}
+
+	stopChan := make(chan struct{})
+	var stopChanOnce sync.Once
+
+	db.subscritionsWG.Add(1)
+	go func() {
+		defer db.subscritionsWG.Done()
+		defer close(statuses)
+
+		for {
+			select {
+			case <-trigger:
+				select {
+				case statuses <- db.DiskStatus():
+				case <-stopChan:
+					return
+				case <-db.close:
+					return
+				}
+			case <-stopChan:
+				return
+			case <-db.close:
+				return
+			}
+		}
+	}()
+
+	stop = func() {
+		stopChanOnce.Do(func() {
+			close(stopChan)
+		})
+
+		db.diskMu.Lock()
+		defer db.diskMu.Unlock()
+
+		for i, t := range db.diskTriggers {
+			if t == trigger {
+				db.diskTriggers = append(db.diskTriggers[:i], db.diskTriggers[i+1:]...)
+				break
+			}
+		}
+	}
+	return statuses, stop
+}
+
+// checkWriteError enters degraded mode and returns chunk.ErrDiskFull
+// if the error is caused by the full disk. Other errors are returned
+// unchanged. If free disk space can not be determined, a successful
+// write leaves the degraded mode entered because the disk was full.
+func (db *DB) checkWriteError(err error) error {
+	if err == nil {
+		db.diskMu.Lock()
+		defer db.diskMu.Unlock()
+
+		if db.diskFreeUnsupported && db.disk.Reason == degradedReasonDiskFull {
+			db.setDegraded("")
+		}
+		return nil
+	}
+	if !errors.Is(err, syscall.ENOSPC) {
+		return err
+	}
+	db.diskMu.Lock()
+	defer db.diskMu.Unlock()
+
+	db.setDegraded(degradedReasonDiskFull)
+	return chunk.ErrDiskFull
+}
+
+// checkDiskSpace measures the free disk space and enters or leaves
+// degraded mode based on it. If free disk space can not be determined,
+// degraded mode entered because the disk was full is left after
+// diskFullRetryDelay.
+func (db *DB) checkDiskSpace() {
+	free, err := diskFree(db.path)
+	if err != nil {
+		if err != errDiskSpaceUnsupported {
+			log.Error("localstore check free disk space", "err", err)
+			return
+		}
+		db.diskMu.Lock()
+		defer db.diskMu.Unlock()
+
+		db.diskFreeUnsupported = true
+		if db.disk.Reason == degradedReasonDiskFull && time.Since(db.disk.Since) >= diskFullRetryDelay {
+			db.setDegraded("")
+		}
+		return
+	}
+	metrics.GetOrRegisterGauge("localstore/disk/free", nil).Update(int64(free))
+
+	db.diskMu.Lock()
+	defer db.diskMu.Unlock()
+
+	db.disk.FreeSpace = free
+	min := db.disk.MinFreeSpace
+	switch {
+	case free < min:
+		db.setDegraded(degradedReasonLowDiskSpace)
+	case db.disk.Reason == degradedReasonDiskFull && free < diskFullRecoverySpace:
+		// stay in degraded mode until enough space is freed
+	default:
+		db.setDegraded("")
+	}
+}
+
+// diskSpaceWorker periodically checks the free disk
+// space until the database is closed.
+func (db *DB) diskSpaceWorker() {
+	defer close(db.diskSpaceWorkerDone)
+
+	ticker := time.NewTicker(diskSpaceCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		db.checkDiskSpace()
+
+		select {
+		case <-ticker.C:
+		case <-db.close:
+			return
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"context"
+	"math"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/swarm/chunk"
+)
+
+// TestDB_degraded validates that chunks from the network are rejected
+// when free disk space is below the minimum, while uploads, pins
+// and reads are still served.
+func TestDB_degraded(t *testing.T) {
+	if _, err := diskFree("."); err != nil {
+		t.Skip(err)
+	}
+	db, cleanupFunc := newTestDB(t, &Options{
+		MinFreeDiskSpace: math.MaxUint64,
+	})
+	defer cleanupFunc()
+
+	db.checkDiskSpace()
+
+	s := db.DiskStatus()
+	if !s.Degraded {
+		t.Fatal("database not degraded")
+	}
+	if s.Reason != degradedReasonLowDiskSpace {
+		t.Errorf("got reason %q, want %q", s.Reason, degradedReasonLowDiskSpace)
+	}
+
+	for _, mode := range []chunk.ModePut{chunk.ModePutSync, chunk.ModePutRequest} {
+		_, err := db.Put(context.Background(), mode, generateTestRandomChunk())
+		if err != chunk.ErrDiskFull {
+			t.Errorf("put %s: got error %v, want %v", mode, err, chunk.ErrDiskFull)
+		}
+	}
+	uploaded := generateTestRandomChunk()
+	if _, err := db.Put(context.Background(), chunk.ModePutUpload, uploaded); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Set(context.Background(), chunk.ModeSetPin, uploaded.Address()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Get(context.Background(), chunk.ModeGetRequest, uploaded.Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	db.diskMu.Lock()
+	db.disk.MinFreeSpace = 0
+	db.diskMu.Unlock()
+	db.checkDiskSpace()
+
+	if db.DiskStatus().Degraded {
+		t.Fatal("database still degraded")
+	}
+	if _, err := db.Put(context.Background(), chunk.ModePutSync, generateTestRandomChunk()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestDB_SubscribeDiskStatus validates that disk status subscriptions
+// receive the current status and every degraded mode change.
+func TestDB_SubscribeDiskStatus(t *testing.T) {
+	// keep the disk space worker from leaving the degraded mode set by the test
+	defer func(s uint64) { diskFullRecoverySpace = s }(diskFullRecoverySpace)
+	diskFullRecoverySpace = math.MaxUint64
+
+	db, cleanupFunc := newTestDB(t, nil)
+	defer cleanupFunc()
+
+	ch, stop := db.SubscribeDiskStatus()
+	defer stop()
+
+	receive := func(wantDegraded bool) {
+		t.Helper()
+
+		select {
+		case s, ok := <-ch:
+			if !ok {
+				t.Fatal("subscription closed")
+			}
+			if s.Degraded != wantDegraded {
+				t.Errorf("got degraded %v, want %v", s.Degraded, wantDegraded)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("timeout waiting for disk status")
+		}
+	}
+
+	receive(false)
+
+	db.diskMu.Lock()
+	db.setDegraded(degradedReasonDiskFull)
+	db.diskMu.Unlock()
+
+	receive(true)
+
+	db.diskMu.Lock()
+	db.setDegraded("")
+	db.diskMu.Unlock()
+
+	receive(false)
+
+	stop()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatal("subscription not closed")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for subscription to close")
+	}
+}
+
+// TestDB_degradedDiskFreeUnsupported validates that degraded mode entered
+// because the disk was full is left after a successful write when free disk
+// space can not be determined.
+func TestDB_degradedDiskFreeUnsupported(t *testing.T) {
+	db, cleanupFunc := newTestDB(t, nil)
+	defer cleanupFunc()
+
+	db.diskMu.Lock()
+	db.diskFreeUnsupported = true
+	db.diskMu.Unlock()
+
+	if err := db.checkWriteError(syscall.ENOSPC); err != chunk.ErrDiskFull {
+		t.Fatalf("got error %v, want %v", err, chunk.ErrDiskFull)
+	}
+	if !db.DiskStatus().Degraded {
+		t.Fatal("database not degraded")
+	}
+
+	if _, err := db.Put(context.Background(), chunk.ModePutUpload, generateTestRandomChunk()); err != nil {
+		t.Fatal(err)
+	}
+	if db.DiskStatus().Degraded {
+		t.Fatal("database still degraded")
+	}
+}
@@ -52,5 +52,11 @@ Chunks from the database based on their most recent access time.
 Internally, DB stores Chunk data and any required information, such as
 store and access timestamps in different shed indexes that can be
 iterated on by garbage collector or subscriptions.
+
+When built with faultinjection build tag, DB exposes methods to inject
+write errors, not found lookups and delayed batch writes, so that recovery,
+garbage collection and syncing code can be tested against storage errors:
+
+	go test -tags faultinjection ./...
 */
 package localstore
@@ -0,0 +1,118 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+// +build faultinjection
+
+package localstore
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// FaultInjectionEnabled is true when localstore is built
+// with faultinjection build tag. See fault_none.go for more.
+const FaultInjectionEnabled = true
+
+// faults holds the configuration of errors and delays that are
+// injected into database operations. It is configured from tests
+// with DB methods FailNextWrites, SetNotFoundRate, SetCommitDelay
+// and ResetFaults.
+type faults struct {
+	mu sync.Mutex
+	// number of next batch writes that will fail
+	failWrites int
+	// error returned by failed batch writes
+	writeErr error
+	// probability in range [0,1] that a chunk is not found
+	notFoundRate float64
+	// deterministic random source for notFoundRate
+	rand *rand.Rand
+	// duration that every batch write is delayed
+	commitDelay time.Duration
+}
+
+// FailNextWrites makes the next n batch writes fail with the provided error.
+// Failed batches are not written to the database.
+func (db *DB) FailNextWrites(n int, err error) {
+	db.faults.mu.Lock()
+	defer db.faults.mu.Unlock()
+
+	db.faults.failWrites = n
+	db.faults.writeErr = err
+}
+
+// SetNotFoundRate makes Get and GetMulti return chunk.ErrChunkNotFound with
+// the provided probability in range [0,1], regardless if the chunk is stored.
+// Random source is seeded with the provided seed, so that the sequence of
+// injected errors is the same for the same seed.
+func (db *DB) SetNotFoundRate(rate float64, seed int64) {
+	db.faults.mu.Lock()
+	defer db.faults.mu.Unlock()
+
+	db.faults.notFoundRate = rate
+	db.faults.rand = rand.New(rand.NewSource(seed))
+}
+
+// SetCommitDelay delays every batch write by the provided duration.
+func (db *DB) SetCommitDelay(d time.Duration) {
+	db.faults.mu.Lock()
+	defer db.faults.mu.Unlock()
+
+	db.faults.commitDelay = d
+}
+
+// ResetFaults removes all injected faults.
+func (db *DB) ResetFaults() {
+	db.faults.mu.Lock()
+	defer db.faults.mu.Unlock()
+
+	db.faults.failWrites = 0
+	db.faults.writeErr = nil
+	db.faults.notFoundRate = 0
+	db.faults.rand = nil
+	db.faults.commitDelay = 0
+}
+
+// writeBatch delays the batch write and returns
+// an error if the write should fail.
+func (f *faults) writeBatch() (err error) {
+	f.mu.Lock()
+	delay := f.commitDelay
+	if f.failWrites > 0 {
+		f.failWrites--
+		err = f.writeErr
+	}
+	f.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	return err
+}
+
+// notFound returns true if a chunk lookup
+// should return a not found error.
+func (f *faults) notFound() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.notFoundRate <= 0 || f.rand == nil {
+		return false
+	}
+	return f.rand.Float64() < f.notFoundRate
+}
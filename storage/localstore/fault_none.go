@@ -0,0 +1,32 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+// +build !faultinjection
+
+package localstore
+
+// FaultInjectionEnabled is false when localstore is built without
+// faultinjection build tag. In that case, no faults are injected
+// and methods that configure them are not available.
+const FaultInjectionEnabled = false
+
+// faults is an empty placeholder which methods
+// do not inject any errors or delays.
+type faults struct{}
+
+func (faults) writeBatch() error { return nil }
+
+func (faults) notFound() bool { return false }
@@ -0,0 +1,168 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+// +build faultinjection
+
+package localstore
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/swarm/chunk"
+)
+
+// TestFailNextWrites validates that the configured number of batch
+// writes fail and that chunks from failed batches are not stored.
+func TestFailNextWrites(t *testing.T) {
+	db, cleanupFunc := newTestDB(t, nil)
+	defer cleanupFunc()
+
+	errWrite := errors.New("write fault")
+	db.FailNextWrites(2, errWrite)
+
+	for i := 0; i < 2; i++ {
+		ch := generateTestRandomChunk()
+		_, err := db.Put(context.Background(), chunk.ModePutUpload, ch)
+		if err != errWrite {
+			t.Fatalf("got error %v, want %v", err, errWrite)
+		}
+		has, err := db.Has(context.Background(), ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if has {
+			t.Error("chunk from a failed batch is stored")
+		}
+	}
+
+	ch := generateTestRandomChunk()
+	if _, err := db.Put(context.Background(), chunk.ModePutUpload, ch); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Get(context.Background(), chunk.ModeGetRequest, ch.Address()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSetNotFoundRate validates that lookups of stored chunks
+// fail deterministically for the same seed.
+func TestSetNotFoundRate(t *testing.T) {
+	db, cleanupFunc := newTestDB(t, nil)
+	defer cleanupFunc()
+
+	ch := generateTestRandomChunk()
+	if _, err := db.Put(context.Background(), chunk.ModePutUpload, ch); err != nil {
+		t.Fatal(err)
+	}
+
+	lookups := func() (results []bool) {
+		for i := 0; i < 100; i++ {
+			_, err := db.Get(context.Background(), chunk.ModeGetLookup, ch.Address())
+			switch err {
+			case nil:
+				results = append(results, true)
+			case chunk.ErrChunkNotFound:
+				results = append(results, false)
+			default:
+				t.Fatal(err)
+			}
+		}
+		return results
+	}
+
+	db.SetNotFoundRate(0.5, 42)
+	first := lookups()
+	db.SetNotFoundRate(0.5, 42)
+	second := lookups()
+
+	var notFound int
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("lookup %v differs for the same seed", i)
+		}
+		if !first[i] {
+			notFound++
+		}
+	}
+	if notFound == 0 || notFound == len(first) {
+		t.Errorf("got %v not found lookups out of %v", notFound, len(first))
+	}
+
+	db.ResetFaults()
+	if _, err := db.GetMulti(context.Background(), chunk.ModeGetLookup, ch.Address()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSetCommitDelay validates that batch writes are delayed.
+func TestSetCommitDelay(t *testing.T) {
+	db, cleanupFunc := newTestDB(t, nil)
+	defer cleanupFunc()
+
+	delay := 100 * time.Millisecond
+	db.SetCommitDelay(delay)
+
+	start := time.Now()
+	if _, err := db.Put(context.Background(), chunk.ModePutUpload, generateTestRandomChunk()); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < delay {
+		t.Errorf("put took %s, want at least %s", d, delay)
+	}
+}
+
+// TestFailNextWrites_diskFull validates that a write failing because
+// the disk is full returns chunk.ErrDiskFull and that chunks from the
+// network are rejected afterwards.
+func TestFailNextWrites_diskFull(t *testing.T) {
+	defer func(s uint64) { diskFullRecoverySpace = s }(diskFullRecoverySpace)
+	diskFullRecoverySpace = math.MaxUint64
+
+	db, cleanupFunc := newTestDB(t, nil)
+	defer cleanupFunc()
+
+	db.FailNextWrites(1, &os.PathError{Op: "write", Path: "000001.log", Err: syscall.ENOSPC})
+
+	_, err := db.Put(context.Background(), chunk.ModePutUpload, generateTestRandomChunk())
+	if err != chunk.ErrDiskFull {
+		t.Fatalf("got error %v, want %v", err, chunk.ErrDiskFull)
+	}
+	if r := db.DiskStatus().Reason; r != degradedReasonDiskFull {
+		t.Errorf("got reason %q, want %q", r, degradedReasonDiskFull)
+	}
+	_, err = db.Put(context.Background(), chunk.ModePutSync, generateTestRandomChunk())
+	if err != chunk.ErrDiskFull {
+		t.Fatalf("got error %v, want %v", err, chunk.ErrDiskFull)
+	}
+
+	// free disk space is not enough to recover
+	db.checkDiskSpace()
+	if !db.DiskStatus().Degraded {
+		t.Fatal("database not degraded")
+	}
+
+	diskFullRecoverySpace = 0
+	db.checkDiskSpace()
+	if db.DiskStatus().Degraded {
+		t.Fatal("database still degraded")
+	}
+}
@@ -21,22 +21,25 @@ import (
 
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethersphere/swarm/chunk"
 	"github.com/ethersphere/swarm/shed"
 	"github.com/syndtr/goleveldb/leveldb"
 )
 
 var (
-	// gcTargetRatio defines the target number of items
+	// gcTargetRatio defines the default target number of items
 	// in garbage collection index that will not be removed
 	// on garbage collection. The target number of items
 	// is calculated by gcTarget function. This value must be
 	// in range (0,1]. For example, with 0.9 value,
 	// garbage collection will leave 90% of defined capacity
 	// in database after its run. This prevents frequent
-	// garbage collection runs.
+	// garbage collection runs. It can be changed for a DB
+	// with the GCTargetRatio option.
 	gcTargetRatio = 0.9
-	// gcBatchSize limits the number of chunks in a single
-	// leveldb batch on garbage collection.
+	// gcBatchSize is the initial limit of the number of chunks
+	// in a single leveldb batch on garbage collection. The limit
+	// is adapted to the database load by gcBatchSizer.
 	gcBatchSize uint64 = 200
 )
 
@@ -47,11 +50,18 @@ var (
 func (db *DB) collectGarbageWorker() {
 	defer close(db.collectGarbageWorkerDone)
 
+	// gcSize may not be valid until the health check is done
+	select {
+	case <-db.ready:
+	case <-db.close:
+		return
+	}
+
 	for {
 		select {
 		case <-db.collectGarbageTrigger:
 			// run a single collect garbage run and
-			// if done is false, gcBatchSize is reached and
+			// if done is false, gc batch size is reached and
 			// another collect garbage run is needed
 			collectedCount, done, err := db.collectGarbage()
 			if err != nil {
@@ -76,6 +86,7 @@ func (db *DB) collectGarbageWorker() {
 // This function returns the number of removed chunks. If done
 // is false, another call to this function is needed to collect
 // the rest of the garbage as the batch size limit is reached.
+// Chunks without postage stamps are collected before chunks with them.
 // This function is called in collectGarbageWorker.
 func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 	metricName := "localstore/gc"
@@ -89,6 +100,7 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 
 	batch := new(leveldb.Batch)
 	target := db.gcTarget()
+	batchSize := db.gcBatchSizer.batchSize()
 
 	// protect database from changing idexes and gcSize
 	db.batchMu.Lock()
@@ -109,23 +121,23 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 	metrics.GetOrRegisterGauge(metricName+"/gcsize", nil).Update(int64(gcSize))
 
 	done = true
-	err = db.gcIndex.Iterate(func(item shed.Item) (stop bool, err error) {
-		if gcSize-collectedCount <= target {
+	depth := db.gcResponsibilityDepth()
+	metrics.GetOrRegisterGauge(metricName+"/depth", nil).Update(int64(depth))
+
+	// chunks that are no longer in the area of responsibility
+	// after the depth increased are at the start of gcReserveIndex,
+	// move them back to gcIndex
+	var movedCount uint64
+	// number of collected chunks with postage stamps
+	var stampedCount uint64
+	err = db.gcReserveIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		if int(db.po(item.Address)) >= depth {
 			return true, nil
 		}
-
-		metrics.GetOrRegisterGauge(metricName+"/storets", nil).Update(item.StoreTimestamp)
-		metrics.GetOrRegisterGauge(metricName+"/accessts", nil).Update(item.AccessTimestamp)
-
-		// delete from retrieve, pull, gc
-		db.retrievalDataIndex.DeleteInBatch(batch, item)
-		db.retrievalAccessIndex.DeleteInBatch(batch, item)
-		db.pullIndex.DeleteInBatch(batch, item)
-		db.gcIndex.DeleteInBatch(batch, item)
-		collectedCount++
-		if collectedCount >= gcBatchSize {
-			// bach size limit reached,
-			// another gc run is needed
+		db.gcReserveIndex.DeleteInBatch(batch, item)
+		db.gcIndex.PutInBatch(batch, item)
+		movedCount++
+		if movedCount >= batchSize {
 			done = false
 			return true, nil
 		}
@@ -134,15 +146,129 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 	if err != nil {
 		return 0, false, err
 	}
+
+	// chunks with postage stamps are collected in the second pass,
+	// only if there are not enough chunks without them to reach the target
+	for _, collectStamped := range []bool{false, true} {
+		if !done || gcSize-collectedCount <= target {
+			break
+		}
+		err = db.gcIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			if gcSize-collectedCount <= target {
+				return true, nil
+			}
+
+			metrics.GetOrRegisterGauge(metricName+"/storets", nil).Update(item.StoreTimestamp)
+			metrics.GetOrRegisterGauge(metricName+"/accessts", nil).Update(item.AccessTimestamp)
+
+			if int(db.po(item.Address)) >= depth {
+				if collectStamped {
+					// already moved to gcReserveIndex in the first pass
+					return false, nil
+				}
+				// chunk is in the area of responsibility after the depth
+				// decreased or it is newly added, move it to gcReserveIndex
+				db.gcIndex.DeleteInBatch(batch, item)
+				db.gcReserveIndex.PutInBatch(batch, item)
+				movedCount++
+			} else {
+				stamped, err := db.stampIndex.Has(item)
+				if err != nil {
+					return true, err
+				}
+				if stamped != collectStamped {
+					// collected in the other pass
+					return false, nil
+				}
+				// delete from retrieve, pull, gc, stamp, block header
+				db.retrievalDataIndex.DeleteInBatch(batch, item)
+				db.retrievalAccessIndex.DeleteInBatch(batch, item)
+				db.pullIndex.DeleteInBatch(batch, item)
+				db.gcIndex.DeleteInBatch(batch, item)
+				if stamped {
+					db.stampIndex.DeleteInBatch(batch, item)
+					stampedCount++
+				}
+				if err := db.deleteBlockHeaderInBatch(batch, item); err != nil {
+					return true, err
+				}
+				collectedCount++
+			}
+			if collectedCount+movedCount >= batchSize {
+				// bach size limit reached,
+				// another gc run is needed
+				done = false
+				return true, nil
+			}
+			return false, nil
+		}, nil)
+		if err != nil {
+			return 0, false, err
+		}
+	}
+
+	// collect chunks in the area of responsibility only if
+	// there are no other chunks left to reach the target,
+	// also chunks without postage stamps first
+	if done && gcSize-collectedCount > target {
+		for _, collectStamped := range []bool{false, true} {
+			if !done || gcSize-collectedCount <= target {
+				break
+			}
+			err = db.gcReserveIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+				if gcSize-collectedCount <= target {
+					return true, nil
+				}
+				if int(db.po(item.Address)) < depth {
+					// already moved to gcIndex in this batch
+					return false, nil
+				}
+				stamped, err := db.stampIndex.Has(item)
+				if err != nil {
+					return true, err
+				}
+				if stamped != collectStamped {
+					// collected in the other pass
+					return false, nil
+				}
+
+				// delete from retrieve, pull, gc reserve, stamp, block header
+				db.retrievalDataIndex.DeleteInBatch(batch, item)
+				db.retrievalAccessIndex.DeleteInBatch(batch, item)
+				db.pullIndex.DeleteInBatch(batch, item)
+				db.gcReserveIndex.DeleteInBatch(batch, item)
+				if stamped {
+					db.stampIndex.DeleteInBatch(batch, item)
+					stampedCount++
+				}
+				if err := db.deleteBlockHeaderInBatch(batch, item); err != nil {
+					return true, err
+				}
+				collectedCount++
+				if collectedCount+movedCount >= batchSize {
+					done = false
+					return true, nil
+				}
+				return false, nil
+			}, nil)
+			if err != nil {
+				return 0, false, err
+			}
+		}
+		metrics.GetOrRegisterCounter(metricName+"/reserve", nil).Inc(1)
+	}
+	metrics.GetOrRegisterCounter(metricName+"/moved-count", nil).Inc(int64(movedCount))
 	metrics.GetOrRegisterCounter(metricName+"/collected-count", nil).Inc(int64(collectedCount))
+	metrics.GetOrRegisterCounter(metricName+"/collected-stamped-count", nil).Inc(int64(stampedCount))
 
 	db.gcSize.PutInBatch(batch, gcSize-collectedCount)
 
-	err = db.shed.WriteBatch(batch)
+	err = db.writeBatch(batch)
 	if err != nil {
 		metrics.GetOrRegisterCounter(metricName+"/writebatch/err", nil).Inc(1)
 		return 0, false, err
 	}
+	db.gcBatchSizer.adjust(!done)
 	return collectedCount, done, nil
 }
 
@@ -175,13 +301,14 @@ func (db *DB) removeChunksInExcludeIndexFromGC() (err error) {
 		}
 		item.BinID = retrievalDataIndexItem.BinID
 
-		// Check if this item is in gcIndex and remove it
-		ok, err := db.gcIndex.Has(item)
+		// Check if this item is in gcIndex or gcReserveIndex and remove it
+		ok, err := db.gcHas(item)
+		if err != nil {
+			return false, err
+		}
 		if ok {
-			db.gcIndex.DeleteInBatch(batch, item)
-			if _, err := db.gcIndex.Get(item); err == nil {
-				gcSizeChange--
-			}
+			db.deleteGCInBatch(batch, item)
+			gcSizeChange--
 			excludedCount++
 			db.gcExcludeIndex.DeleteInBatch(batch, item)
 		}
@@ -199,7 +326,7 @@ func (db *DB) removeChunksInExcludeIndexFromGC() (err error) {
 	}
 
 	metrics.GetOrRegisterCounter(metricName+"/excluded-count", nil).Inc(int64(excludedCount))
-	err = db.shed.WriteBatch(batch)
+	err = db.writeBatch(batch)
 	if err != nil {
 		metrics.GetOrRegisterCounter(metricName+"/writebatch/err", nil).Inc(1)
 		return err
@@ -208,10 +335,41 @@ func (db *DB) removeChunksInExcludeIndexFromGC() (err error) {
 	return nil
 }
 
+// gcResponsibilityDepth returns the proximity order from which
+// chunks are in the area of responsibility and are kept in
+// gcReserveIndex. If ResponsibilityDepth option is not set,
+// no chunks are in the area of responsibility.
+func (db *DB) gcResponsibilityDepth() int {
+	if db.responsibilityDepth == nil {
+		return chunk.MaxPO + 1
+	}
+	depth := db.responsibilityDepth()
+	if depth < 0 {
+		return 0
+	}
+	return depth
+}
+
+// deleteGCInBatch removes the item from gcIndex and gcReserveIndex.
+// Item must have Address, AccessTimestamp and BinID fields set.
+func (db *DB) deleteGCInBatch(batch *leveldb.Batch, item shed.Item) {
+	db.gcIndex.DeleteInBatch(batch, item)
+	db.gcReserveIndex.DeleteInBatch(batch, item)
+}
+
+// gcHas returns true if the item is in gcIndex or gcReserveIndex.
+func (db *DB) gcHas(item shed.Item) (bool, error) {
+	ok, err := db.gcIndex.Has(item)
+	if err != nil || ok {
+		return ok, err
+	}
+	return db.gcReserveIndex.Has(item)
+}
+
 // gcTrigger retruns the absolute value for garbage collection
-// target value, calculated from db.capacity and gcTargetRatio.
+// target value, calculated from db.capacity and db.gcTargetRatio.
 func (db *DB) gcTarget() (target uint64) {
-	return uint64(float64(db.capacity) * gcTargetRatio)
+	return uint64(float64(db.capacity) * db.gcTargetRatio)
 }
 
 // triggerGarbageCollection signals collectGarbageWorker
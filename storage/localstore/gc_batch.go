@@ -0,0 +1,138 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	// gcMinBatchSize and gcMaxBatchSize are the bounds for
+	// the adaptive number of chunks in a single gc batch.
+	gcMinBatchSize uint64 = 20
+	gcMaxBatchSize uint64 = 5000
+	// gcTargetWriteLatency is the leveldb batch write duration
+	// above which the gc batch size is decreased to leave more
+	// write capacity to the incoming chunks.
+	gcTargetWriteLatency = 50 * time.Millisecond
+	// gcBusyPutRate is the number of chunks put per second
+	// above which the gc batch size is decreased.
+	gcBusyPutRate = 1000.0
+	// gcIdlePutRate is the number of chunks put per second
+	// below which the gc batch size is increased.
+	gcIdlePutRate = 100.0
+	// gcWriteLatencyWeight is the weight of the most recent
+	// write duration in exponential moving average.
+	gcWriteLatencyWeight = 0.2
+)
+
+// gcBatchSizer adapts the number of chunks removed in a single
+// garbage collection batch to the observed leveldb write latency
+// and the rate of incoming chunks. The batch size is halved when
+// the database is under load, and doubled when it is idle.
+type gcBatchSizer struct {
+	mu           sync.Mutex
+	size         uint64
+	min, max     uint64
+	writeLatency float64 // exponential moving average in nanoseconds
+	puts         uint64  // number of chunks put since the last adjustment
+	lastAdjust   time.Time
+}
+
+// newGCBatchSizer returns a new gcBatchSizer that starts with the initial
+// batch size and keeps it in range [min, max]. If the initial size is lower
+// than the minimum, it is used as the minimum.
+func newGCBatchSizer(initial, min, max uint64) *gcBatchSizer {
+	if initial < min {
+		min = initial
+	}
+	if initial > max {
+		max = initial
+	}
+	return &gcBatchSizer{
+		size:       initial,
+		min:        min,
+		max:        max,
+		lastAdjust: time.Now(),
+	}
+}
+
+// batchSize returns the current gc batch size.
+func (s *gcBatchSizer) batchSize() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.size
+}
+
+// observeWrite updates the write latency moving average
+// with the duration of a single leveldb batch write.
+func (s *gcBatchSizer) observeWrite(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.writeLatency == 0 {
+		s.writeLatency = float64(d)
+		return
+	}
+	s.writeLatency = gcWriteLatencyWeight*float64(d) + (1-gcWriteLatencyWeight)*s.writeLatency
+}
+
+// observePut records the number of chunks put to the database.
+func (s *gcBatchSizer) observePut(count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.puts += uint64(count)
+}
+
+// adjust changes the batch size after a gc run. Argument full
+// should be true if the last gc run reached the batch size limit,
+// as only in that case a larger batch would collect more chunks.
+func (s *gcBatchSizer) adjust(full bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var putRate float64
+	if elapsed := time.Since(s.lastAdjust); elapsed > 0 {
+		putRate = float64(s.puts) / elapsed.Seconds()
+	}
+	s.puts = 0
+	s.lastAdjust = time.Now()
+
+	latency := time.Duration(s.writeLatency)
+	switch {
+	case latency > gcTargetWriteLatency || putRate > gcBusyPutRate:
+		s.size /= 2
+		if s.size < s.min {
+			s.size = s.min
+		}
+	case full && latency < gcTargetWriteLatency/2 && putRate < gcIdlePutRate:
+		s.size *= 2
+		if s.size > s.max {
+			s.size = s.max
+		}
+	}
+
+	metricName := "localstore/gc/batch"
+	metrics.GetOrRegisterGauge(metricName+"/size", nil).Update(int64(s.size))
+	metrics.GetOrRegisterGauge(metricName+"/writelatency", nil).Update(int64(latency))
+	metrics.GetOrRegisterGaugeFloat64(metricName+"/putrate", nil).Update(putRate)
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"testing"
+	"time"
+)
+
+// TestGCBatchSizer validates that gc batch size is decreased under load,
+// increased when idle and kept in configured bounds.
+func TestGCBatchSizer(t *testing.T) {
+	t.Run("shrink on write latency", func(t *testing.T) {
+		s := newGCBatchSizer(200, 20, 5000)
+
+		s.observeWrite(2 * gcTargetWriteLatency)
+		s.adjust(true)
+		if got := s.batchSize(); got != 100 {
+			t.Errorf("got batch size %v, want %v", got, 100)
+		}
+
+		for i := 0; i < 10; i++ {
+			s.adjust(true)
+		}
+		if got := s.batchSize(); got != 20 {
+			t.Errorf("got batch size %v, want %v", got, 20)
+		}
+	})
+
+	t.Run("shrink on put rate", func(t *testing.T) {
+		s := newGCBatchSizer(200, 20, 5000)
+
+		s.lastAdjust = time.Now().Add(-time.Second)
+		s.observePut(int(2 * gcBusyPutRate))
+		s.adjust(true)
+		if got := s.batchSize(); got != 100 {
+			t.Errorf("got batch size %v, want %v", got, 100)
+		}
+	})
+
+	t.Run("grow when idle", func(t *testing.T) {
+		s := newGCBatchSizer(200, 20, 500)
+
+		s.observeWrite(time.Millisecond)
+		s.adjust(true)
+		if got := s.batchSize(); got != 400 {
+			t.Errorf("got batch size %v, want %v", got, 400)
+		}
+
+		s.adjust(true)
+		if got := s.batchSize(); got != 500 {
+			t.Errorf("got batch size %v, want %v", got, 500)
+		}
+	})
+
+	t.Run("keep when not full", func(t *testing.T) {
+		s := newGCBatchSizer(200, 20, 5000)
+
+		s.observeWrite(time.Millisecond)
+		s.adjust(false)
+		if got := s.batchSize(); got != 200 {
+			t.Errorf("got batch size %v, want %v", got, 200)
+		}
+	})
+
+	t.Run("initial below minimum", func(t *testing.T) {
+		s := newGCBatchSizer(2, 20, 5000)
+
+		s.observeWrite(2 * gcTargetWriteLatency)
+		s.adjust(true)
+		if got := s.batchSize(); got != 2 {
+			t.Errorf("got batch size %v, want %v", got, 2)
+		}
+	})
+}
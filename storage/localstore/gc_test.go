@@ -22,6 +22,7 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"os"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -32,7 +33,9 @@ import (
 // TestDB_collectGarbageWorker tests garbage collection runs
 // by uploading and syncing a number of chunks.
 func TestDB_collectGarbageWorker(t *testing.T) {
-	testDBCollectGarbageWorker(t)
+	testDBCollectGarbageWorker(t, &Options{
+		Capacity: 100,
+	})
 }
 
 // TestDB_collectGarbageWorker_multipleBatches tests garbage
@@ -40,22 +43,49 @@ func TestDB_collectGarbageWorker(t *testing.T) {
 // chunks by having multiple smaller batches.
 func TestDB_collectGarbageWorker_multipleBatches(t *testing.T) {
 	// lower the maximal number of chunks in a single
-	// gc batch to ensure multiple batches.
-	defer func(s uint64) { gcBatchSize = s }(gcBatchSize)
-	gcBatchSize = 2
+	// gc batch and keep it fixed to ensure multiple batches.
+	defer func(s, min, max uint64) {
+		gcBatchSize, gcMinBatchSize, gcMaxBatchSize = s, min, max
+	}(gcBatchSize, gcMinBatchSize, gcMaxBatchSize)
+	gcBatchSize, gcMinBatchSize, gcMaxBatchSize = 10, 10, 10
+
+	collected := testDBCollectGarbageWorker(t, &Options{
+		Capacity: 100,
+	})
+
+	var fullBatches int
+	for _, c := range collected {
+		if c > gcBatchSize {
+			t.Errorf("collected %v chunks in a batch larger than %v", c, gcBatchSize)
+		}
+		if c == gcBatchSize {
+			fullBatches++
+		}
+	}
+	// 60 chunks are collected from 150 chunks
+	// with capacity 100 and gc target 90
+	if fullBatches < 3 {
+		t.Errorf("got %v full gc batches, want at least %v", fullBatches, 3)
+	}
+}
 
-	testDBCollectGarbageWorker(t)
+// TestDB_collectGarbageWorker_targetRatio tests garbage
+// collection runs with a custom garbage collection target ratio.
+func TestDB_collectGarbageWorker_targetRatio(t *testing.T) {
+	testDBCollectGarbageWorker(t, &Options{
+		Capacity:      100,
+		GCTargetRatio: 0.5,
+	})
 }
 
 // testDBCollectGarbageWorker is a helper test function to test
 // garbage collection runs by uploading and syncing a number of chunks.
-func testDBCollectGarbageWorker(t *testing.T) {
+// It returns the number of chunks collected in every gc run.
+func testDBCollectGarbageWorker(t *testing.T, o *Options) (collected []uint64) {
 
 	chunkCount := 150
 
-	db, cleanupFunc := newTestDB(t, &Options{
-		Capacity: 100,
-	})
+	db, cleanupFunc := newTestDB(t, o)
 	testHookCollectGarbageChan := make(chan uint64)
 	defer setTestHookCollectGarbage(func(collectedCount uint64) {
 		select {
@@ -86,10 +116,18 @@ func testDBCollectGarbageWorker(t *testing.T) {
 	}
 
 	gcTarget := db.gcTarget()
+	wantTarget := uint64(float64(o.Capacity) * gcTargetRatio)
+	if o.GCTargetRatio > 0 {
+		wantTarget = uint64(float64(o.Capacity) * o.GCTargetRatio)
+	}
+	if gcTarget != wantTarget {
+		t.Fatalf("got gc target %v, want %v", gcTarget, wantTarget)
+	}
 
 	for {
 		select {
-		case <-testHookCollectGarbageChan:
+		case c := <-testHookCollectGarbageChan:
+			collected = append(collected, c)
 		case <-time.After(10 * time.Second):
 			t.Error("collect garbage timeout")
 		}
@@ -132,6 +170,8 @@ func testDBCollectGarbageWorker(t *testing.T) {
 			t.Fatal(err)
 		}
 	})
+
+	return collected
 }
 
 // Pin a file, upload chunks to go past the gc limit to trigger GC,
@@ -421,6 +461,109 @@ func TestDB_collectGarbageWorker_withRequests(t *testing.T) {
 	})
 }
 
+// TestDB_collectGarbageWorker_responsibility validates that chunks
+// within the area of responsibility are not garbage collected while
+// there are other chunks to collect, and that they are reclassified
+// when the depth changes.
+func TestDB_collectGarbageWorker_responsibility(t *testing.T) {
+	chunkCount := 150
+
+	var depth int64 = 2
+	db, cleanupFunc := newTestDB(t, &Options{
+		Capacity: 100,
+		ResponsibilityDepth: func() int {
+			return int(atomic.LoadInt64(&depth))
+		},
+	})
+	testHookCollectGarbageChan := make(chan uint64)
+	defer setTestHookCollectGarbage(func(collectedCount uint64) {
+		select {
+		case testHookCollectGarbageChan <- collectedCount:
+		case <-db.close:
+		}
+	})()
+	defer cleanupFunc()
+
+	gcTarget := db.gcTarget()
+
+	upload := func(count int) (addrs []chunk.Address) {
+		for i := 0; i < count; i++ {
+			ch := generateTestRandomChunk()
+
+			_, err := db.Put(context.Background(), chunk.ModePutUpload, ch)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = db.Set(context.Background(), chunk.ModeSetSyncPull, ch.Address())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			addrs = append(addrs, ch.Address())
+		}
+		return addrs
+	}
+	waitGC := func() {
+		for {
+			select {
+			case <-testHookCollectGarbageChan:
+			case <-time.After(10 * time.Second):
+				t.Fatal("collect garbage timeout")
+			}
+			gcSize, err := db.gcSize.Get()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gcSize == gcTarget {
+				return
+			}
+		}
+	}
+
+	addrs := upload(chunkCount)
+	waitGC()
+
+	t.Run("gc size", newIndexGCSizeTest(db))
+
+	for _, a := range addrs {
+		if db.po(a) < 2 {
+			continue
+		}
+		_, err := db.Get(context.Background(), chunk.ModeGetLookup, a)
+		if err != nil {
+			t.Errorf("chunk %s with po %v in the area of responsibility: %v", a, db.po(a), err)
+		}
+	}
+
+	t.Run("gc reserve index", func(t *testing.T) {
+		var count int
+		err := db.gcReserveIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			if po := db.po(item.Address); po < 2 {
+				t.Errorf("chunk %x with po %v in gc reserve index", item.Address, po)
+			}
+			count++
+			return false, nil
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count == 0 {
+			t.Error("no chunks in gc reserve index")
+		}
+	})
+
+	// no chunks are in the area of responsibility after the depth change
+	atomic.StoreInt64(&depth, chunk.MaxPO+1)
+
+	upload(int(db.capacity - gcTarget))
+	waitGC()
+
+	t.Run("gc reserve index count after depth change", newItemsCountTest(db.gcReserveIndex, 0))
+
+	t.Run("gc size after depth change", newIndexGCSizeTest(db))
+}
+
 // TestDB_gcSize checks if gcSize has a correct value after
 // database is initialized with existing data.
 func TestDB_gcSize(t *testing.T) {
@@ -0,0 +1,132 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/shed"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// BlockHeader associates the address of a stored block header chunk
+// with the block number of the header.
+type BlockHeader struct {
+	Address chunk.Address
+	Number  uint64
+}
+
+// PutBlockHeaders indexes stored block header chunks by their block
+// numbers. Indexed headers are removed from the indexes when their chunks
+// are garbage collected or removed. Headers of chunks that are not stored
+// are not indexed and chunk.ErrChunkNotFound is returned.
+func (db *DB) PutBlockHeaders(headers ...BlockHeader) (err error) {
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	batch := new(leveldb.Batch)
+	for _, h := range headers {
+		item := addressToItem(h.Address)
+		has, err := db.retrievalDataIndex.Has(item)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return chunk.ErrChunkNotFound
+		}
+		item.BinID = h.Number
+		db.headerNumberIndex.PutInBatch(batch, item)
+		db.headerHashIndex.PutInBatch(batch, item)
+	}
+	return db.writeBatch(batch)
+}
+
+// GetBlockHeader returns the data of the indexed block header chunk
+// without updating its access timestamp, so that serving headers does not
+// affect the garbage collection ordering of other chunks. If the header is
+// not indexed, chunk.ErrChunkNotFound is returned.
+func (db *DB) GetBlockHeader(addr chunk.Address) (data []byte, err error) {
+	item := addressToItem(addr)
+	has, err := db.headerHashIndex.Has(item)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, chunk.ErrChunkNotFound
+	}
+	item, err = db.retrievalDataIndex.Get(item)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return nil, chunk.ErrChunkNotFound
+		}
+		return nil, err
+	}
+	return item.Data, nil
+}
+
+// HasBlockHeader returns true if the block header chunk is indexed.
+func (db *DB) HasBlockHeader(addr chunk.Address) (bool, error) {
+	return db.headerHashIndex.Has(addressToItem(addr))
+}
+
+// IterateBlockHeaders calls fn for every indexed block header with block
+// number in the inclusive range from-to, ordered by block number. If there
+// are multiple headers for the same block number, all of them are passed to
+// fn. Iteration stops if fn returns true or an error.
+func (db *DB) IterateBlockHeaders(from, to uint64, fn func(h BlockHeader, data []byte) (stop bool, err error)) error {
+	if from > to {
+		return nil
+	}
+	return db.headerNumberIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		if item.BinID > to {
+			return true, nil
+		}
+		i, err := db.retrievalDataIndex.Get(item)
+		if err != nil {
+			if err == leveldb.ErrNotFound {
+				// removed after the iteration started
+				return false, nil
+			}
+			return true, err
+		}
+		return fn(BlockHeader{
+			Address: item.Address,
+			Number:  item.BinID,
+		}, i.Data)
+	}, &shed.IterateOptions{
+		StartFrom: &shed.Item{
+			BinID: from,
+		},
+	})
+}
+
+// deleteBlockHeaderInBatch removes the item from block header
+// indexes if it is indexed.
+// Provided batch is updated.
+func (db *DB) deleteBlockHeaderInBatch(batch *leveldb.Batch, item shed.Item) (err error) {
+	// only the address is passed as other item fields
+	// would be merged into the decoded block number
+	i, err := db.headerHashIndex.Get(addressToItem(item.Address))
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+	db.headerNumberIndex.DeleteInBatch(batch, i)
+	db.headerHashIndex.DeleteInBatch(batch, i)
+	return nil
+}
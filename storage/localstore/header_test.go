@@ -0,0 +1,158 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/swarm/chunk"
+)
+
+// TestDB_BlockHeaders validates that block header chunks are indexed
+// by block numbers and removed from indexes with removed chunks.
+func TestDB_BlockHeaders(t *testing.T) {
+	db, cleanupFunc := newTestDB(t, nil)
+	defer cleanupFunc()
+
+	// index headers in reverse order to validate range ordering
+	chunks := generateTestRandomChunks(10)
+	if _, err := db.Put(context.Background(), chunk.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+	var headers []BlockHeader
+	for i := range chunks {
+		headers = append(headers, BlockHeader{
+			Address: chunks[i].Address(),
+			Number:  uint64((9 - i) * 1000),
+		})
+	}
+	if err := db.PutBlockHeaders(headers...); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ch := range chunks {
+		data, err := db.GetBlockHeader(ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, ch.Data()) {
+			t.Errorf("got header data %x, want %x", data, ch.Data())
+		}
+	}
+
+	var numbers []uint64
+	err := db.IterateBlockHeaders(2500, 6000, func(h BlockHeader, data []byte) (stop bool, err error) {
+		numbers = append(numbers, h.Number)
+		return false, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []uint64{3000, 4000, 5000, 6000}
+	if len(numbers) != len(want) {
+		t.Fatalf("got block numbers %v, want %v", numbers, want)
+	}
+	for i := range want {
+		if numbers[i] != want[i] {
+			t.Fatalf("got block numbers %v, want %v", numbers, want)
+		}
+	}
+
+	t.Run("not stored", func(t *testing.T) {
+		err := db.PutBlockHeaders(BlockHeader{
+			Address: generateTestRandomChunk().Address(),
+			Number:  1,
+		})
+		if err != chunk.ErrChunkNotFound {
+			t.Errorf("got error %v, want %v", err, chunk.ErrChunkNotFound)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		addr := chunks[0].Address()
+		if err := db.Set(context.Background(), chunk.ModeSetRemove, addr); err != nil {
+			t.Fatal(err)
+		}
+		_, err := db.GetBlockHeader(addr)
+		if err != chunk.ErrChunkNotFound {
+			t.Errorf("got error %v, want %v", err, chunk.ErrChunkNotFound)
+		}
+		t.Run("header number index count", newItemsCountTest(db.headerNumberIndex, len(chunks)-1))
+		t.Run("header hash index count", newItemsCountTest(db.headerHashIndex, len(chunks)-1))
+	})
+}
+
+// TestDB_BlockHeaders_gc validates that block header indexes
+// are pruned when header chunks are garbage collected.
+func TestDB_BlockHeaders_gc(t *testing.T) {
+	db, cleanupFunc := newTestDB(t, &Options{
+		Capacity: 100,
+	})
+	testHookCollectGarbageChan := make(chan uint64)
+	defer setTestHookCollectGarbage(func(collectedCount uint64) {
+		select {
+		case testHookCollectGarbageChan <- collectedCount:
+		case <-db.close:
+		}
+	})()
+	defer cleanupFunc()
+
+	chunks := generateTestRandomChunks(150)
+	for i, ch := range chunks {
+		if _, err := db.Put(context.Background(), chunk.ModePutRequest, ch); err != nil {
+			t.Fatal(err)
+		}
+		err := db.PutBlockHeaders(BlockHeader{
+			Address: ch.Address(),
+			Number:  uint64(i),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gcTarget := db.gcTarget()
+	for {
+		select {
+		case <-testHookCollectGarbageChan:
+		case <-time.After(10 * time.Second):
+			t.Fatal("collect garbage timeout")
+		}
+		gcSize, err := db.gcSize.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gcSize == gcTarget {
+			break
+		}
+	}
+
+	t.Run("header number index count", newItemsCountTest(db.headerNumberIndex, int(gcTarget)))
+	t.Run("header hash index count", newItemsCountTest(db.headerHashIndex, int(gcTarget)))
+
+	// the first chunk should be garbage collected with its header index
+	has, err := db.HasBlockHeader(chunks[0].Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if has {
+		t.Error("garbage collected block header is indexed")
+	}
+}
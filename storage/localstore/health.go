@@ -0,0 +1,248 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethersphere/swarm/shed"
+)
+
+// healthCheckItemsPerSecond is a conservative estimate of the number
+// of index items iterated in a second, used to estimate the time
+// needed to reconstruct gcSize.
+const healthCheckItemsPerSecond = 200000
+
+// errClosed is returned by the health check if the database
+// is closed while indexes are iterated.
+var errClosed = errors.New("database closed")
+
+// HealthReport holds information about the database state when it
+// was opened. Index counts are available when the health check is done.
+type HealthReport struct {
+	// Schema is the name of the database schema.
+	Schema string `json:"schema"`
+	// UncleanShutdown is true if the database was not closed
+	// properly the last time it was used.
+	UncleanShutdown bool `json:"uncleanShutdown"`
+	// GCSize is the gcSize value when the database was opened.
+	GCSize uint64 `json:"gcSize"`
+	// GCSizeRebuild is true if gcSize has to be reconstructed
+	// from the garbage collection indexes.
+	GCSizeRebuild bool `json:"gcSizeRebuild"`
+	// EstimatedRecoveryTime is the estimated duration of the
+	// gcSize reconstruction.
+	EstimatedRecoveryTime time.Duration `json:"estimatedRecoveryTime"`
+	// Indices holds the number of items in every index, except
+	// retrievalDataIndex which has the same number of items as
+	// pullIndex and iterating it would read all chunk data.
+	Indices map[string]int `json:"indices"`
+	// Ready is true when the health check is successfully done.
+	Ready bool `json:"ready"`
+	// Duration is the duration of the health check.
+	Duration time.Duration `json:"duration"`
+	// Error is set if the health check failed.
+	Error string `json:"error,omitempty"`
+}
+
+// HealthReport returns the report of the health check done
+// when the database was opened.
+func (db *DB) HealthReport() HealthReport {
+	db.healthMu.RLock()
+	defer db.healthMu.RUnlock()
+
+	r := *db.health
+	if db.health.Indices != nil {
+		r.Indices = make(map[string]int, len(db.health.Indices))
+		for name, count := range db.health.Indices {
+			r.Indices[name] = count
+		}
+	}
+	return r
+}
+
+// Ready returns a channel that is closed when gcSize is reliable,
+// after it is reconstructed if the database was not closed properly.
+// In slow start mode, garbage collection is not started and writes may
+// block until then. Index counts are reported when the health report
+// is Ready, which may be later.
+func (db *DB) Ready() <-chan struct{} {
+	return db.ready
+}
+
+// startHealthCheck creates the initial health report and runs the
+// health check. If slowStart is false, gcSize is reconstructed before
+// it returns, if needed. Indexes are always counted in the background.
+func (db *DB) startHealthCheck(slowStart bool) (err error) {
+	r := new(HealthReport)
+	r.Schema, err = db.schemaName.Get()
+	if err != nil {
+		return err
+	}
+	r.GCSize, err = db.gcSize.Get()
+	if err != nil {
+		return err
+	}
+	dirty, err := db.dirty.Get()
+	if err != nil {
+		return err
+	}
+	r.UncleanShutdown = dirty != 0
+	// gcSize is updated in the same batch as garbage collection indexes,
+	// but it can not be trusted if the database was not closed properly
+	r.GCSizeRebuild = r.UncleanShutdown
+	if r.GCSizeRebuild {
+		r.EstimatedRecoveryTime = time.Duration(r.GCSize) * time.Second / healthCheckItemsPerSecond
+	}
+	db.health = r
+
+	// mark the database as open until it is closed properly
+	if err := db.dirty.Put(1); err != nil {
+		return err
+	}
+
+	log.Info("localstore opened", "schema", r.Schema, "unclean shutdown", r.UncleanShutdown, "gc size", r.GCSize, "gc size rebuild", r.GCSizeRebuild, "estimated recovery time", r.EstimatedRecoveryTime, "slow start", slowStart)
+
+	if !slowStart {
+		if err := db.rebuildGCSize(); err != nil {
+			close(db.healthCheckDone)
+			return err
+		}
+	}
+	go func() {
+		if err := db.healthCheck(slowStart); err != nil && err != errClosed {
+			log.Error("localstore health check", "err", err)
+		}
+	}()
+	return nil
+}
+
+// healthCheck reconstructs gcSize if rebuildGCSize is true and counts
+// items in indexes. It closes the healthCheckDone channel when it returns.
+func (db *DB) healthCheck(rebuildGCSize bool) (err error) {
+	defer close(db.healthCheckDone)
+
+	start := time.Now()
+	defer func() {
+		db.healthMu.Lock()
+		db.health.Duration = time.Since(start)
+		if err != nil {
+			db.health.Error = err.Error()
+		} else {
+			db.health.Ready = true
+		}
+		r := db.health
+		db.healthMu.Unlock()
+
+		if err == nil {
+			log.Info("localstore health check done", "duration", r.Duration, "indices", r.Indices)
+		}
+	}()
+
+	if rebuildGCSize {
+		if err := db.rebuildGCSize(); err != nil {
+			return err
+		}
+	}
+
+	counts := make(map[string]int)
+	for name, index := range db.indices() {
+		if name == "retrievalDataIndex" {
+			continue
+		}
+		counts[name], err = db.countIndex(index)
+		if err != nil {
+			return err
+		}
+	}
+
+	db.healthMu.Lock()
+	db.health.Indices = counts
+	db.healthMu.Unlock()
+	return nil
+}
+
+// rebuildGCSize reconstructs gcSize from garbage collection indexes
+// if the database was not closed properly. It closes the ready channel
+// when it returns.
+func (db *DB) rebuildGCSize() (err error) {
+	defer close(db.ready)
+
+	if !db.health.GCSizeRebuild {
+		return nil
+	}
+
+	// block writes while garbage collection indexes are counted,
+	// reads are not affected
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	gcCount, err := db.countIndex(db.gcIndex)
+	if err != nil {
+		return err
+	}
+	reserveCount, err := db.countIndex(db.gcReserveIndex)
+	if err != nil {
+		return err
+	}
+	gcSize := uint64(gcCount + reserveCount)
+	if err := db.gcSize.Put(gcSize); err != nil {
+		return err
+	}
+	if gcSize != db.health.GCSize {
+		log.Warn("localstore gc size reconstructed", "was", db.health.GCSize, "now", gcSize)
+	}
+
+	db.healthMu.Lock()
+	db.gcSizeRebuilt = true
+	db.healthMu.Unlock()
+
+	if gcSize >= db.capacity {
+		db.triggerGarbageCollection()
+	}
+	return nil
+}
+
+// countIndex returns the number of items in the index.
+// It returns errClosed if the database is closed.
+func (db *DB) countIndex(index shed.Index) (count int, err error) {
+	err = index.Iterate(func(item shed.Item) (stop bool, err error) {
+		select {
+		case <-db.close:
+			return true, errClosed
+		default:
+		}
+		count++
+		return false, nil
+	}, nil)
+	return count, err
+}
+
+// markClosed clears the flag set when the database was opened,
+// unless gcSize still needs to be reconstructed.
+func (db *DB) markClosed() error {
+	db.healthMu.RLock()
+	clean := !db.health.GCSizeRebuild || db.gcSizeRebuilt
+	db.healthMu.RUnlock()
+
+	if !clean {
+		return nil
+	}
+	return db.dirty.Put(0)
+}
@@ -0,0 +1,325 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/shed"
+)
+
+// TestDB_HealthReport validates the health report
+// of a database that is closed properly.
+func TestDB_HealthReport(t *testing.T) {
+	db, cleanupFunc := newTestDB(t, nil)
+	defer cleanupFunc()
+
+	select {
+	case <-db.Ready():
+	default:
+		t.Fatal("database not ready")
+	}
+	waitHealthCheck(t, db)
+
+	r := db.HealthReport()
+	if !r.Ready {
+		t.Error("health check not done")
+	}
+	if r.UncleanShutdown {
+		t.Error("unexpected unclean shutdown")
+	}
+	if r.GCSizeRebuild {
+		t.Error("unexpected gc size rebuild")
+	}
+	if r.Schema != DbSchemaCurrent {
+		t.Errorf("got schema %q, want %q", r.Schema, DbSchemaCurrent)
+	}
+	for name, count := range r.Indices {
+		if count != 0 {
+			t.Errorf("got %v items in %s, want 0", count, name)
+		}
+	}
+	if _, ok := r.Indices["retrievalDataIndex"]; ok {
+		t.Error("retrievalDataIndex should not be counted")
+	}
+}
+
+// TestDB_HealthReport_uncleanShutdown validates that gcSize is
+// reconstructed after an unclean shutdown.
+func TestDB_HealthReport_uncleanShutdown(t *testing.T) {
+	t.Run("slow start", func(t *testing.T) {
+		testDBHealthReportUncleanShutdown(t, true)
+	})
+	t.Run("no slow start", func(t *testing.T) {
+		testDBHealthReportUncleanShutdown(t, false)
+	})
+}
+
+// testDBHealthReportUncleanShutdown validates that gcSize is reconstructed
+// after an unclean shutdown, before New returns if slowStart is false.
+func testDBHealthReportUncleanShutdown(t *testing.T, slowStart bool) {
+	dir, err := ioutil.TempDir("", "localstore-health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	baseKey := make([]byte, 32)
+	if _, err := rand.Read(baseKey); err != nil {
+		t.Fatal(err)
+	}
+	db, err := New(dir, baseKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count := 100
+	chunks := make([]chunk.Chunk, 0, count)
+	for i := 0; i < count; i++ {
+		ch := generateTestRandomChunk()
+
+		_, err := db.Put(context.Background(), chunk.ModePutUpload, ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = db.Set(context.Background(), chunk.ModeSetSyncPull, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks = append(chunks, ch)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// simulate an unclean shutdown with invalid gcSize
+	s, err := shed.NewDB(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range map[string]uint64{
+		"dirty":   1,
+		"gc-size": 3,
+	} {
+		f, err := s.NewUint64Field(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Put(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = New(dir, baseKey, &Options{SlowStart: slowStart})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slowStart {
+		select {
+		case <-db.Ready():
+		default:
+			t.Fatal("database not ready")
+		}
+		// gcSize is reconstructed synchronously
+		// while indexes may still be counted
+		gcSize, err := db.gcSize.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gcSize != uint64(count) {
+			t.Errorf("got gc size %v, want %v", gcSize, count)
+		}
+	}
+
+	// reads are served while the health check is running
+	for _, ch := range chunks[:10] {
+		_, err := db.Get(context.Background(), chunk.ModeGetRequest, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case <-db.Ready():
+	case <-time.After(10 * time.Second):
+		t.Fatal("health check timeout")
+	}
+	waitHealthCheck(t, db)
+
+	r := db.HealthReport()
+	if !r.Ready {
+		t.Errorf("health check not done: %s", r.Error)
+	}
+	if !r.UncleanShutdown {
+		t.Error("unclean shutdown not detected")
+	}
+	if !r.GCSizeRebuild {
+		t.Error("gc size rebuild not required")
+	}
+	if r.GCSize != 3 {
+		t.Errorf("got gc size %v, want %v", r.GCSize, 3)
+	}
+	if r.Indices["pullIndex"] != count {
+		t.Errorf("got %v items in pullIndex, want %v", r.Indices["pullIndex"], count)
+	}
+
+	t.Run("gc size", newIndexGCSizeTest(db))
+
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = New(dir, baseKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	r = db.HealthReport()
+	if r.UncleanShutdown {
+		t.Error("unexpected unclean shutdown after reconstruction")
+	}
+	if r.GCSize != uint64(count) {
+		t.Errorf("got gc size %v, want %v", r.GCSize, count)
+	}
+}
+
+// TestDB_crashRestart validates that chunks and pin counters are
+// preserved and that gcSize is reconstructed when the database is
+// opened from the files of a database that was not closed.
+func TestDB_crashRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localstore-crash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	baseKey := make([]byte, 32)
+	if _, err := rand.Read(baseKey); err != nil {
+		t.Fatal(err)
+	}
+	db, err := New(dir, baseKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var chunks []chunk.Chunk
+	for i := 0; i < 50; i++ {
+		ch := generateTestRandomChunk()
+		if _, err := db.Put(context.Background(), chunk.ModePutUpload, ch); err != nil {
+			t.Fatal(err)
+		}
+		// only synced chunks are in the garbage collection index
+		if i%2 == 0 {
+			if err := db.Set(context.Background(), chunk.ModeSetSyncPull, ch.Address()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		chunks = append(chunks, ch)
+	}
+	pinned := make([]chunk.Chunk, 10)
+	for i := range pinned {
+		pinned[i] = generateTestRandomChunk()
+	}
+	if _, err := db.Put(context.Background(), chunk.ModePutRequest, pinned...); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		for _, ch := range pinned {
+			if err := db.Set(context.Background(), chunk.ModeSetPin, ch.Address()); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	chunks = append(chunks, pinned...)
+
+	// copy database files without closing the database
+	crashDir, err := ioutil.TempDir("", "localstore-crash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(crashDir)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if err := copyFileContents(filepath.Join(dir, f.Name()), filepath.Join(crashDir, f.Name())); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cdb, err := New(crashDir, baseKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cdb.Close()
+
+	r := cdb.HealthReport()
+	if !r.UncleanShutdown {
+		t.Error("unclean shutdown not detected")
+	}
+	if !r.GCSizeRebuild {
+		t.Error("gc size rebuild not required")
+	}
+
+	t.Run("gc size", newIndexGCSizeTest(cdb))
+
+	for _, ch := range chunks {
+		got, err := cdb.Get(context.Background(), chunk.ModeGetLookup, ch.Address())
+		if err != nil {
+			t.Fatalf("chunk %s: %v", ch.Address(), err)
+		}
+		if !bytes.Equal(got.Data(), ch.Data()) {
+			t.Fatalf("chunk %s: got invalid data", ch.Address())
+		}
+	}
+
+	for _, ch := range pinned {
+		item, err := cdb.pinIndex.Get(shed.Item{
+			Address: ch.Address(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item.PinCounter != 2 {
+			t.Errorf("chunk %s: got pin counter %v, want %v", ch.Address(), item.PinCounter, 2)
+		}
+	}
+}
+
+// waitHealthCheck waits for indexes to be counted by the health check.
+func waitHealthCheck(t *testing.T, db *DB) {
+	t.Helper()
+
+	select {
+	case <-db.healthCheckDone:
+	case <-time.After(10 * time.Second):
+		t.Fatal("health check timeout")
+	}
+}
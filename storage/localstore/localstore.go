@@ -29,6 +29,7 @@ import (
 	"github.com/ethersphere/swarm/chunk"
 	"github.com/ethersphere/swarm/shed"
 	"github.com/ethersphere/swarm/storage/mock"
+	"github.com/syndtr/goleveldb/leveldb"
 )
 
 // DB implements chunk.Store.
@@ -60,6 +61,8 @@ type DB struct {
 
 	// schema name of loaded data
 	schemaName shed.StringField
+	// migrations that were run on loaded data
+	migrationHistory shed.StructField
 
 	// retrieval indexes
 	retrievalDataIndex   shed.Index
@@ -86,15 +89,52 @@ type DB struct {
 	// garbage collection exclude index for pinned contents
 	gcExcludeIndex shed.Index
 
+	// garbage collection index for chunks within the area of
+	// responsibility, collected only when gcIndex is exhausted
+	gcReserveIndex shed.Index
+
+	// returns the proximity order from which chunks are
+	// in the area of responsibility of this node
+	responsibilityDepth func() int
+
 	// pin files Index
 	pinIndex shed.Index
 
-	// field that stores number of intems in gc index
+	// postage stamps of stamped chunks
+	stampIndex shed.Index
+
+	// block header chunks by block number, for range queries
+	headerNumberIndex shed.Index
+	// block numbers of block header chunks by address
+	headerHashIndex shed.Index
+
+	// field that stores number of intems in gc and gc reserve indexes
 	gcSize shed.Uint64Field
 
+	// field that is set while the database is open
+	// to detect unclean shutdowns
+	dirty shed.Uint64Field
+
+	// report of the health check done when the database is opened
+	health   *HealthReport
+	healthMu sync.RWMutex
+	// set when gcSize is reconstructed after an unclean shutdown
+	gcSizeRebuilt bool
+	// closed when gcSize is reliable, reconstructed if needed
+	ready chan struct{}
+	// closed when the health check is done
+	healthCheckDone chan struct{}
+
 	// garbage collection is triggered when gcSize exceeds
 	// the capacity value
 	capacity uint64
+	// garbage collection removes items until gcSize is
+	// reduced to this ratio of capacity
+	gcTargetRatio float64
+
+	// adapts the number of chunks removed
+	// in a single garbage collection batch
+	gcBatchSizer *gcBatchSizer
 
 	// triggers garbage collection event loop
 	collectGarbageTrigger chan struct{}
@@ -120,12 +160,42 @@ type DB struct {
 	// are done
 	collectGarbageWorkerDone chan struct{}
 
+	// protect Close method from exiting before
+	// proximity metrics worker is done
+	proximityMetricsWorkerDone chan struct{}
+	// period of proximity metrics updates, negative if disabled
+	proximityMetricsInterval time.Duration
+
+	// prefix of metrics names
+	metricsPrefix string
+
+	// path of the database directory
+	path string
+	// free disk space and degraded mode state
+	disk   DiskStatus
+	diskMu sync.Mutex
+	// set if free disk space can not be determined on this platform
+	diskFreeUnsupported bool
+	// triggers of disk status subscriptions, guarded by diskMu
+	diskTriggers []chan struct{}
+
+	// push and pull index sizes cached by the Status method
+	statusCounts statusCounts
+	statusMu     sync.Mutex
+	// protect Close method from exiting before
+	// disk space worker is done
+	diskSpaceWorkerDone chan struct{}
+
 	putToGCCheck func([]byte) bool
 
 	// wait for all subscriptions to finish before closing
 	// underlaying LevelDB to prevent possible panics from
 	// iterators
 	subscritionsWG sync.WaitGroup
+
+	// errors and delays injected in tests,
+	// see fault.go and fault_none.go
+	faults faults
 }
 
 // Options struct holds optional parameters for configuring DB.
@@ -139,13 +209,41 @@ type Options struct {
 	// Capacity is a limit that triggers garbage collection when
 	// number of items in gcIndex equals or exceeds it.
 	Capacity uint64
+	// GCTargetRatio is the ratio of Capacity that garbage collection
+	// leaves in the database. It must be in range (0,1], otherwise
+	// the default value is used. Lower values remove more chunks
+	// in a single garbage collection run.
+	GCTargetRatio float64
 	// MetricsPrefix defines a prefix for metrics names.
+	// It is prepended to names without a separator, like "proxycache/".
 	MetricsPrefix string
 	Tags          *chunk.Tags
 	// PutSetCheckFunc is a function called after a Put of a chunk
 	// to verify whether that chunk needs to be Set and added to
 	// garbage collection index too
 	PutToGCCheck func([]byte) bool
+	// ResponsibilityDepth is a function that returns the current
+	// neighbourhood depth. Chunks with proximity order to the base key
+	// equal or greater than depth are garbage collected only when
+	// there are no other chunks to collect. If nil, all chunks are
+	// collected by their access time.
+	ResponsibilityDepth func() int
+	// SlowStart makes New return before gcSize is reconstructed after
+	// an unclean shutdown, serving reads while it is reconstructed in the
+	// background. Garbage collection is started when gcSize is reconstructed.
+	// Without it, New blocks until then. Indexes are always counted for
+	// the health report in the background.
+	SlowStart bool
+	// MinFreeDiskSpace is the number of bytes of free disk space
+	// below which chunks received from the network are rejected
+	// with chunk.ErrDiskFull, while uploads, pins and reads are still
+	// served. The same degraded mode is entered when a write fails
+	// because the disk is full. If 0, only the full disk is detected.
+	MinFreeDiskSpace uint64
+	// ProximityMetricsInterval is the period in which proximity histogram
+	// metrics are updated, which requires iterating over all stored chunks.
+	// If 0, the default value is used. If negative, the metrics are not updated.
+	ProximityMetricsInterval time.Duration
 }
 
 // New returns a new DB.  All fields and indexes are initialized
@@ -164,21 +262,38 @@ func New(path string, baseKey []byte, o *Options) (db *DB, err error) {
 	}
 
 	db = &DB{
-		capacity: o.Capacity,
-		baseKey:  baseKey,
-		tags:     o.Tags,
+		capacity:      o.Capacity,
+		gcTargetRatio: o.GCTargetRatio,
+		metricsPrefix: o.MetricsPrefix,
+		baseKey:       baseKey,
+		tags:          o.Tags,
 		// channel collectGarbageTrigger
 		// needs to be buffered with the size of 1
 		// to signal another event if it
 		// is triggered during already running function
-		collectGarbageTrigger:    make(chan struct{}, 1),
-		close:                    make(chan struct{}),
-		collectGarbageWorkerDone: make(chan struct{}),
-		putToGCCheck:             o.PutToGCCheck,
+		collectGarbageTrigger:      make(chan struct{}, 1),
+		close:                      make(chan struct{}),
+		collectGarbageWorkerDone:   make(chan struct{}),
+		proximityMetricsWorkerDone: make(chan struct{}),
+		diskSpaceWorkerDone:        make(chan struct{}),
+		path:                       path,
+		disk:                       DiskStatus{MinFreeSpace: o.MinFreeDiskSpace},
+		ready:                      make(chan struct{}),
+		healthCheckDone:            make(chan struct{}),
+		putToGCCheck:               o.PutToGCCheck,
+		responsibilityDepth:        o.ResponsibilityDepth,
+		gcBatchSizer:               newGCBatchSizer(gcBatchSize, gcMinBatchSize, gcMaxBatchSize),
 	}
 	if db.capacity <= 0 {
 		db.capacity = defaultCapacity
 	}
+	if db.gcTargetRatio <= 0 || db.gcTargetRatio > 1 {
+		db.gcTargetRatio = gcTargetRatio
+	}
+	db.proximityMetricsInterval = o.ProximityMetricsInterval
+	if db.proximityMetricsInterval == 0 {
+		db.proximityMetricsInterval = proximityMetricsInterval
+	}
 	if maxParallelUpdateGC > 0 {
 		db.updateGCSem = make(chan struct{}, maxParallelUpdateGC)
 	}
@@ -197,6 +312,10 @@ func New(path string, baseKey []byte, o *Options) (db *DB, err error) {
 	if err != nil {
 		return nil, err
 	}
+	db.migrationHistory, err = db.shed.NewStructField("migration-history")
+	if err != nil {
+		return nil, err
+	}
 	if schemaName == "" {
 		// initial new localstore run
 		err := db.schemaName.Put(DbSchemaCurrent)
@@ -216,6 +335,11 @@ func New(path string, baseKey []byte, o *Options) (db *DB, err error) {
 	if err != nil {
 		return nil, err
 	}
+	// Persist open state.
+	db.dirty, err = db.shed.NewUint64Field("dirty")
+	if err != nil {
+		return nil, err
+	}
 	// Functions for retrieval data index.
 	var (
 		encodeValueFunc func(fields shed.Item) (value []byte, err error)
@@ -430,8 +554,111 @@ func New(path string, baseKey []byte, o *Options) (db *DB, err error) {
 		return nil, err
 	}
 
+	// postage stamps attached to chunks on upload or received
+	// with them from other nodes
+	db.stampIndex, err = db.shed.NewIndex("Hash->Stamp", shed.IndexFuncs{
+		EncodeKey: func(fields shed.Item) (key []byte, err error) {
+			return fields.Address, nil
+		},
+		DecodeKey: func(key []byte) (e shed.Item, err error) {
+			e.Address = key
+			return e, nil
+		},
+		EncodeValue: func(fields shed.Item) (value []byte, err error) {
+			return fields.Stamp, nil
+		},
+		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
+			e.Stamp = value
+			return e, nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// block header chunks indexed by their block numbers,
+	// BinID field of the Item holds the block number
+	db.headerNumberIndex, err = db.shed.NewIndex("BlockNumber|Hash->nil", shed.IndexFuncs{
+		EncodeKey: func(fields shed.Item) (key []byte, err error) {
+			key = make([]byte, 8, 8+len(fields.Address))
+			binary.BigEndian.PutUint64(key, fields.BinID)
+			return append(key, fields.Address...), nil
+		},
+		DecodeKey: func(key []byte) (e shed.Item, err error) {
+			e.BinID = binary.BigEndian.Uint64(key[:8])
+			e.Address = key[8:]
+			return e, nil
+		},
+		EncodeValue: func(fields shed.Item) (value []byte, err error) {
+			return nil, nil
+		},
+		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
+			return e, nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	db.headerHashIndex, err = db.shed.NewIndex("Hash->BlockNumber", shed.IndexFuncs{
+		EncodeKey: func(fields shed.Item) (key []byte, err error) {
+			return fields.Address, nil
+		},
+		DecodeKey: func(key []byte) (e shed.Item, err error) {
+			e.Address = key
+			return e, nil
+		},
+		EncodeValue: func(fields shed.Item) (value []byte, err error) {
+			value = make([]byte, 8)
+			binary.BigEndian.PutUint64(value, fields.BinID)
+			return value, nil
+		},
+		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
+			e.BinID = binary.BigEndian.Uint64(value)
+			return e, nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// gc reserve index for chunks in the area of responsibility
+	// ordered by ascending proximity order and last access time
+	db.gcReserveIndex, err = db.shed.NewIndex("PO|AccessTimestamp|BinID|Hash->nil", shed.IndexFuncs{
+		EncodeKey: func(fields shed.Item) (key []byte, err error) {
+			b := make([]byte, 17, 17+len(fields.Address))
+			b[0] = db.po(fields.Address)
+			binary.BigEndian.PutUint64(b[1:9], uint64(fields.AccessTimestamp))
+			binary.BigEndian.PutUint64(b[9:17], fields.BinID)
+			key = append(b, fields.Address...)
+			return key, nil
+		},
+		DecodeKey: func(key []byte) (e shed.Item, err error) {
+			e.AccessTimestamp = int64(binary.BigEndian.Uint64(key[1:9]))
+			e.BinID = binary.BigEndian.Uint64(key[9:17])
+			e.Address = key[17:]
+			return e, nil
+		},
+		EncodeValue: func(fields shed.Item) (value []byte, err error) {
+			return nil, nil
+		},
+		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
+			return e, nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.startHealthCheck(o.SlowStart); err != nil {
+		return nil, err
+	}
+
 	// start garbage collection worker
 	go db.collectGarbageWorker()
+	// start proximity metrics worker
+	go db.proximityMetricsWorker()
+	// start disk space worker
+	go db.diskSpaceWorker()
 	return db, nil
 }
 
@@ -444,9 +671,15 @@ func (db *DB) Close() (err error) {
 	go func() {
 		db.updateGCWG.Wait()
 		db.subscritionsWG.Wait()
+		// wait for the health check to
+		// stop iterating indexes
+		<-db.ready
+		<-db.healthCheckDone
 		// wait for gc worker to
 		// return before closing the shed
 		<-db.collectGarbageWorkerDone
+		<-db.proximityMetricsWorkerDone
+		<-db.diskSpaceWorkerDone
 		close(done)
 	}()
 	select {
@@ -457,9 +690,28 @@ func (db *DB) Close() (err error) {
 		// TODO: use a logger to write a goroutine profile
 		pprof.Lookup("goroutine").WriteTo(os.Stdout, 2)
 	}
+	if err := db.markClosed(); err != nil {
+		log.Error("localstore mark closed", "err", err)
+	}
 	return db.shed.Close()
 }
 
+// writeBatch writes the batch to the underlying database
+// with possible injected faults and records the write
+// duration for gc batch size adjustments.
+func (db *DB) writeBatch(batch *leveldb.Batch) (err error) {
+	defer func(start time.Time) {
+		if err == nil {
+			db.gcBatchSizer.observeWrite(time.Since(start))
+		}
+	}(time.Now())
+
+	if err := db.faults.writeBatch(); err != nil {
+		return db.checkWriteError(err)
+	}
+	return db.checkWriteError(db.shed.WriteBatch(batch))
+}
+
 // po computes the proximity order between the address
 // and database base key.
 func (db *DB) po(addr chunk.Address) (bin uint8) {
@@ -470,15 +722,7 @@ func (db *DB) po(addr chunk.Address) (bin uint8) {
 // the returned map keys are the index name, values are the number of elements in the index
 func (db *DB) DebugIndices() (indexInfo map[string]int, err error) {
 	indexInfo = make(map[string]int)
-	for k, v := range map[string]shed.Index{
-		"retrievalDataIndex":   db.retrievalDataIndex,
-		"retrievalAccessIndex": db.retrievalAccessIndex,
-		"pushIndex":            db.pushIndex,
-		"pullIndex":            db.pullIndex,
-		"gcIndex":              db.gcIndex,
-		"gcExcludeIndex":       db.gcExcludeIndex,
-		"pinIndex":             db.pinIndex,
-	} {
+	for k, v := range db.indices() {
 		indexSize, err := v.Count()
 		if err != nil {
 			return indexInfo, err
@@ -500,6 +744,7 @@ func chunkToItem(ch chunk.Chunk) shed.Item {
 		Address: ch.Address(),
 		Data:    ch.Data(),
 		Tag:     ch.TagID(),
+		Stamp:   ch.Stamp(),
 	}
 }
 
@@ -542,3 +787,20 @@ func totalTimeMetric(name string, start time.Time) {
 	totalTime := time.Since(start)
 	metrics.GetOrRegisterResettingTimer(name+"/total-time", nil).Update(totalTime)
 }
+
+// indices returns all indexes in localstore by their names.
+func (db *DB) indices() map[string]shed.Index {
+	return map[string]shed.Index{
+		"retrievalDataIndex":   db.retrievalDataIndex,
+		"retrievalAccessIndex": db.retrievalAccessIndex,
+		"pushIndex":            db.pushIndex,
+		"pullIndex":            db.pullIndex,
+		"gcIndex":              db.gcIndex,
+		"gcExcludeIndex":       db.gcExcludeIndex,
+		"gcReserveIndex":       db.gcReserveIndex,
+		"pinIndex":             db.pinIndex,
+		"stampIndex":           db.stampIndex,
+		"headerNumberIndex":    db.headerNumberIndex,
+		"headerHashIndex":      db.headerHashIndex,
+	}
+}
@@ -377,18 +377,20 @@ func newItemsCountTest(i shed.Index, want int) func(t *testing.T) {
 }
 
 // newIndexGCSizeTest retruns a test function that validates if DB.gcSize
-// value is the same as the number of items in DB.gcIndex.
+// value is the same as the number of items in DB.gcIndex and DB.gcReserveIndex.
 func newIndexGCSizeTest(db *DB) func(t *testing.T) {
 	return func(t *testing.T) {
 		t.Helper()
 
 		var want uint64
-		err := db.gcIndex.Iterate(func(item shed.Item) (stop bool, err error) {
-			want++
-			return
-		}, nil)
-		if err != nil {
-			t.Fatal(err)
+		for _, i := range []shed.Index{db.gcIndex, db.gcReserveIndex} {
+			err := i.Iterate(func(item shed.Item) (stop bool, err error) {
+				want++
+				return
+			}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
 		}
 		got, err := db.gcSize.Get()
 		if err != nil {
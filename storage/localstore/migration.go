@@ -65,6 +65,10 @@ func (db *DB) migrate(schemaName string) error {
 		if err != nil {
 			return err
 		}
+		err = db.addMigrationRecord(schemaName, migrations[i].name)
+		if err != nil {
+			return err
+		}
 		schemaName, err = db.schemaName.Get()
 		if err != nil {
 			return err
@@ -74,6 +78,38 @@ func (db *DB) migrate(schemaName string) error {
 	return nil
 }
 
+// MigrationRecord describes a single schema migration
+// that was run on the database.
+type MigrationRecord struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Timestamp uint64 `json:"timestamp"` // unix time in nanoseconds
+}
+
+// MigrationHistory returns all migrations that were run
+// on the database in the order of their execution.
+func (db *DB) MigrationHistory() (history []MigrationRecord, err error) {
+	err = db.migrationHistory.Get(&history)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	return history, err
+}
+
+// addMigrationRecord appends a migration record to the migration history.
+func (db *DB) addMigrationRecord(from, to string) error {
+	history, err := db.MigrationHistory()
+	if err != nil {
+		return err
+	}
+	history = append(history, MigrationRecord{
+		From:      from,
+		To:        to,
+		Timestamp: uint64(now()),
+	})
+	return db.migrationHistory.Put(history)
+}
+
 // migrationFn is a function that takes a localstore.DB and
 // returns an error if a migration has failed
 type migrationFn func(db *DB) error
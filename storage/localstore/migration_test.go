@@ -184,6 +184,25 @@ func TestManyMigrations(t *testing.T) {
 		}
 	}
 
+	history, err := db.MigrationHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantHistory := [][2]string{
+		{DbSchemaSanctuary, DbSchemaDiwali},
+		{DbSchemaDiwali, "coconut"},
+		{"coconut", "mango"},
+		{"mango", "salvation"},
+	}
+	if len(history) != len(wantHistory) {
+		t.Fatalf("got %v migration records, want %v", len(history), len(wantHistory))
+	}
+	for i, r := range history {
+		if r.From != wantHistory[i][0] || r.To != wantHistory[i][1] || r.Timestamp == 0 {
+			t.Errorf("got migration record %v %+v, want from %s to %s", i, r, wantHistory[i][0], wantHistory[i][1])
+		}
+	}
+
 	err = db.Close()
 	if err != nil {
 		t.Error(err)
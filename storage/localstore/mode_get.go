@@ -60,6 +60,9 @@ func (db *DB) Get(ctx context.Context, mode chunk.ModeGet, addr chunk.Address) (
 func (db *DB) get(mode chunk.ModeGet, addr chunk.Address) (out shed.Item, err error) {
 	item := addressToItem(addr)
 
+	if db.faults.notFound() {
+		return out, leveldb.ErrNotFound
+	}
 	out, err = db.retrievalDataIndex.Get(item)
 	if err != nil {
 		return out, err
@@ -148,7 +151,7 @@ func (db *DB) updateGC(item shed.Item) (err error) {
 		return nil
 	}
 	// delete current entry from the gc index
-	db.gcIndex.DeleteInBatch(batch, item)
+	db.deleteGCInBatch(batch, item)
 	// update access timestamp
 	item.AccessTimestamp = now()
 	// update retrieve access index
@@ -165,7 +168,7 @@ func (db *DB) updateGC(item shed.Item) (err error) {
 		}
 	}
 
-	return db.shed.WriteBatch(batch)
+	return db.writeBatch(batch)
 }
 
 // testHookUpdateGC is a hook that can provide
@@ -65,6 +65,9 @@ func (db *DB) getMulti(mode chunk.ModeGet, addrs ...chunk.Address) (out []shed.I
 		out[i].Address = addr
 	}
 
+	if db.faults.notFound() {
+		return nil, leveldb.ErrNotFound
+	}
 	err = db.retrievalDataIndex.Fill(out)
 	if err != nil {
 		return nil, err
@@ -38,10 +38,20 @@ func (db *DB) Put(ctx context.Context, mode chunk.ModePut, chs ...chunk.Chunk) (
 	metrics.GetOrRegisterCounter(metricName, nil).Inc(1)
 	defer totalTimeMetric(metricName, time.Now())
 
+	// chunks received from the network are
+	// rejected in degraded mode
+	if mode == chunk.ModePutRequest || mode == chunk.ModePutSync {
+		if err := db.degraded(); err != nil {
+			metrics.GetOrRegisterCounter(metricName+"/degraded", nil).Inc(1)
+			return nil, err
+		}
+	}
+
 	exist, err = db.put(mode, chs...)
 	if err != nil {
 		metrics.GetOrRegisterCounter(metricName+"/error", nil).Inc(1)
 	}
+	db.gcBatchSizer.observePut(len(chs))
 
 	return exist, err
 }
@@ -81,10 +91,14 @@ func (db *DB) put(mode chunk.ModePut, chs ...chunk.Chunk) (exist []bool, err err
 				exist[i] = true
 				continue
 			}
-			exists, c, err := db.putRequest(batch, binIDs, chunkToItem(ch))
+			item := chunkToItem(ch)
+			exists, c, err := db.putRequest(batch, binIDs, item)
 			if err != nil {
 				return nil, err
 			}
+			if err := db.setStamp(batch, item); err != nil {
+				return nil, err
+			}
 			exist[i] = exists
 			gcSizeChange += c
 		}
@@ -95,10 +109,14 @@ func (db *DB) put(mode chunk.ModePut, chs ...chunk.Chunk) (exist []bool, err err
 				exist[i] = true
 				continue
 			}
-			exists, c, err := db.putUpload(batch, binIDs, chunkToItem(ch))
+			item := chunkToItem(ch)
+			exists, c, err := db.putUpload(batch, binIDs, item)
 			if err != nil {
 				return nil, err
 			}
+			if err := db.setStamp(batch, item); err != nil {
+				return nil, err
+			}
 			exist[i] = exists
 			if !exists {
 				// chunk is new so, trigger subscription feeds
@@ -115,10 +133,14 @@ func (db *DB) put(mode chunk.ModePut, chs ...chunk.Chunk) (exist []bool, err err
 				exist[i] = true
 				continue
 			}
-			exists, c, err := db.putSync(batch, binIDs, chunkToItem(ch))
+			item := chunkToItem(ch)
+			exists, c, err := db.putSync(batch, binIDs, item)
 			if err != nil {
 				return nil, err
 			}
+			if err := db.setStamp(batch, item); err != nil {
+				return nil, err
+			}
 			exist[i] = exists
 			if !exists {
 				// chunk is new so, trigger pull subscription feed
@@ -141,7 +163,7 @@ func (db *DB) put(mode chunk.ModePut, chs ...chunk.Chunk) (exist []bool, err err
 		return nil, err
 	}
 
-	err = db.shed.WriteBatch(batch)
+	err = db.writeBatch(batch)
 	if err != nil {
 		return nil, err
 	}
@@ -158,6 +180,8 @@ func (db *DB) put(mode chunk.ModePut, chs ...chunk.Chunk) (exist []bool, err err
 // putRequest adds an Item to the batch by updating required indexes:
 //  - put to indexes: retrieve, gc
 //  - it does not enter the syncpool
+// If the chunk already exists, only gc related indexes are updated
+// and chunk data is not written again.
 // The batch can be written to the database.
 // Provided batch and binID map are updated.
 func (db *DB) putRequest(batch *leveldb.Batch, binIDs map[uint8]uint64, item shed.Item) (exists bool, gcSizeChange int64, err error) {
@@ -188,7 +212,9 @@ func (db *DB) putRequest(batch *leveldb.Batch, binIDs map[uint8]uint64, item she
 		return false, 0, err
 	}
 
-	db.retrievalDataIndex.PutInBatch(batch, item)
+	if !exists {
+		db.retrievalDataIndex.PutInBatch(batch, item)
+	}
 
 	return exists, gcSizeChange, nil
 }
@@ -210,7 +236,7 @@ func (db *DB) putUpload(batch *leveldb.Batch, binIDs map[uint8]uint64, item shed
 			}
 		}
 
-		return true, 0, nil
+		return true, gcSizeChange, nil
 	}
 	anonymous := false
 	if db.tags != nil && item.Tag != 0 {
@@ -307,7 +333,7 @@ func (db *DB) setGC(batch *leveldb.Batch, item shed.Item) (gcSizeChange int64, e
 	switch err {
 	case nil:
 		item.AccessTimestamp = i.AccessTimestamp
-		db.gcIndex.DeleteInBatch(batch, item)
+		db.deleteGCInBatch(batch, item)
 		gcSizeChange--
 	case leveldb.ErrNotFound:
 		// the chunk is not accessed before
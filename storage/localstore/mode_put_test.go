@@ -24,7 +24,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/storage/mock"
+	"github.com/ethersphere/swarm/storage/mock/mem"
 	"github.com/syndtr/goleveldb/leveldb"
 )
 
@@ -439,6 +442,98 @@ func TestModePut_addToGcExisting(t *testing.T) {
 	}
 }
 
+// TestModePut_existingNoDataWrite validates that chunk data is written only
+// once, regardless of how many times the same chunk is put, and that exist
+// is reported for every subsequent Put.
+func TestModePut_existingNoDataWrite(t *testing.T) {
+	for _, mode := range []chunk.ModePut{
+		chunk.ModePutRequest,
+		chunk.ModePutSync,
+		chunk.ModePutUpload,
+	} {
+		t.Run(mode.String(), func(t *testing.T) {
+			globalStore := &putCountingGlobalStore{GlobalStore: mem.NewGlobalStore()}
+			db, cleanupFunc := newTestDB(t, &Options{
+				MockStore: mock.NewNodeStore(common.Address{}, globalStore),
+			})
+			defer cleanupFunc()
+
+			ch := generateTestRandomChunk()
+
+			for i := 0; i < 3; i++ {
+				exist, err := db.Put(context.Background(), mode, ch)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if exist[0] != (i > 0) {
+					t.Errorf("put %v: got exist %v, want %v", i, exist[0], i > 0)
+				}
+			}
+
+			if got := globalStore.count(); got != 1 {
+				t.Errorf("got %v chunk data writes, want %v", got, 1)
+			}
+		})
+	}
+}
+
+// putCountingGlobalStore counts Put calls on a mock GlobalStore.
+type putCountingGlobalStore struct {
+	*mem.GlobalStore
+	puts int
+	mu   sync.Mutex
+}
+
+func (s *putCountingGlobalStore) Put(addr common.Address, key []byte, data []byte) error {
+	s.mu.Lock()
+	s.puts++
+	s.mu.Unlock()
+	return s.GlobalStore.Put(addr, key, data)
+}
+
+func (s *putCountingGlobalStore) NewNodeStore(addr common.Address) *mock.NodeStore {
+	return mock.NewNodeStore(addr, s)
+}
+
+func (s *putCountingGlobalStore) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.puts
+}
+
+// TestModePut_existingGCSize validates that gc size is updated
+// when an existing chunk is added to the gc index on Put.
+func TestModePut_existingGCSize(t *testing.T) {
+	for _, mode := range []chunk.ModePut{
+		chunk.ModePutSync,
+		chunk.ModePutUpload,
+	} {
+		t.Run(mode.String(), func(t *testing.T) {
+			putToGC := false
+			db, cleanupFunc := newTestDB(t, &Options{
+				PutToGCCheck: func(_ []byte) bool { return putToGC },
+			})
+			defer cleanupFunc()
+
+			ch := generateTestRandomChunk()
+
+			if _, err := db.Put(context.Background(), mode, ch); err != nil {
+				t.Fatal(err)
+			}
+			newItemsCountTest(db.gcIndex, 0)(t)
+			newIndexGCSizeTest(db)(t)
+
+			putToGC = true
+
+			if _, err := db.Put(context.Background(), mode, ch); err != nil {
+				t.Fatal(err)
+			}
+			newItemsCountTest(db.gcIndex, 1)(t)
+			newIndexGCSizeTest(db)(t)
+		})
+	}
+}
+
 // TestPutDuplicateChunks validates the expected behaviour for
 // passing duplicate chunks to the Put method.
 func TestPutDuplicateChunks(t *testing.T) {
@@ -121,7 +121,7 @@ func (db *DB) set(mode chunk.ModeSet, addrs ...chunk.Address) (err error) {
 		return err
 	}
 
-	err = db.shed.WriteBatch(batch)
+	err = db.writeBatch(batch)
 	if err != nil {
 		return err
 	}
@@ -161,7 +161,7 @@ func (db *DB) setAccess(batch *leveldb.Batch, binIDs map[uint8]uint64, addr chun
 	switch err {
 	case nil:
 		item.AccessTimestamp = i.AccessTimestamp
-		db.gcIndex.DeleteInBatch(batch, item)
+		db.deleteGCInBatch(batch, item)
 		gcSizeChange--
 	case leveldb.ErrNotFound:
 		// the chunk is not accessed before
@@ -290,7 +290,7 @@ func (db *DB) setSync(batch *leveldb.Batch, addr chunk.Address, mode chunk.ModeS
 	switch err {
 	case nil:
 		item.AccessTimestamp = i.AccessTimestamp
-		db.gcIndex.DeleteInBatch(batch, item)
+		db.deleteGCInBatch(batch, item)
 		gcSizeChange--
 	case leveldb.ErrNotFound:
 		// the chunk is not accessed before
@@ -343,11 +343,21 @@ func (db *DB) setRemove(batch *leveldb.Batch, addr chunk.Address) (gcSizeChange
 	db.retrievalDataIndex.DeleteInBatch(batch, item)
 	db.retrievalAccessIndex.DeleteInBatch(batch, item)
 	db.pullIndex.DeleteInBatch(batch, item)
-	db.gcIndex.DeleteInBatch(batch, item)
+	db.deleteGCInBatch(batch, item)
+	if _, err := db.deleteStampInBatch(batch, item); err != nil {
+		return 0, err
+	}
+	if err := db.deleteBlockHeaderInBatch(batch, item); err != nil {
+		return 0, err
+	}
 	// a check is needed for decrementing gcSize
 	// as delete is not reporting if the key/value pair
 	// is deleted or not
-	if _, err := db.gcIndex.Get(item); err == nil {
+	ok, err := db.gcHas(item)
+	if err != nil {
+		return 0, err
+	}
+	if ok {
 		gcSizeChange = -1
 	}
 
@@ -0,0 +1,149 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/shed"
+)
+
+// proximityMetricsInterval is the default period in which
+// proximity histogram metrics are updated.
+var proximityMetricsInterval = 10 * time.Minute
+
+// ProximityHistogram holds the number of stored chunks by proximity
+// order of their addresses to the base key and the estimated storage
+// radius.
+type ProximityHistogram struct {
+	// Bins holds the number of chunks for every proximity order
+	// from 0 to chunk.MaxPO.
+	Bins []uint64 `json:"bins"`
+	// Total is the number of all stored chunks.
+	Total uint64 `json:"total"`
+	// Depth is the proximity order from which chunks are
+	// in the area of responsibility, if it is known.
+	Depth int `json:"depth"`
+	// Radius is the estimated lowest proximity order of chunks
+	// that can be kept in the store without being garbage collected.
+	Radius int `json:"radius"`
+}
+
+// ProximityHistogram iterates over all stored chunks and returns their
+// distribution by proximity order and the estimated storage radius.
+func (db *DB) ProximityHistogram() (h *ProximityHistogram, err error) {
+	h = &ProximityHistogram{
+		Bins: make([]uint64, chunk.MaxPO+1),
+	}
+	// pull index does not include chunks stored with ModePutRequest,
+	// and only keys are needed from the retrieval data index
+	err = db.retrievalDataIndex.IterateKeys(func(item shed.Item) (stop bool, err error) {
+		select {
+		case <-db.close:
+			return true, errClosed
+		default:
+		}
+		h.Bins[db.po(item.Address)]++
+		h.Total++
+		return false, nil
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	h.Depth = db.gcResponsibilityDepth()
+	h.Radius = estimateRadius(h.Bins, h.Depth, db.capacity)
+	return h, nil
+}
+
+// estimateRadius returns the lowest proximity order from which all chunks
+// are expected to fit into the store with the provided capacity.
+// The number of chunks in the whole network is estimated from the chunks
+// with proximity order equal or greater than depth, assuming that the
+// area of responsibility is fully synced and that chunk addresses are
+// uniformly distributed. Every increment of the radius halves the part
+// of the address space that the node needs to store.
+func estimateRadius(bins []uint64, depth int, capacity uint64) (radius int) {
+	if depth > chunk.MaxPO {
+		// the area of responsibility is not known,
+		// only the stored chunks are taken into account
+		depth = 0
+	}
+	var count uint64
+	for po := depth; po < len(bins); po++ {
+		count += bins[po]
+	}
+	for radius = 0; radius < chunk.MaxPO; radius++ {
+		// expected number of chunks with proximity order
+		// equal or greater than radius
+		expected := count
+		if depth > radius {
+			expected = count << uint(depth-radius)
+		} else {
+			expected = count >> uint(radius-depth)
+		}
+		if expected <= capacity {
+			break
+		}
+	}
+	return radius
+}
+
+// proximityMetricsWorker periodically updates proximity histogram
+// and storage radius metrics until the database is closed.
+// Metrics names are prefixed with the database metrics prefix,
+// so that metrics of multiple databases are not overwritten.
+func (db *DB) proximityMetricsWorker() {
+	defer close(db.proximityMetricsWorkerDone)
+
+	if db.proximityMetricsInterval < 0 {
+		return
+	}
+
+	select {
+	case <-db.ready:
+	case <-db.close:
+		return
+	}
+
+	ticker := time.NewTicker(db.proximityMetricsInterval)
+	defer ticker.Stop()
+
+	for {
+		h, err := db.ProximityHistogram()
+		switch err {
+		case nil:
+			for po, count := range h.Bins {
+				metrics.GetOrRegisterGauge(fmt.Sprintf("%slocalstore/proximity/bin/%d", db.metricsPrefix, po), nil).Update(int64(count))
+			}
+			metrics.GetOrRegisterGauge(db.metricsPrefix+"localstore/proximity/radius", nil).Update(int64(h.Radius))
+		case errClosed:
+			return
+		default:
+			log.Error("localstore proximity histogram", "err", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-db.close:
+			return
+		}
+	}
+}
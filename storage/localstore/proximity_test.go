@@ -0,0 +1,139 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethersphere/swarm/chunk"
+)
+
+// TestDB_ProximityHistogram validates the number of
+// chunks in proximity order bins, including chunks
+// that are not in the pull index.
+func TestDB_ProximityHistogram(t *testing.T) {
+	db, cleanupFunc := newTestDB(t, nil)
+	defer cleanupFunc()
+
+	count := 200
+	want := make([]uint64, chunk.MaxPO+1)
+	for i := 0; i < count; i++ {
+		mode := chunk.ModePutUpload
+		if i%2 == 1 {
+			mode = chunk.ModePutRequest
+		}
+		ch := generateTestRandomChunk()
+		_, err := db.Put(context.Background(), mode, ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want[db.po(ch.Address())]++
+	}
+
+	h, err := db.ProximityHistogram()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Total != uint64(count) {
+		t.Errorf("got total %v, want %v", h.Total, count)
+	}
+	for po := range want {
+		if h.Bins[po] != want[po] {
+			t.Errorf("bin %v: got %v chunks, want %v", po, h.Bins[po], want[po])
+		}
+	}
+	if h.Radius != 0 {
+		t.Errorf("got radius %v, want 0", h.Radius)
+	}
+}
+
+// TestDB_proximityMetrics validates that proximity metrics names are
+// prefixed with the metrics prefix and that the metrics can be disabled.
+func TestDB_proximityMetrics(t *testing.T) {
+	t.Run("prefix", func(t *testing.T) {
+		_, cleanupFunc := newTestDB(t, &Options{
+			MetricsPrefix:            "proximitytest/",
+			ProximityMetricsInterval: 10 * time.Millisecond,
+		})
+		defer cleanupFunc()
+
+		name := "proximitytest/localstore/proximity/radius"
+		timeout := time.After(10 * time.Second)
+		for metrics.DefaultRegistry.Get(name) == nil {
+			select {
+			case <-timeout:
+				t.Fatalf("metric %s not registered", name)
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		db, cleanupFunc := newTestDB(t, &Options{
+			ProximityMetricsInterval: -1,
+		})
+		defer cleanupFunc()
+
+		select {
+		case <-db.proximityMetricsWorkerDone:
+		case <-time.After(10 * time.Second):
+			t.Fatal("proximity metrics worker running")
+		}
+	})
+}
+
+// TestEstimateRadius validates storage radius
+// estimation for different capacities and depths.
+func TestEstimateRadius(t *testing.T) {
+	bins := make([]uint64, chunk.MaxPO+1)
+	// 1024 uniformly distributed chunks
+	for po := 0; po < 10; po++ {
+		bins[po] = 512 >> uint(po)
+	}
+	bins[10] = 1
+
+	for _, tc := range []struct {
+		name     string
+		bins     []uint64 // uniformly distributed chunks if nil
+		depth    int
+		capacity uint64
+		want     int
+	}{
+		{name: "all stored", depth: chunk.MaxPO + 1, capacity: 1024, want: 0},
+		{name: "half stored", depth: chunk.MaxPO + 1, capacity: 512, want: 1},
+		{name: "not power of two", depth: chunk.MaxPO + 1, capacity: 300, want: 2},
+		{name: "depth", depth: 2, capacity: 1024, want: 0},
+		{name: "depth half stored", depth: 2, capacity: 128, want: 3},
+		{name: "network larger than stored", depth: 4, capacity: 64, want: 4},
+		{name: "zero capacity", depth: chunk.MaxPO + 1, capacity: 0, want: 11},
+		{name: "no chunks", bins: make([]uint64, chunk.MaxPO+1), depth: chunk.MaxPO + 1, capacity: 0, want: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := tc.bins
+			if b == nil {
+				b = bins
+			}
+			got := estimateRadius(b, tc.depth, tc.capacity)
+			if got != tc.want {
+				t.Errorf("got radius %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
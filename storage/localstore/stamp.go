@@ -0,0 +1,60 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/shed"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// Stamp returns the postage stamp of the chunk with the provided
+// address, or nil if the chunk is not stamped.
+func (db *DB) Stamp(addr chunk.Address) (stamp []byte, err error) {
+	item, err := db.stampIndex.Get(addressToItem(addr))
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return item.Stamp, nil
+}
+
+// setStamp adds the postage stamp of the item to the stamp index if the
+// item is stamped. A stamp of an already stored chunk is replaced.
+// Provided batch is updated.
+func (db *DB) setStamp(batch *leveldb.Batch, item shed.Item) (err error) {
+	if len(item.Stamp) == 0 {
+		return nil
+	}
+	return db.stampIndex.PutInBatch(batch, item)
+}
+
+// deleteStampInBatch removes the postage stamp of the item from the
+// stamp index and reports if the item was stamped.
+// Provided batch is updated.
+func (db *DB) deleteStampInBatch(batch *leveldb.Batch, item shed.Item) (stamped bool, err error) {
+	stamped, err = db.stampIndex.Has(item)
+	if err != nil {
+		return false, err
+	}
+	if stamped {
+		db.stampIndex.DeleteInBatch(batch, item)
+	}
+	return stamped, nil
+}
@@ -0,0 +1,205 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/swarm/chunk"
+)
+
+// TestDB_Stamp validates that postage stamps of chunks are stored,
+// sent with chunks to push subscriptions and removed with chunks.
+func TestDB_Stamp(t *testing.T) {
+	db, cleanupFunc := newTestDB(t, nil)
+	defer cleanupFunc()
+
+	stamp := bytes.Repeat([]byte{1}, chunk.StampSize)
+
+	stamped := generateTestRandomChunk().WithStamp(stamp)
+	unstamped := generateTestRandomChunk()
+	if _, err := db.Put(context.Background(), chunk.ModePutUpload, stamped, unstamped); err != nil {
+		t.Fatal(err)
+	}
+
+	checkStamp := func(t *testing.T, addr chunk.Address, want []byte) {
+		t.Helper()
+		got, err := db.Stamp(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("got stamp %x, want %x", got, want)
+		}
+	}
+
+	checkStamp(t, stamped.Address(), stamp)
+	checkStamp(t, unstamped.Address(), nil)
+
+	t.Run("push subscription", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		c, stop := db.SubscribePush(ctx)
+		defer stop()
+
+		for i := 0; i < 2; i++ {
+			select {
+			case ch := <-c:
+				want := []byte(nil)
+				if bytes.Equal(ch.Address(), stamped.Address()) {
+					want = stamp
+				}
+				if !bytes.Equal(ch.Stamp(), want) {
+					t.Errorf("chunk %s: got stamp %x, want %x", ch.Address(), ch.Stamp(), want)
+				}
+			case <-ctx.Done():
+				t.Fatal(ctx.Err())
+			}
+		}
+	})
+
+	t.Run("stamp existing", func(t *testing.T) {
+		newStamp := bytes.Repeat([]byte{2}, chunk.StampSize)
+		ch := chunk.NewChunk(unstamped.Address(), unstamped.Data()).WithStamp(newStamp)
+		exist, err := db.Put(context.Background(), chunk.ModePutSync, ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !exist[0] {
+			t.Error("chunk not reported as existing")
+		}
+		checkStamp(t, unstamped.Address(), newStamp)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		if err := db.Set(context.Background(), chunk.ModeSetRemove, stamped.Address()); err != nil {
+			t.Fatal(err)
+		}
+		checkStamp(t, stamped.Address(), nil)
+	})
+}
+
+// TestDB_Stamp_gc validates that postage stamps are removed
+// with garbage collected chunks.
+func TestDB_Stamp_gc(t *testing.T) {
+	db, cleanupFunc := newTestDB(t, &Options{
+		Capacity: 100,
+	})
+	testHookCollectGarbageChan := make(chan uint64)
+	defer setTestHookCollectGarbage(func(collectedCount uint64) {
+		select {
+		case testHookCollectGarbageChan <- collectedCount:
+		case <-db.close:
+		}
+	})()
+	defer cleanupFunc()
+
+	stamp := bytes.Repeat([]byte{1}, chunk.StampSize)
+	chunks := generateTestRandomChunks(150)
+	for _, ch := range chunks {
+		if _, err := db.Put(context.Background(), chunk.ModePutRequest, ch.WithStamp(stamp)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gcTarget := db.gcTarget()
+	for {
+		select {
+		case <-testHookCollectGarbageChan:
+		case <-time.After(10 * time.Second):
+			t.Fatal("collect garbage timeout")
+		}
+		gcSize, err := db.gcSize.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gcSize == gcTarget {
+			break
+		}
+	}
+
+	t.Run("stamp index count", newItemsCountTest(db.stampIndex, int(gcTarget)))
+
+	// the first chunk should be garbage collected with its stamp
+	got, err := db.Stamp(chunks[0].Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != nil {
+		t.Errorf("got stamp %x of a garbage collected chunk", got)
+	}
+}
+
+// TestDB_Stamp_gcUnstampedFirst validates that chunks without postage
+// stamps are garbage collected before chunks with them.
+func TestDB_Stamp_gcUnstampedFirst(t *testing.T) {
+	db, cleanupFunc := newTestDB(t, &Options{
+		Capacity: 100,
+	})
+	testHookCollectGarbageChan := make(chan uint64)
+	defer setTestHookCollectGarbage(func(collectedCount uint64) {
+		select {
+		case testHookCollectGarbageChan <- collectedCount:
+		case <-db.close:
+		}
+	})()
+	defer cleanupFunc()
+
+	stamp := bytes.Repeat([]byte{1}, chunk.StampSize)
+	// stamped chunks are stored first to be the least recently accessed
+	stamped := generateTestRandomChunks(75)
+	for _, ch := range stamped {
+		if _, err := db.Put(context.Background(), chunk.ModePutRequest, ch.WithStamp(stamp)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, ch := range generateTestRandomChunks(75) {
+		if _, err := db.Put(context.Background(), chunk.ModePutRequest, ch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gcTarget := db.gcTarget()
+	for {
+		select {
+		case <-testHookCollectGarbageChan:
+		case <-time.After(10 * time.Second):
+			t.Fatal("collect garbage timeout")
+		}
+		gcSize, err := db.gcSize.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gcSize == gcTarget {
+			break
+		}
+	}
+
+	t.Run("gc size", newIndexGCSizeTest(db))
+
+	t.Run("stamp index count", newItemsCountTest(db.stampIndex, len(stamped)))
+
+	for _, ch := range stamped {
+		if _, err := db.Get(context.Background(), chunk.ModeGetLookup, ch.Address()); err != nil {
+			t.Errorf("stamped chunk %s: %v", ch.Address(), err)
+		}
+	}
+}
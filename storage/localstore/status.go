@@ -0,0 +1,106 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import "time"
+
+// statusCountsTTL is the duration for which push and pull index sizes
+// are cached by the Status method, as counting them requires iterating
+// over all of their keys.
+var statusCountsTTL = 10 * time.Second
+
+// Status holds a snapshot of the database state that is cheap
+// enough to be requested periodically by monitoring systems,
+// as index sizes that are expensive to count are cached.
+type Status struct {
+	// Schema is the name of the database schema.
+	Schema string `json:"schema"`
+	// Capacity is the number of chunks the database keeps
+	// before garbage collection starts.
+	Capacity uint64 `json:"capacity"`
+	// GCSize is the number of chunks that are subject
+	// to garbage collection.
+	GCSize uint64 `json:"gcSize"`
+	// PushIndexSize is the number of chunks that are
+	// not yet push synced.
+	PushIndexSize int `json:"pushIndexSize"`
+	// PullIndexSize is the number of chunks that are
+	// available for pull syncing.
+	PullIndexSize int `json:"pullIndexSize"`
+	// Ready is true when gcSize is reliable, after it is
+	// reconstructed if the database was not closed properly.
+	Ready bool `json:"ready"`
+	// Disk holds information about the free disk space.
+	Disk DiskStatus `json:"disk"`
+}
+
+// Status returns the current database status. Push and pull index
+// sizes are counted at most once in statusCountsTTL and may be that
+// old, as counting requires iterating over their keys.
+func (db *DB) Status() (s Status, err error) {
+	s.Schema, err = db.schemaName.Get()
+	if err != nil {
+		return s, err
+	}
+	s.Capacity = db.capacity
+	s.GCSize, err = db.gcSize.Get()
+	if err != nil {
+		return s, err
+	}
+	counts, err := db.indexCounts()
+	if err != nil {
+		return s, err
+	}
+	s.PushIndexSize = counts.push
+	s.PullIndexSize = counts.pull
+	select {
+	case <-db.ready:
+		s.Ready = true
+	default:
+	}
+	s.Disk = db.DiskStatus()
+	return s, nil
+}
+
+// statusCounts holds push and pull index sizes
+// and the time when they were counted.
+type statusCounts struct {
+	push, pull int
+	time       time.Time
+}
+
+// indexCounts returns push and pull index sizes, counting them
+// only if the cached ones are older than statusCountsTTL.
+func (db *DB) indexCounts() (c statusCounts, err error) {
+	db.statusMu.Lock()
+	defer db.statusMu.Unlock()
+
+	if !db.statusCounts.time.IsZero() && time.Since(db.statusCounts.time) < statusCountsTTL {
+		return db.statusCounts, nil
+	}
+	c.push, err = db.pushIndex.Count()
+	if err != nil {
+		return c, err
+	}
+	c.pull, err = db.pullIndex.Count()
+	if err != nil {
+		return c, err
+	}
+	c.time = time.Now()
+	db.statusCounts = c
+	return c, nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package localstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/swarm/chunk"
+)
+
+// TestDB_Status validates that the database status reports
+// push and pull index sizes and gcSize as chunks are uploaded
+// and push synced.
+func TestDB_Status(t *testing.T) {
+	defer func(ttl time.Duration) { statusCountsTTL = ttl }(statusCountsTTL)
+	statusCountsTTL = 0
+
+	db, cleanupFunc := newTestDB(t, &Options{Capacity: 100})
+	defer cleanupFunc()
+
+	checkStatus := func(t *testing.T, push, pull int, gcSize uint64) {
+		t.Helper()
+		s, err := db.Status()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Schema != DbSchemaCurrent {
+			t.Errorf("got schema %q, want %q", s.Schema, DbSchemaCurrent)
+		}
+		if s.Capacity != 100 {
+			t.Errorf("got capacity %v, want %v", s.Capacity, 100)
+		}
+		if !s.Ready {
+			t.Error("database not ready")
+		}
+		if s.PushIndexSize != push {
+			t.Errorf("got push index size %v, want %v", s.PushIndexSize, push)
+		}
+		if s.PullIndexSize != pull {
+			t.Errorf("got pull index size %v, want %v", s.PullIndexSize, pull)
+		}
+		if s.GCSize != gcSize {
+			t.Errorf("got gcSize %v, want %v", s.GCSize, gcSize)
+		}
+	}
+
+	checkStatus(t, 0, 0, 0)
+
+	chunks := make([]chunk.Chunk, 10)
+	for i := range chunks {
+		chunks[i] = generateTestRandomChunk()
+	}
+	if _, err := db.Put(context.Background(), chunk.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+
+	checkStatus(t, 10, 10, 0)
+
+	for _, ch := range chunks[:4] {
+		if err := db.Set(context.Background(), chunk.ModeSetSyncPush, ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checkStatus(t, 6, 10, 4)
+}
+
+// TestDB_Status_cachedCounts validates that push and pull index
+// sizes are not counted again before statusCountsTTL passes.
+func TestDB_Status_cachedCounts(t *testing.T) {
+	defer func(ttl time.Duration) { statusCountsTTL = ttl }(statusCountsTTL)
+	statusCountsTTL = time.Hour
+
+	db, cleanupFunc := newTestDB(t, nil)
+	defer cleanupFunc()
+
+	if _, err := db.Status(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Put(context.Background(), chunk.ModePutUpload, generateTestRandomChunk()); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := db.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.PushIndexSize != 0 {
+		t.Errorf("got cached push index size %v, want %v", s.PushIndexSize, 0)
+	}
+
+	db.statusMu.Lock()
+	db.statusCounts.time = time.Now().Add(-time.Hour)
+	db.statusMu.Unlock()
+
+	s, err = db.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.PushIndexSize != 1 {
+		t.Errorf("got push index size %v, want %v", s.PushIndexSize, 1)
+	}
+	if s.PullIndexSize != 1 {
+		t.Errorf("got pull index size %v, want %v", s.PullIndexSize, 1)
+	}
+}
@@ -76,9 +76,14 @@ func (db *DB) SubscribePush(ctx context.Context) (c <-chan chunk.Chunk, stop fun
 					if err != nil {
 						return true, err
 					}
+					// get the postage stamp to be sent with the chunk
+					stamp, err := db.Stamp(item.Address)
+					if err != nil {
+						return true, err
+					}
 
 					select {
-					case chunks <- chunk.NewChunk(dataItem.Address, dataItem.Data).WithTagID(item.Tag):
+					case chunks <- chunk.NewChunk(dataItem.Address, dataItem.Data).WithTagID(item.Tag).WithStamp(stamp):
 						count++
 						// set next iteration start item
 						// when its chunk is successfully sent to channel
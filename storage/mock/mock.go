@@ -22,7 +22,8 @@
 //
 //  - db - LevelDB backend
 //  - mem - in memory map backend
-//  - rpc - RPC client that can connect to other backends
+//  - rpc - RPC client that can connect to other backends and
+//          RPC service that exposes them with streaming of keys
 //
 // Mock storages can implement Importer and Exporter interfaces
 // for importing and exporting all chunk data that they contain.
@@ -0,0 +1,102 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// NewAuthHandler returns a handler that passes requests to the handler h
+// only if they are authenticated with the token. The token can be provided
+// as a bearer token or as the password of the basic authentication, which
+// is set by RPC websocket client from the endpoint URL user information.
+// If token is empty, requests are not authenticated.
+func NewAuthHandler(h http.Handler, token string) http.Handler {
+	if token == "" {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r, token) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mockStore"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// authorized returns true if the request contains the token
+// in the Authorization header.
+func authorized(r *http.Request, token string) bool {
+	var got string
+	if _, password, ok := r.BasicAuth(); ok {
+		got = password
+	} else {
+		auth := r.Header.Get("Authorization")
+		const prefix = "Bearer "
+		if !strings.HasPrefix(auth, prefix) {
+			return false
+		}
+		got = auth[len(prefix):]
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+// Dial connects to a mock store RPC server on the endpoint. If the endpoint
+// URL contains user information, its password is used as the authentication
+// token, for example ws://:secret@localhost:3033.
+func Dial(ctx context.Context, endpoint string) (*rpc.Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.User == nil {
+		return rpc.DialContext(ctx, endpoint)
+	}
+	switch u.Scheme {
+	case "http", "https":
+		token, _ := u.User.Password()
+		u.User = nil
+		return rpc.DialHTTPWithClient(u.String(), &http.Client{
+			Transport: &authTransport{
+				token: token,
+				base:  http.DefaultTransport,
+			},
+		})
+	default:
+		// websocket client sends user information as basic authentication
+		return rpc.DialContext(ctx, endpoint)
+	}
+}
+
+// authTransport sets the bearer token on all requests.
+type authTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", "Bearer "+t.token)
+	return t.base.RoundTrip(r)
+}
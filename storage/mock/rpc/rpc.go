@@ -21,9 +21,17 @@
 //
 //   server := rpc.NewServer()
 //   server.RegisterName("mockStore", mem.NewGlobalStore())
+//
+// Mock stores registered with NewService additionally support streaming of
+// key listings. Servers can require authentication with NewAuthHandler and
+// clients can provide the token in the endpoint URL passed to Dial.
+// Batch methods of the GlobalStore send many operations in a single RPC
+// request to reduce the overhead with many simulated nodes.
 package rpc
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -112,3 +120,172 @@ func (s *GlobalStore) KeyNodes(key []byte, startAddr *common.Address, limit int)
 	err = s.client.Call(&nodes, "mockStore_keyNodes", key, startAddr, limit)
 	return nodes, err
 }
+
+// maxBatchSize is the maximal number of calls sent in a single batch request.
+// It keeps requests with full chunks under the RPC HTTP request size limit.
+var maxBatchSize = 500
+
+// BatchItem is a key and chunk data pair used by PutBatch.
+type BatchItem struct {
+	Key  []byte
+	Data []byte
+}
+
+// PutBatch saves chunk data for multiple keys for a node with address addr
+// in batch RPC requests.
+func (s *GlobalStore) PutBatch(addr common.Address, items ...BatchItem) error {
+	batch := make([]rpc.BatchElem, len(items))
+	for i, item := range items {
+		batch[i] = rpc.BatchElem{
+			Method: "mockStore_put",
+			Args:   []interface{}{addr, item.Key, item.Data},
+			Result: new(interface{}),
+		}
+	}
+	return s.batchCall(batch)
+}
+
+// GetBatch returns chunk data for multiple keys for a node with address addr
+// in batch RPC requests. Data for keys that are not found is nil.
+func (s *GlobalStore) GetBatch(addr common.Address, keys ...[]byte) (data [][]byte, err error) {
+	data = make([][]byte, len(keys))
+	batch := make([]rpc.BatchElem, len(keys))
+	for i, key := range keys {
+		batch[i] = rpc.BatchElem{
+			Method: "mockStore_get",
+			Args:   []interface{}{addr, key},
+			Result: &data[i],
+		}
+	}
+	if err := s.batchCallFunc(batch, func(i int, err error) error {
+		if err.Error() == mock.ErrNotFound.Error() {
+			data[i] = nil
+			return nil
+		}
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// DeleteBatch removes chunk references for multiple keys for a node with
+// address addr in batch RPC requests.
+func (s *GlobalStore) DeleteBatch(addr common.Address, keys ...[]byte) error {
+	batch := make([]rpc.BatchElem, len(keys))
+	for i, key := range keys {
+		batch[i] = rpc.BatchElem{
+			Method: "mockStore_delete",
+			Args:   []interface{}{addr, key},
+			Result: new(interface{}),
+		}
+	}
+	return s.batchCall(batch)
+}
+
+// HasKeyBatch returns whether a node with address addr contains keys
+// in batch RPC requests.
+func (s *GlobalStore) HasKeyBatch(addr common.Address, keys ...[]byte) (has []bool, err error) {
+	has = make([]bool, len(keys))
+	batch := make([]rpc.BatchElem, len(keys))
+	for i, key := range keys {
+		batch[i] = rpc.BatchElem{
+			Method: "mockStore_hasKey",
+			Args:   []interface{}{addr, key},
+			Result: &has[i],
+		}
+	}
+	if err := s.batchCall(batch); err != nil {
+		return nil, err
+	}
+	return has, nil
+}
+
+// batchCall sends calls in batches of maxBatchSize and returns
+// the first error of any call.
+func (s *GlobalStore) batchCall(batch []rpc.BatchElem) error {
+	return s.batchCallFunc(batch, func(_ int, err error) error {
+		return err
+	})
+}
+
+// batchCallFunc sends calls in batches of maxBatchSize. Errors of individual
+// calls are passed to errFunc with the call index and no more calls are
+// sent if it returns a non-nil error.
+func (s *GlobalStore) batchCallFunc(batch []rpc.BatchElem, errFunc func(i int, err error) error) error {
+	for start := 0; start < len(batch); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(batch) {
+			end = len(batch)
+		}
+		if err := s.client.BatchCall(batch[start:end]); err != nil {
+			return err
+		}
+		for i := start; i < end; i++ {
+			if batch[i].Error == nil {
+				continue
+			}
+			if err := errFunc(i, batch[i].Error); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// IterateKeys calls fn for every key on all nodes, starting from startKey,
+// in the order of Keys method. Keys are streamed by the server in pages, which
+// requires a server with a Service registered and a connection that supports
+// subscriptions, like websocket. Iteration stops when fn returns true or
+// an error.
+func (s *GlobalStore) IterateKeys(ctx context.Context, startKey []byte, fn func(key []byte) (stop bool, err error)) error {
+	return s.iterateKeys(ctx, fn, "streamKeys", startKey)
+}
+
+// IterateNodeKeys calls fn for every key on a node with provided address,
+// starting from startKey, in the same way as IterateKeys.
+func (s *GlobalStore) IterateNodeKeys(ctx context.Context, addr common.Address, startKey []byte, fn func(key []byte) (stop bool, err error)) error {
+	return s.iterateKeys(ctx, fn, "streamNodeKeys", addr, startKey)
+}
+
+// iterateKeys subscribes to a key streaming subscription with
+// provided arguments and calls fn for every received key.
+func (s *GlobalStore) iterateKeys(ctx context.Context, fn func(key []byte) (stop bool, err error), args ...interface{}) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pages := make(chan keysPage)
+	sub, err := s.client.Subscribe(ctx, "mockStore", pages, args...)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case page := <-pages:
+			if page.Error != "" {
+				return errors.New(page.Error)
+			}
+			for _, key := range page.Keys {
+				stop, err := fn(key)
+				if err != nil {
+					return err
+				}
+				if stop {
+					return nil
+				}
+			}
+			if page.Next == nil {
+				return nil
+			}
+		case err := <-sub.Err():
+			if err == nil {
+				err = errors.New("key stream closed")
+			}
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -17,9 +17,16 @@
 package rpc
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethersphere/swarm/storage/mock"
 	"github.com/ethersphere/swarm/storage/mock/mem"
 	"github.com/ethersphere/swarm/storage/mock/test"
 )
@@ -42,6 +49,226 @@ func TestRPCStoreListings(t *testing.T) {
 	test.MockStoreListings(t, store, 1000)
 }
 
+// TestRPCServiceStore is running test for a GlobalStore
+// connected to a Service using test.MockStore function.
+func TestRPCServiceStore(t *testing.T) {
+	store, cleanup := newTestServiceStore(t)
+	defer cleanup()
+
+	test.MockStore(t, store, 30)
+}
+
+// TestRPCServiceStoreListings is running test for a GlobalStore
+// connected to a Service using test.MockStoreListings function.
+func TestRPCServiceStoreListings(t *testing.T) {
+	store, cleanup := newTestServiceStore(t)
+	defer cleanup()
+
+	test.MockStoreListings(t, store, 1000)
+}
+
+// TestRPCStoreBatch validates batch methods of the GlobalStore
+// with more items than the maximal batch size.
+func TestRPCStoreBatch(t *testing.T) {
+	defer func(s int) { maxBatchSize = s }(maxBatchSize)
+	maxBatchSize = 7
+
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	addr := common.HexToAddress("0x1234")
+	items := make([]BatchItem, 30)
+	keys := make([][]byte, len(items))
+	for i := range items {
+		items[i] = BatchItem{
+			Key:  []byte(fmt.Sprintf("key-%v", i)),
+			Data: []byte(fmt.Sprintf("data-%v", i)),
+		}
+		keys[i] = items[i].Key
+	}
+
+	if err := store.PutBatch(addr, items...); err != nil {
+		t.Fatal(err)
+	}
+
+	missingKey := []byte("missing")
+	data, err := store.GetBatch(addr, append(keys, missingKey)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, item := range items {
+		if !bytes.Equal(data[i], item.Data) {
+			t.Errorf("got data %q for key %q, want %q", data[i], item.Key, item.Data)
+		}
+	}
+	if data[len(items)] != nil {
+		t.Errorf("got data %q for missing key", data[len(items)])
+	}
+
+	if err := store.DeleteBatch(addr, keys[:10]...); err != nil {
+		t.Fatal(err)
+	}
+	has, err := store.HasKeyBatch(addr, keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range keys {
+		if want := i >= 10; has[i] != want {
+			t.Errorf("got has %v for key %q, want %v", has[i], keys[i], want)
+		}
+	}
+}
+
+// TestRPCStoreIterateKeys validates that streamed keys are
+// the same as keys returned by paginated listings.
+func TestRPCStoreIterateKeys(t *testing.T) {
+	store, cleanup := newTestServiceStore(t)
+	defer cleanup()
+
+	addrs := []common.Address{common.HexToAddress("0x1"), common.HexToAddress("0x2")}
+	for i := 0; i < 2*mock.MaxLimit+10; i++ {
+		addr := addrs[i%len(addrs)]
+		if err := store.Put(addr, []byte(fmt.Sprintf("key-%05d", i)), []byte("data")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	collect := func(list func(startKey []byte, limit int) (mock.Keys, error)) (keys [][]byte) {
+		var start []byte
+		for {
+			page, err := list(start, mock.MaxLimit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			keys = append(keys, page.Keys...)
+			if page.Next == nil {
+				return keys
+			}
+			start = page.Next
+		}
+	}
+	check := func(got, want [][]byte) {
+		t.Helper()
+
+		if len(got) != len(want) {
+			t.Fatalf("got %v keys, want %v", len(got), len(want))
+		}
+		for i := range want {
+			if !bytes.Equal(got[i], want[i]) {
+				t.Fatalf("got key %q at %v, want %q", got[i], i, want[i])
+			}
+		}
+	}
+
+	var got [][]byte
+	err := store.IterateKeys(context.Background(), nil, func(key []byte) (bool, error) {
+		got = append(got, key)
+		return false, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(got, collect(store.Keys))
+
+	got = nil
+	err = store.IterateNodeKeys(context.Background(), addrs[1], nil, func(key []byte) (bool, error) {
+		got = append(got, key)
+		return false, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(got, collect(func(startKey []byte, limit int) (mock.Keys, error) {
+		return store.NodeKeys(addrs[1], startKey, limit)
+	}))
+
+	// stop the iteration early
+	var count int
+	err = store.IterateKeys(context.Background(), nil, func(key []byte) (bool, error) {
+		count++
+		return count == 5, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 5 {
+		t.Errorf("got %v iterated keys, want 5", count)
+	}
+}
+
+// TestAuthHandler validates that only clients with the correct
+// token can access the mock store over HTTP and websocket.
+func TestAuthHandler(t *testing.T) {
+	server := rpc.NewServer()
+	if err := server.RegisterName("mockStore", NewService(mem.NewGlobalStore())); err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewServer(NewAuthHandler(server, "secret"))
+	defer httpServer.Close()
+	wsServer := httptest.NewServer(NewAuthHandler(server.WebsocketHandler(nil), "secret"))
+	defer wsServer.Close()
+
+	httpURL := httpServer.URL
+	wsURL := "ws" + strings.TrimPrefix(wsServer.URL, "http")
+
+	for _, tc := range []struct {
+		name     string
+		endpoint string
+		token    string
+		wantErr  bool
+	}{
+		{name: "http", endpoint: httpURL, token: "secret"},
+		{name: "http wrong token", endpoint: httpURL, token: "wrong", wantErr: true},
+		{name: "http no token", endpoint: httpURL, wantErr: true},
+		{name: "websocket", endpoint: wsURL, token: "secret"},
+		{name: "websocket wrong token", endpoint: wsURL, token: "wrong", wantErr: true},
+		{name: "websocket no token", endpoint: wsURL, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint := tc.endpoint
+			if tc.token != "" {
+				endpoint = strings.Replace(endpoint, "://", "://:"+tc.token+"@", 1)
+			}
+			client, err := Dial(context.Background(), endpoint)
+			if err != nil {
+				if tc.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			store := NewGlobalStore(client)
+			defer store.Close()
+
+			err = store.Put(common.HexToAddress("0x1"), []byte("key"), []byte("data"))
+			if tc.wantErr && err == nil {
+				t.Fatal("expected error")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// newTestServiceStore creates a temporary GlobalStore connected
+// to a Service that will be closed when returned cleanup function
+// is called.
+func newTestServiceStore(t *testing.T) (s *GlobalStore, cleanup func()) {
+	t.Helper()
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("mockStore", NewService(mem.NewGlobalStore())); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewGlobalStore(rpc.DialInProc(server))
+	return store, func() {
+		if err := store.Close(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
 // newTestStore creates a temporary GlobalStore
 // that will be closed when returned cleanup function
 // is called.
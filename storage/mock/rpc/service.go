@@ -0,0 +1,147 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethersphere/swarm/log"
+	"github.com/ethersphere/swarm/storage/mock"
+)
+
+// Service exposes a mock store over RPC. In addition to methods defined by
+// mock.GlobalStorer, it provides subscriptions that stream keys in pages,
+// so that clients do not need to request each page separately.
+// It should be registered to RPC server under mockStore name:
+//
+//   server := rpc.NewServer()
+//   server.RegisterName("mockStore", NewService(mem.NewGlobalStore()))
+type Service struct {
+	store mock.GlobalStorer
+}
+
+// NewService creates a new instance of Service for the provided mock store.
+func NewService(store mock.GlobalStorer) *Service {
+	return &Service{
+		store: store,
+	}
+}
+
+// Get returns chunk data if the chunk with key exists for node
+// on address addr.
+func (s *Service) Get(addr common.Address, key []byte) (data []byte, err error) {
+	return s.store.Get(addr, key)
+}
+
+// Put saves the chunk data for node with address addr.
+func (s *Service) Put(addr common.Address, key []byte, data []byte) error {
+	return s.store.Put(addr, key, data)
+}
+
+// Delete removes the chunk reference to node with address addr.
+func (s *Service) Delete(addr common.Address, key []byte) error {
+	return s.store.Delete(addr, key)
+}
+
+// HasKey returns whether a node with addr contains the key.
+func (s *Service) HasKey(addr common.Address, key []byte) bool {
+	return s.store.HasKey(addr, key)
+}
+
+// Keys returns a paginated list of keys on all nodes.
+func (s *Service) Keys(startKey []byte, limit int) (keys mock.Keys, err error) {
+	return s.store.Keys(startKey, limit)
+}
+
+// Nodes returns a paginated list of all known nodes.
+func (s *Service) Nodes(startAddr *common.Address, limit int) (nodes mock.Nodes, err error) {
+	return s.store.Nodes(startAddr, limit)
+}
+
+// NodeKeys returns a paginated list of keys on a node with provided address.
+func (s *Service) NodeKeys(addr common.Address, startKey []byte, limit int) (keys mock.Keys, err error) {
+	return s.store.NodeKeys(addr, startKey, limit)
+}
+
+// KeyNodes returns a paginated list of nodes that contain a particular key.
+func (s *Service) KeyNodes(key []byte, startAddr *common.Address, limit int) (nodes mock.Nodes, err error) {
+	return s.store.KeyNodes(key, startAddr, limit)
+}
+
+// keysPage is a notification sent by key streaming subscriptions.
+// The last page of the stream has nil Next field. If listing fails,
+// Error field is set and the stream is ended.
+type keysPage struct {
+	Keys  [][]byte
+	Next  []byte
+	Error string
+}
+
+// StreamKeys is a subscription that sends pages of keys on all nodes,
+// starting from startKey, until all keys are sent.
+func (s *Service) StreamKeys(ctx context.Context, startKey []byte) (*rpc.Subscription, error) {
+	return s.streamKeys(ctx, startKey, s.store.Keys)
+}
+
+// StreamNodeKeys is a subscription that sends pages of keys on a node with
+// provided address, starting from startKey, until all keys are sent.
+func (s *Service) StreamNodeKeys(ctx context.Context, addr common.Address, startKey []byte) (*rpc.Subscription, error) {
+	return s.streamKeys(ctx, startKey, func(startKey []byte, limit int) (mock.Keys, error) {
+		return s.store.NodeKeys(addr, startKey, limit)
+	})
+}
+
+// streamKeys creates a subscription that sends pages returned by list function.
+func (s *Service) streamKeys(ctx context.Context, startKey []byte, list func(startKey []byte, limit int) (mock.Keys, error)) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+
+	go func() {
+		start := startKey
+		for {
+			select {
+			case <-sub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			default:
+			}
+			var page keysPage
+			keys, err := list(start, mock.MaxLimit)
+			if err != nil {
+				page.Error = err.Error()
+			} else {
+				page.Keys = keys.Keys
+				page.Next = keys.Next
+			}
+			if err := notifier.Notify(sub.ID, page); err != nil {
+				log.Error("mock store: stream keys notify", "err", err)
+				return
+			}
+			if page.Next == nil {
+				return
+			}
+			start = page.Next
+		}
+	}()
+	return sub, nil
+}
@@ -63,6 +63,8 @@ type Fetcher struct {
 	CreatedBy string    // who created the fetcher - "request" or "syncing", used for metrics measuring lifecycle of fetchers
 
 	RequestedBySyncer bool // whether we have issued at least once a request through Offered/Wanted hashes flow
+
+	refs int // number of parties interested in the fetcher, guarded by NetStore.putMu
 }
 
 // NewFetcher is a constructor for a Fetcher
@@ -88,17 +90,37 @@ func (fi *Fetcher) SafeClose(ch chunk.Chunk) {
 
 type RemoteGetFunc func(ctx context.Context, req *Request, localID enode.ID) (*enode.ID, func(), error)
 
+// RecoverFunc requests a chunk that could not be retrieved from the network
+// to be delivered by other means. The chunk is expected to be put in the
+// NetStore once it is received.
+type RecoverFunc func(ctx context.Context, addr Address) error
+
 // NetStore is an extension of LocalStore
 // it implements the ChunkStore interface
 // on request it initiates remote cloud retrieval
 type NetStore struct {
 	chunk.Store
-	LocalID      enode.ID // our local enode - used when issuing RetrieveRequests
-	fetchers     *lru.Cache
-	putMu        sync.Mutex
-	requestGroup singleflight.Group
-	RemoteGet    RemoteGetFunc
-	logger       log.Logger
+	LocalID        enode.ID      // our local enode - used when issuing RetrieveRequests
+	FetcherTimeout time.Duration // max time a chunk is searched for on the network
+	SearchTimeout  time.Duration // max time to wait for a single peer to deliver a chunk before trying another one
+	fetchers       *lru.Cache
+	putMu          sync.Mutex
+	requestGroup   singleflight.Group
+	requests       map[string]*fetchRequest // in-flight network fetches, guarded by requestsMu
+	requestsMu     sync.Mutex
+	RemoteGet      RemoteGetFunc
+	Recover        RecoverFunc // optional, called when RemoteFetch fails before FetcherTimeout
+	logger         log.Logger
+}
+
+// fetchRequest tracks the callers of NetStore.Get waiting for the same chunk,
+// so that the shared network fetch can be cancelled as soon as none of them
+// is interested in the result anymore.
+type fetchRequest struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	req     *Request // request for the network fetch, with the least restrictive limits of all callers
+	waiters int
 }
 
 // NewNetStore creates a new NetStore using the provided chunk.Store and localID of the node.
@@ -106,10 +128,13 @@ func NewNetStore(store chunk.Store, baseAddr *network.BzzAddr) *NetStore {
 	fetchers, _ := lru.New(fetchersCapacity)
 
 	return &NetStore{
-		fetchers: fetchers,
-		Store:    store,
-		LocalID:  baseAddr.ID(),
-		logger:   log.NewBaseAddressLogger(baseAddr.ShortString()),
+		fetchers:       fetchers,
+		Store:          store,
+		LocalID:        baseAddr.ID(),
+		FetcherTimeout: timeouts.FetcherGlobalTimeout,
+		SearchTimeout:  timeouts.SearchTimeout,
+		requests:       make(map[string]*fetchRequest),
+		logger:         log.NewBaseAddressLogger(baseAddr.ShortString()),
 	}
 }
 
@@ -167,6 +192,8 @@ func (n *NetStore) Close() error {
 
 // Get retrieves a chunk
 // If it is not found in the LocalStore then it uses RemoteGet to fetch from the network.
+// Concurrent requests for the same chunk share a single network fetch, which is limited
+// by FetcherTimeout and cancelled as soon as all callers waiting on it have returned.
 func (n *NetStore) Get(ctx context.Context, mode chunk.ModeGet, req *Request) (ch Chunk, err error) {
 	metrics.GetOrRegisterCounter("netstore/get", nil).Inc(1)
 	start := time.Now()
@@ -182,7 +209,11 @@ func (n *NetStore) Get(ctx context.Context, mode chunk.ModeGet, req *Request) (c
 
 		n.logger.Trace("netstore.chunk-not-in-localstore", "ref", ref.String())
 
-		v, err, _ := n.requestGroup.Do(ref.String(), func() (interface{}, error) {
+		key := ref.String()
+		fr := n.joinFetch(key, req)
+		defer n.leaveFetch(key, fr)
+
+		resC := n.requestGroup.DoChan(key, func() (interface{}, error) {
 			// currently we issue a retrieve request if a fetcher
 			// has already been created by a syncer for that particular chunk.
 			// so it is possible to
@@ -191,28 +222,40 @@ func (n *NetStore) Get(ctx context.Context, mode chunk.ModeGet, req *Request) (c
 			// here - retrieve request
 			fi, _, ok := n.GetOrCreateFetcher(ctx, ref, "request")
 			if ok {
-				ch, err = n.RemoteFetch(ctx, req, fi)
+				defer n.ReleaseFetcher(ref, fi)
+
+				// the fetch is bound to the context and the request limits shared by all callers
+				// waiting for this chunk and not to the ones of the caller that happened to start it
+				ch, err := n.RemoteFetch(fr.ctx, fr.req, fi)
+				if err != nil && n.Recover != nil && fr.ctx.Err() == nil {
+					ch, err = n.recover(fr.ctx, ref, fi)
+				}
 				if err != nil {
 					return nil, err
 				}
-			}
-
-			// fi could be nil (when ok == false) if the chunk was added to the NetStore between n.store.Get and the call to n.GetOrCreateFetcher
-			if fi != nil {
 				metrics.GetOrRegisterResettingTimer(fmt.Sprintf("fetcher/%s/request", fi.CreatedBy), nil).UpdateSince(start)
+				return ch, nil
 			}
 
-			return ch, nil
+			// the chunk was added to the NetStore between n.store.Get and the call to n.GetOrCreateFetcher
+			return n.Store.Get(fr.ctx, mode, ref)
 		})
 
-		if err != nil {
-			n.logger.Trace(err.Error(), "ref", ref)
-			return nil, err
-		}
+		select {
+		case res := <-resC:
+			if res.Err != nil {
+				n.logger.Trace(res.Err.Error(), "ref", ref)
+				return nil, res.Err
+			}
 
-		n.logger.Trace("netstore.singleflight returned", "ref", ref.String(), "err", err)
+			n.logger.Trace("netstore.singleflight returned", "ref", ref.String())
 
-		return v.(Chunk), nil
+			return res.Val.(Chunk), nil
+		case <-ctx.Done():
+			metrics.GetOrRegisterCounter("netstore/get/cancelled", nil).Inc(1)
+			n.logger.Trace("netstore.get cancelled", "ref", ref.String(), "err", ctx.Err())
+			return nil, ctx.Err()
+		}
 	}
 	n.logger.Trace("netstore.get returned", "ref", ref.String())
 
@@ -224,10 +267,58 @@ func (n *NetStore) Get(ctx context.Context, mode chunk.ModeGet, req *Request) (c
 	return ch, nil
 }
 
+// joinFetch registers a caller interested in the network fetch for the chunk with the given key
+// and returns the fetch request shared by all such callers. The hop limit and the deadline of
+// the shared request are extended to satisfy the caller request.
+func (n *NetStore) joinFetch(key string, req *Request) *fetchRequest {
+	n.requestsMu.Lock()
+	defer n.requestsMu.Unlock()
+
+	fr, ok := n.requests[key]
+	if !ok {
+		ctx, cancel := context.WithTimeout(context.Background(), n.FetcherTimeout)
+		fr = &fetchRequest{
+			ctx:    ctx,
+			cancel: cancel,
+			req: &Request{
+				Addr:     req.Addr,
+				Origin:   req.Origin,
+				HopLimit: req.HopLimit,
+				Deadline: req.Deadline,
+			},
+		}
+		n.requests[key] = fr
+	} else {
+		fr.req.extendLimits(req.HopLimit, req.Deadline)
+	}
+	fr.waiters++
+	return fr
+}
+
+// leaveFetch unregisters a caller from the fetch request. When the last caller leaves,
+// the network fetch is cancelled, so that in-flight retrieve requests and the fetcher are freed
+// without waiting for the timeouts to fire.
+func (n *NetStore) leaveFetch(key string, fr *fetchRequest) {
+	n.requestsMu.Lock()
+	defer n.requestsMu.Unlock()
+
+	fr.waiters--
+	if fr.waiters > 0 {
+		return
+	}
+	fr.cancel()
+	if n.requests[key] == fr {
+		delete(n.requests, key)
+		// new callers must not join the cancelled fetch
+		n.requestGroup.Forget(key)
+	}
+}
+
 // RemoteFetch is handling the retry mechanism when making a chunk request to our peers.
 // For a given chunk Request, we call RemoteGet, which selects the next eligible peer and
 // issues a RetrieveRequest and we wait for a delivery. If a delivery doesn't arrive within the SearchTimeout
-// we retry.
+// we retry. All retrieve requests issued are cleaned up when RemoteFetch returns, which happens
+// at the latest when ctx is done.
 func (n *NetStore) RemoteFetch(ctx context.Context, req *Request, fi *Fetcher) (chunk.Chunk, error) {
 	// while we haven't timed-out, and while we don't have a chunk,
 	// iterate over peers and try to find a chunk
@@ -235,6 +326,9 @@ func (n *NetStore) RemoteFetch(ctx context.Context, req *Request, fi *Fetcher) (
 
 	ref := req.Addr
 
+	searchTimer := time.NewTimer(n.SearchTimeout)
+	defer searchTimer.Stop()
+
 	for {
 		metrics.GetOrRegisterCounter("remote/fetch/inner", nil).Inc(1)
 
@@ -260,6 +354,14 @@ func (n *NetStore) RemoteFetch(ctx context.Context, req *Request, fi *Fetcher) (
 		n.logger.Trace("remote.fetch, adding peer to skip", "ref", ref, "peer", currentPeer.String())
 		req.PeersToSkip.Store(currentPeer.String(), time.Now())
 
+		if !searchTimer.Stop() {
+			select {
+			case <-searchTimer.C:
+			default:
+			}
+		}
+		searchTimer.Reset(n.SearchTimeout)
+
 		select {
 		case <-fi.Delivered:
 			n.logger.Trace("remote.fetch, chunk delivered", "ref", ref, "base", hex.EncodeToString(n.LocalID[:16]))
@@ -267,7 +369,7 @@ func (n *NetStore) RemoteFetch(ctx context.Context, req *Request, fi *Fetcher) (
 			osp.LogFields(olog.Bool("delivered", true))
 			osp.Finish()
 			return fi.Chunk, nil
-		case <-time.After(timeouts.SearchTimeout):
+		case <-searchTimer.C:
 			metrics.GetOrRegisterCounter("remote/fetch/timeout/search", nil).Inc(1)
 
 			osp.LogFields(olog.Bool("timeout", true))
@@ -284,6 +386,28 @@ func (n *NetStore) RemoteFetch(ctx context.Context, req *Request, fi *Fetcher) (
 	}
 }
 
+// recover calls the Recover function for the chunk that could not be fetched
+// from peers and waits for the chunk to be delivered until ctx is done.
+func (n *NetStore) recover(ctx context.Context, ref Address, fi *Fetcher) (chunk.Chunk, error) {
+	metrics.GetOrRegisterCounter("netstore/recover", nil).Inc(1)
+
+	n.logger.Trace("netstore.recover", "ref", ref)
+	if err := n.Recover(ctx, ref); err != nil {
+		metrics.GetOrRegisterCounter("netstore/recover/fail", nil).Inc(1)
+		return nil, err
+	}
+
+	select {
+	case <-fi.Delivered:
+		metrics.GetOrRegisterCounter("netstore/recover/delivered", nil).Inc(1)
+		n.logger.Trace("netstore.recover, chunk delivered", "ref", ref)
+		return fi.Chunk, nil
+	case <-ctx.Done():
+		metrics.GetOrRegisterCounter("netstore/recover/timeout", nil).Inc(1)
+		return nil, ctx.Err()
+	}
+}
+
 // Has is the storage layer entry point to query the underlying
 // database to return if it has a chunk or not.
 func (n *NetStore) Has(ctx context.Context, ref Address) (bool, error) {
@@ -292,6 +416,8 @@ func (n *NetStore) Has(ctx context.Context, ref Address) (bool, error) {
 
 // GetOrCreateFetcher returns the Fetcher for a given chunk, if this chunk is not in the LocalStore.
 // If the chunk is in the LocalStore, it returns nil for the Fetcher and ok == false
+// Every returned Fetcher must be released with ReleaseFetcher once the caller is not interested
+// in the delivery anymore.
 func (n *NetStore) GetOrCreateFetcher(ctx context.Context, ref Address, interestedParty string) (f *Fetcher, loaded bool, ok bool) {
 	n.putMu.Lock()
 	defer n.putMu.Unlock()
@@ -305,6 +431,7 @@ func (n *NetStore) GetOrCreateFetcher(ctx context.Context, ref Address, interest
 		f.CreatedBy = interestedParty
 		n.fetchers.Add(ref.String(), f)
 	}
+	f.refs++
 
 	// if fetcher created by request, but we get a call from syncer, make sure we issue a second request
 	if f.CreatedBy != interestedParty && !f.RequestedBySyncer {
@@ -314,3 +441,27 @@ func (n *NetStore) GetOrCreateFetcher(ctx context.Context, ref Address, interest
 
 	return f, loaded, true
 }
+
+// ReleaseFetcher is called by a party that obtained the Fetcher through GetOrCreateFetcher
+// when it is no longer interested in the chunk delivery. When the last interested party
+// releases an undelivered Fetcher, it is removed from the fetchers cache.
+func (n *NetStore) ReleaseFetcher(ref Address, f *Fetcher) {
+	n.putMu.Lock()
+	defer n.putMu.Unlock()
+
+	f.refs--
+	if f.refs > 0 {
+		return
+	}
+	v, ok := n.fetchers.Peek(ref.String())
+	if !ok || v.(*Fetcher) != f {
+		return
+	}
+	select {
+	case <-f.Delivered:
+		// delivered fetchers are removed by Put
+	default:
+		metrics.GetOrRegisterCounter("netstore/fetcher/released", nil).Inc(1)
+		n.fetchers.Remove(ref.String())
+	}
+}
@@ -0,0 +1,389 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/network"
+)
+
+// testRemoteGet is a RemoteGetFunc that records issued requests and
+// their cleanups, without delivering anything.
+type testRemoteGet struct {
+	mu       sync.Mutex
+	requests int
+	cleanups int
+	called   chan struct{}
+}
+
+func newTestRemoteGet() *testRemoteGet {
+	return &testRemoteGet{
+		called: make(chan struct{}, 100),
+	}
+}
+
+func (r *testRemoteGet) RemoteGet(ctx context.Context, req *Request, localID enode.ID) (*enode.ID, func(), error) {
+	r.mu.Lock()
+	r.requests++
+	r.mu.Unlock()
+
+	r.called <- struct{}{}
+
+	var peer enode.ID
+	return &peer, func() {
+		r.mu.Lock()
+		r.cleanups++
+		r.mu.Unlock()
+	}, nil
+}
+
+func (r *testRemoteGet) counts() (requests, cleanups int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.requests, r.cleanups
+}
+
+func newTestNetStore(t *testing.T) (*NetStore, *testRemoteGet) {
+	t.Helper()
+
+	ns := NewNetStore(NewMapChunkStore(), network.RandomBzzAddr())
+	r := newTestRemoteGet()
+	ns.RemoteGet = r.RemoteGet
+	return ns, r
+}
+
+// waitFetchersFreed waits for the netstore to drop all fetchers and in-flight requests.
+func waitFetchersFreed(t *testing.T, ns *NetStore) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		ns.requestsMu.Lock()
+		requests := len(ns.requests)
+		ns.requestsMu.Unlock()
+
+		if ns.fetchers.Len() == 0 && requests == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %v fetchers and %v requests, want none", ns.fetchers.Len(), requests)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestNetStoreGetCancel validates that cancelling the context of the only caller
+// of NetStore.Get stops the network fetch, cleans up the in-flight retrieve request
+// and frees the fetcher before the fetcher timeout.
+func TestNetStoreGetCancel(t *testing.T) {
+	ns, r := newTestNetStore(t)
+	ns.FetcherTimeout = time.Minute
+	ns.SearchTimeout = time.Minute
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-r.called
+		cancel()
+	}()
+
+	start := time.Now()
+	_, err := ns.Get(ctx, chunk.ModeGetRequest, NewRequest(GenerateRandomChunk(chunk.DefaultSize).Address()))
+	if err != context.Canceled {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Fatalf("get returned after %v", d)
+	}
+
+	waitFetchersFreed(t, ns)
+
+	requests, cleanups := r.counts()
+	if requests != 1 {
+		t.Errorf("got %v remote requests, want 1", requests)
+	}
+	if cleanups != requests {
+		t.Errorf("got %v cleanups, want %v", cleanups, requests)
+	}
+}
+
+// TestNetStoreGetSharedFetch validates that the cancellation of one caller does not
+// affect other callers waiting for the same chunk.
+func TestNetStoreGetSharedFetch(t *testing.T) {
+	ns, r := newTestNetStore(t)
+	ns.FetcherTimeout = time.Minute
+	ns.SearchTimeout = time.Minute
+
+	ch := GenerateRandomChunk(chunk.DefaultSize)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		_, err := ns.Get(ctx, chunk.ModeGetRequest, NewRequest(ch.Address()))
+		errc <- err
+	}()
+	<-r.called
+
+	type result struct {
+		ch  Chunk
+		err error
+	}
+	resultc := make(chan result, 1)
+	go func() {
+		got, err := ns.Get(context.Background(), chunk.ModeGetRequest, NewRequest(ch.Address()))
+		resultc <- result{got, err}
+	}()
+
+	// wait for the second caller to join the fetch
+	for {
+		ns.requestsMu.Lock()
+		waiters := ns.requests[ch.Address().String()].waiters
+		ns.requestsMu.Unlock()
+		if waiters == 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-errc; err != context.Canceled {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+
+	if _, err := ns.Put(context.Background(), chunk.ModePutRequest, ch); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case res := <-resultc:
+		if res.err != nil {
+			t.Fatal(res.err)
+		}
+		if !bytes.Equal(res.ch.Address(), ch.Address()) {
+			t.Errorf("got chunk %s, want %s", res.ch.Address(), ch.Address())
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for chunk")
+	}
+
+	waitFetchersFreed(t, ns)
+
+	requests, _ := r.counts()
+	if requests != 1 {
+		t.Errorf("got %v remote requests, want 1", requests)
+	}
+}
+
+// TestNetStoreGetSharedFetchLimits validates that a caller joining a shared fetch
+// with a later deadline and a higher hop limit than the caller that started it
+// extends the fetch, so that it does not fail when the first deadline passes.
+func TestNetStoreGetSharedFetchLimits(t *testing.T) {
+	ns := NewNetStore(NewMapChunkStore(), network.RandomBzzAddr())
+	ns.FetcherTimeout = time.Minute
+	ns.SearchTimeout = 50 * time.Millisecond
+
+	type limits struct {
+		hopLimit uint8
+		deadline time.Time
+	}
+	limitsc := make(chan limits, 100)
+	ns.RemoteGet = func(ctx context.Context, req *Request, localID enode.ID) (*enode.ID, func(), error) {
+		hopLimit, deadline := req.Limits()
+		limitsc <- limits{hopLimit, deadline}
+		// as retrieval, do not issue requests past the deadline
+		if !deadline.IsZero() && time.Until(deadline) <= 0 {
+			return nil, nil, context.DeadlineExceeded
+		}
+		var peer enode.ID
+		return &peer, func() {}, nil
+	}
+
+	ch := GenerateRandomChunk(chunk.DefaultSize)
+
+	shortDeadline := time.Now().Add(200 * time.Millisecond)
+	ctx, cancel := context.WithDeadline(context.Background(), shortDeadline)
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() {
+		req := NewRequest(ch.Address())
+		req.HopLimit = 1
+		req.Deadline = shortDeadline
+		_, err := ns.Get(ctx, chunk.ModeGetRequest, req)
+		errc <- err
+	}()
+	if l := <-limitsc; l.hopLimit != 1 || !l.deadline.Equal(shortDeadline) {
+		t.Fatalf("got first request limits %v %v, want %v %v", l.hopLimit, l.deadline, 1, shortDeadline)
+	}
+
+	type result struct {
+		ch  Chunk
+		err error
+	}
+	longDeadline := time.Now().Add(time.Minute)
+	resultc := make(chan result, 1)
+	go func() {
+		req := NewRequest(ch.Address())
+		req.Deadline = longDeadline
+		got, err := ns.Get(context.Background(), chunk.ModeGetRequest, req)
+		resultc <- result{got, err}
+	}()
+
+	// wait for the second caller to join the fetch
+	for {
+		ns.requestsMu.Lock()
+		waiters := ns.requests[ch.Address().String()].waiters
+		ns.requestsMu.Unlock()
+		if waiters == 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := <-errc; err != context.DeadlineExceeded {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// requests after the first deadline have the limits of the second caller
+	timeout := time.After(10 * time.Second)
+	for {
+		var l limits
+		select {
+		case l = <-limitsc:
+		case <-timeout:
+			t.Fatal("timeout waiting for remote request")
+		}
+		if time.Now().Before(shortDeadline) {
+			continue
+		}
+		if l.hopLimit != DefaultHopLimit || !l.deadline.Equal(longDeadline) {
+			t.Fatalf("got request limits %v %v, want %v %v", l.hopLimit, l.deadline, DefaultHopLimit, longDeadline)
+		}
+		break
+	}
+
+	if _, err := ns.Put(context.Background(), chunk.ModePutRequest, ch); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case res := <-resultc:
+		if res.err != nil {
+			t.Fatal(res.err)
+		}
+		if !bytes.Equal(res.ch.Address(), ch.Address()) {
+			t.Errorf("got chunk %s, want %s", res.ch.Address(), ch.Address())
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for chunk")
+	}
+
+	waitFetchersFreed(t, ns)
+}
+
+// TestNetStoreGetTimeouts validates that configured search and fetcher timeouts
+// are respected and that fetchers of undelivered chunks are freed.
+func TestNetStoreGetTimeouts(t *testing.T) {
+	ns, r := newTestNetStore(t)
+	ns.FetcherTimeout = 500 * time.Millisecond
+	ns.SearchTimeout = 100 * time.Millisecond
+
+	_, err := ns.Get(context.Background(), chunk.ModeGetRequest, NewRequest(GenerateRandomChunk(chunk.DefaultSize).Address()))
+	if err != context.DeadlineExceeded {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	waitFetchersFreed(t, ns)
+
+	requests, cleanups := r.counts()
+	if requests < 2 {
+		t.Errorf("got %v remote requests, want at least 2", requests)
+	}
+	if cleanups != requests {
+		t.Errorf("got %v cleanups, want %v", cleanups, requests)
+	}
+}
+
+// TestNetStoreReleaseFetcher validates that a fetcher is removed only when
+// all parties interested in it have released it.
+func TestNetStoreReleaseFetcher(t *testing.T) {
+	ns, _ := newTestNetStore(t)
+
+	addr := GenerateRandomChunk(chunk.DefaultSize).Address()
+
+	f1, _, _ := ns.GetOrCreateFetcher(context.Background(), addr, "syncer")
+	f2, _, _ := ns.GetOrCreateFetcher(context.Background(), addr, "request")
+	if f1 != f2 {
+		t.Fatal("got different fetchers for the same chunk")
+	}
+
+	ns.ReleaseFetcher(addr, f1)
+	if ns.fetchers.Len() != 1 {
+		t.Fatalf("got %v fetchers, want 1", ns.fetchers.Len())
+	}
+	ns.ReleaseFetcher(addr, f2)
+	if ns.fetchers.Len() != 0 {
+		t.Fatalf("got %v fetchers, want 0", ns.fetchers.Len())
+	}
+}
+
+// TestNetStoreGetRecover validates that the Recover function is called when
+// no peer can deliver the chunk and that the chunk put in the NetStore
+// in response is returned.
+func TestNetStoreGetRecover(t *testing.T) {
+	ns := NewNetStore(NewMapChunkStore(), network.RandomBzzAddr())
+	ns.FetcherTimeout = 10 * time.Second
+	ns.RemoteGet = func(ctx context.Context, req *Request, localID enode.ID) (*enode.ID, func(), error) {
+		return nil, nil, ErrNoSuitablePeer
+	}
+
+	ch := GenerateRandomChunk(chunk.DefaultSize)
+
+	if _, err := ns.Get(context.Background(), chunk.ModeGetRequest, NewRequest(ch.Address())); err != ErrNoSuitablePeer {
+		t.Fatalf("got error %v, want %v", err, ErrNoSuitablePeer)
+	}
+
+	var recovered []Address
+	ns.Recover = func(ctx context.Context, addr Address) error {
+		recovered = append(recovered, addr)
+		go func() {
+			if _, err := ns.Put(context.Background(), chunk.ModePutUpload, ch); err != nil {
+				t.Error(err)
+			}
+		}()
+		return nil
+	}
+
+	got, err := ns.Get(context.Background(), chunk.ModeGetRequest, NewRequest(ch.Address()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Data(), ch.Data()) {
+		t.Error("got wrong chunk data")
+	}
+	if len(recovered) != 1 || !bytes.Equal(recovered[0], ch.Address()) {
+		t.Errorf("got recovered addresses %v, want %v", recovered, ch.Address())
+	}
+
+	waitFetchersFreed(t, ns)
+}
@@ -28,7 +28,7 @@ import (
 	"github.com/ethersphere/swarm/log"
 	"github.com/ethersphere/swarm/state"
 	"github.com/ethersphere/swarm/storage"
-	"github.com/ethersphere/swarm/storage/localstore"
+	"github.com/ethersphere/swarm/storage/traversal"
 )
 
 const (
@@ -80,16 +80,17 @@ func (f *PinInfo) UnmarshalBinary(data []byte) error {
 
 // API is the main object which implements all things pinning.
 type API struct {
-	db         *localstore.DB
+	db         chunk.Store
 	api        *api.API
 	fileParams *storage.FileStoreParams
 	tag        *chunk.Tags
 	hashSize   int
 	state      state.Store // the state store used to store info about pinned files
+	traversal  *traversal.Service
 }
 
 // NewAPI creates a API object that is required for pinning and unpinning
-func NewAPI(lstore *localstore.DB, stateStore state.Store, params *storage.FileStoreParams, tags *chunk.Tags, api *api.API) *API {
+func NewAPI(lstore chunk.Store, stateStore state.Store, params *storage.FileStoreParams, tags *chunk.Tags, api *api.API) *API {
 	hashFunc := storage.MakeHashFunc(storage.DefaultHash)
 	return &API{
 		db:         lstore,
@@ -98,6 +99,7 @@ func NewAPI(lstore *localstore.DB, stateStore state.Store, params *storage.FileS
 		tag:        tags,
 		hashSize:   hashFunc().Size(),
 		state:      stateStore,
+		traversal:  traversal.New(lstore, nil),
 	}
 }
 
@@ -271,6 +273,18 @@ func (p *API) ListPins() ([]PinInfo, error) {
 func (p *API) walkChunksFromRootHash(addr []byte, isRaw bool, credentials string,
 	executeFunc func(storage.Reference) error) error {
 
+	if credentials == "" {
+		// Content without access control is walked with the traversal
+		// service, which includes sub-manifests and their chunks too.
+		fn := func(addr chunk.Address) error {
+			return executeFunc(storage.Reference(addr))
+		}
+		if isRaw {
+			return p.traversal.TraverseFileAddresses(context.Background(), addr, fn)
+		}
+		return p.traversal.TraverseManifestAddresses(context.Background(), addr, fn)
+	}
+
 	fileHashesC := make(chan storage.Reference, WorkerChanSize)
 	fileErrC := make(chan error)
 	var fwg sync.WaitGroup // wait group for file walker reoutine to complete
@@ -25,13 +25,21 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/enode"
 )
 
+// DefaultHopLimit is the max number of hops of requests without
+// a hop limit. Requests are forwarded to peers closer to the chunk, so the
+// limit is not expected to be reached.
+const DefaultHopLimit = 32
+
 // Request encapsulates all the necessary arguments when making a request to NetStore.
 // These could have also been added as part of the interface of NetStore.Get, but a request struct seemed
 // like a better option
 type Request struct {
-	Addr        Address  // chunk address
-	Origin      enode.ID // who is sending us that request? we compare Origin to the suggested peer from RequestFromPeers
-	PeersToSkip sync.Map // peers not to request chunk from
+	Addr        Address      // chunk address
+	Origin      enode.ID     // who is sending us that request? we compare Origin to the suggested peer from RequestFromPeers
+	PeersToSkip sync.Map     // peers not to request chunk from
+	HopLimit    uint8        // max number of hops the request travels through the network, 0 for the default
+	Deadline    time.Time    // time after which the chunk is not needed anymore, zero for no deadline
+	limitsMu    sync.RWMutex // protects HopLimit and Deadline once the request is shared by a network fetch
 }
 
 // NewRequest returns a new instance of Request based on chunk address skip check and
@@ -42,6 +50,38 @@ func NewRequest(addr Address) *Request {
 	}
 }
 
+// Limits returns the hop limit and the deadline of the request. The request
+// passed to RemoteGetFunc is shared by all callers of NetStore.Get waiting for
+// the same chunk, and its limits must be read with this method.
+func (r *Request) Limits() (hopLimit uint8, deadline time.Time) {
+	r.limitsMu.RLock()
+	defer r.limitsMu.RUnlock()
+
+	return r.HopLimit, r.Deadline
+}
+
+// extendLimits raises the hop limit and postpones the deadline of the request
+// if the provided ones are less restrictive, so that the request satisfies
+// another caller with these limits.
+func (r *Request) extendLimits(hopLimit uint8, deadline time.Time) {
+	r.limitsMu.Lock()
+	defer r.limitsMu.Unlock()
+
+	if hopLimit == 0 {
+		hopLimit = DefaultHopLimit
+	}
+	current := r.HopLimit
+	if current == 0 {
+		current = DefaultHopLimit
+	}
+	if hopLimit > current {
+		r.HopLimit = hopLimit
+	}
+	if !r.Deadline.IsZero() && (deadline.IsZero() || deadline.After(r.Deadline)) {
+		r.Deadline = deadline
+	}
+}
+
 // SkipPeer returns if the peer with nodeID should not be requested to deliver a chunk.
 // Peers to skip are kept per Request and for a time period of FailedPeerSkipDelay.
 func (r *Request) SkipPeer(nodeID string) bool {
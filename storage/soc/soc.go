@@ -0,0 +1,185 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package soc implements single-owner chunks.
+//
+// A single-owner chunk wraps a content addressed chunk together with an
+// identifier and the signature of its owner. Its address is derived from the
+// identifier and the owner's ethereum address, not from the content:
+//
+//	address = H(id|owner)
+//
+// so that every owner has a separate address space in which the content at an
+// address can only be set by the owner. The chunk data is:
+//
+//	id|signature|span|payload
+//
+// where span and payload form the wrapped content addressed chunk and the
+// signature is the owner's signature of H(id|wrapped chunk address).
+package soc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/storage"
+	"github.com/ethersphere/swarm/storage/feed"
+)
+
+const (
+	// IDSize is the length of the single-owner chunk identifier.
+	IDSize = 32
+	// SignatureSize is the length of the owner's signature.
+	SignatureSize = 65
+	// SpanSize is the length of the span of the wrapped chunk.
+	SpanSize = 8
+	// MaxPayloadSize is the maximal length of the single-owner chunk payload.
+	MaxPayloadSize = chunk.DefaultSize
+	// MaxChunkSize is the maximal length of the single-owner chunk data.
+	MaxChunkSize = minSize + MaxPayloadSize
+
+	headerSize = IDSize + SignatureSize
+	minSize    = headerSize + SpanSize
+)
+
+var (
+	// ErrInvalidID is returned when the identifier does not have IDSize length.
+	ErrInvalidID = errors.New("invalid single-owner chunk id")
+	// ErrInvalidSize is returned when chunk data or payload length is out of range.
+	ErrInvalidSize = errors.New("invalid single-owner chunk size")
+	// ErrInvalidAddress is returned when the chunk address is not derived
+	// from the identifier and the owner that signed the chunk.
+	ErrInvalidAddress = errors.New("invalid single-owner chunk address")
+)
+
+// hashFunc is the hash function of the wrapped content addressed chunk.
+var hashFunc = storage.MakeHashFunc(storage.DefaultHash)
+
+// SOC is a parsed single-owner chunk.
+type SOC struct {
+	ID        []byte
+	Owner     common.Address
+	Signature []byte
+	Span      uint64
+	Payload   []byte
+}
+
+// Address returns the single-owner chunk address for the identifier
+// and the owner.
+func Address(id []byte, owner common.Address) chunk.Address {
+	return crypto.Keccak256(id, owner.Bytes())
+}
+
+// NewChunk creates a single-owner chunk with the identifier and payload,
+// signed by the signer.
+func NewChunk(id, payload []byte, signer feed.Signer) (chunk.Chunk, error) {
+	if len(id) != IDSize {
+		return nil, ErrInvalidID
+	}
+	if len(payload) > MaxPayloadSize {
+		return nil, ErrInvalidSize
+	}
+	data := make([]byte, minSize+len(payload))
+	copy(data, id)
+	binary.LittleEndian.PutUint64(data[headerSize:], uint64(len(payload)))
+	copy(data[minSize:], payload)
+
+	signature, err := signer.Sign(digest(id, wrappedAddress(data[headerSize:])))
+	if err != nil {
+		return nil, err
+	}
+	copy(data[IDSize:], signature[:])
+
+	return chunk.NewChunk(Address(id, signer.Address()), data), nil
+}
+
+// FromChunk parses the single-owner chunk, recovering the owner from the
+// signature. It returns ErrInvalidAddress if the chunk address does not match
+// the identifier and the recovered owner.
+func FromChunk(ch chunk.Chunk) (*SOC, error) {
+	s, err := parse(ch.Data())
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(ch.Address(), Address(s.ID, s.Owner)) {
+		return nil, ErrInvalidAddress
+	}
+	return s, nil
+}
+
+// FromData parses the single-owner chunk data, recovering the owner from the
+// signature, and returns the chunk with the address derived from them.
+func FromData(data []byte) (chunk.Chunk, *SOC, error) {
+	s, err := parse(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return chunk.NewChunk(Address(s.ID, s.Owner), data), s, nil
+}
+
+// parse decodes the chunk data and recovers the owner from the signature.
+func parse(data []byte) (*SOC, error) {
+	if l := len(data); l < minSize || l > MaxChunkSize {
+		return nil, ErrInvalidSize
+	}
+	id := data[:IDSize]
+	signature := data[IDSize:headerSize]
+	pub, err := crypto.SigToPub(digest(id, wrappedAddress(data[headerSize:])).Bytes(), signature)
+	if err != nil {
+		return nil, err
+	}
+	return &SOC{
+		ID:        id,
+		Owner:     crypto.PubkeyToAddress(*pub),
+		Signature: signature,
+		Span:      binary.LittleEndian.Uint64(data[headerSize:minSize]),
+		Payload:   data[minSize:],
+	}, nil
+}
+
+// wrappedAddress returns the content address of the wrapped chunk data.
+func wrappedAddress(data []byte) []byte {
+	hasher := hashFunc()
+	hasher.Reset()
+	hasher.SetSpanBytes(data[:SpanSize])
+	hasher.Write(data[SpanSize:])
+	return hasher.Sum(nil)
+}
+
+// digest returns the hash signed by the owner.
+func digest(id, addr []byte) common.Hash {
+	return crypto.Keccak256Hash(id, addr)
+}
+
+// Validator validates single-owner chunks.
+// It implements the chunk.Validator interface.
+type Validator struct{}
+
+// NewValidator returns a new single-owner chunk Validator.
+func NewValidator() *Validator {
+	return &Validator{}
+}
+
+// Validate returns true if the chunk is a single-owner chunk
+// with a valid signature of the owner of its address.
+func (v *Validator) Validate(ch chunk.Chunk) bool {
+	_, err := FromChunk(ch)
+	return err == nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package soc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/storage/feed"
+)
+
+// TestSOC checks that a created single-owner chunk is valid,
+// that it can be parsed and that any modification is detected.
+func TestSOC(t *testing.T) {
+	privKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := feed.NewGenericSigner(privKey)
+
+	id := make([]byte, IDSize)
+	id[0] = 42
+	payload := []byte("single owner chunk payload")
+
+	ch, err := NewChunk(id, payload, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ch.Address(), Address(id, signer.Address())) {
+		t.Fatalf("got address %s, want %s", ch.Address(), Address(id, signer.Address()))
+	}
+
+	v := NewValidator()
+	if !v.Validate(ch) {
+		t.Fatal("valid chunk not validated")
+	}
+
+	s, err := FromChunk(ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Owner != signer.Address() {
+		t.Errorf("got owner %s, want %s", s.Owner.Hex(), signer.Address().Hex())
+	}
+	if !bytes.Equal(s.ID, id) {
+		t.Errorf("got id %x, want %x", s.ID, id)
+	}
+	if !bytes.Equal(s.Payload, payload) {
+		t.Errorf("got payload %q, want %q", s.Payload, payload)
+	}
+	if s.Span != uint64(len(payload)) {
+		t.Errorf("got span %v, want %v", s.Span, len(payload))
+	}
+
+	dataCh, _, err := FromData(ch.Data())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dataCh.Address(), ch.Address()) {
+		t.Errorf("got address from data %s, want %s", dataCh.Address(), ch.Address())
+	}
+
+	// modified payload changes the recovered owner
+	data := append([]byte(nil), ch.Data()...)
+	data[len(data)-1]++
+	if v.Validate(chunk.NewChunk(ch.Address(), data)) {
+		t.Error("chunk with modified payload validated")
+	}
+
+	// the same identifier of another owner has a different address
+	otherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewChunk(id, payload, feed.NewGenericSigner(otherKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Validate(chunk.NewChunk(ch.Address(), other.Data())) {
+		t.Error("chunk signed by another owner validated")
+	}
+
+	// content addressed chunks are not single-owner chunks
+	if v.Validate(chunk.NewChunk(ch.Address(), ch.Data()[:minSize-1])) {
+		t.Error("too short chunk validated")
+	}
+}
+
+// TestNewChunkInvalid checks that single-owner chunks are not
+// created with invalid identifier or payload.
+func TestNewChunkInvalid(t *testing.T) {
+	privKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := feed.NewGenericSigner(privKey)
+
+	if _, err := NewChunk(make([]byte, IDSize-1), nil, signer); err != ErrInvalidID {
+		t.Errorf("got error %v, want %v", err, ErrInvalidID)
+	}
+	if _, err := NewChunk(make([]byte, IDSize), make([]byte, MaxPayloadSize+1), signer); err != ErrInvalidSize {
+		t.Errorf("got error %v, want %v", err, ErrInvalidSize)
+	}
+}
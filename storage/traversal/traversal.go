@@ -0,0 +1,352 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package traversal walks all chunks that are referenced from a root
+// address. Swarm content is organised as chunk trees of files, manifests
+// whose entries reference other files, manifests or feeds, and feed updates
+// which may in turn reference other content. Features like pinning, export
+// and repair need the complete set of chunk addresses of such content, and
+// the Service in this package provides it in one place.
+package traversal
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/storage"
+	"github.com/ethersphere/swarm/storage/feed"
+	"github.com/ethersphere/swarm/storage/feed/lookup"
+)
+
+// Content types of manifest entries. They must match api.ManifestType and
+// api.FeedContentType, which can not be imported as the api package depends
+// on this package.
+const (
+	manifestType    = "application/bzz-manifest+json"
+	feedContentType = "application/bzz-feed"
+
+	manifestSizeLimit = 5 * 1024 * 1024
+
+	// maxDepth is the maximal number of nested manifests and feeds
+	// that are traversed from the root.
+	maxDepth = 32
+)
+
+var (
+	// ErrNoFeedHandler is returned when traversed content references a feed,
+	// but the Service was constructed without a feed handler.
+	ErrNoFeedHandler = errors.New("no feed handler")
+	// ErrMaxDepth is returned when traversed content nests manifests
+	// and feeds deeper than the traversal allows.
+	ErrMaxDepth = errors.New("maximum traversal depth exceeded")
+
+	errInvalidChunkData = errors.New("invalid chunk data")
+)
+
+// AddressFunc is called for every chunk address encountered during traversal.
+// If it returns an error, traversal stops and the error is returned.
+type AddressFunc func(addr chunk.Address) error
+
+// Service traverses content stored in a chunk store.
+type Service struct {
+	store    storage.ChunkStore
+	feeds    *feed.Handler
+	hashFunc storage.SwarmHasher
+	hashSize int
+}
+
+// New constructs a new traversal Service which retrieves chunks from the
+// provided store and resolves feeds with the feed handler. Feed handler may
+// be nil, in which case traversal of content referencing feeds fails with
+// ErrNoFeedHandler.
+func New(store storage.ChunkStore, feeds *feed.Handler) *Service {
+	hashFunc := storage.MakeHashFunc(storage.DefaultHash)
+	return &Service{
+		store:    store,
+		feeds:    feeds,
+		hashFunc: hashFunc,
+		hashSize: hashFunc().Size(),
+	}
+}
+
+// TraverseAddresses calls fn for addresses of all chunks that are referenced
+// from the root. If the root is a manifest, chunks of all its entries are
+// traversed too, recursively for sub-manifests, and for feed entries the
+// latest feed update chunk and the content it references are included.
+// Otherwise, root is traversed as a file. Addresses of encrypted content are
+// passed without decryption keys. The same address may be passed to fn more
+// than once if it is referenced from more than one place, but every manifest
+// and feed is traversed only once, so that references between them can not
+// form a cycle.
+func (s *Service) TraverseAddresses(ctx context.Context, root storage.Address, fn AddressFunc) error {
+	return s.traverse(ctx, newWalk(fn), root, 0)
+}
+
+// TraverseFileAddresses calls fn for addresses of all chunks in the chunk
+// tree of a single file, without interpreting its content.
+func (s *Service) TraverseFileAddresses(ctx context.Context, root storage.Address, fn AddressFunc) error {
+	return s.traverseFile(ctx, root, fn)
+}
+
+// TraverseManifestAddresses is the same as TraverseAddresses, but it
+// returns an error if the root is not a manifest.
+func (s *Service) TraverseManifestAddresses(ctx context.Context, root storage.Address, fn AddressFunc) error {
+	return s.traverseManifest(ctx, newWalk(fn), root, 0, true)
+}
+
+// walk holds the state of a single traversal.
+type walk struct {
+	fn      AddressFunc
+	visited map[string]struct{} // roots of traversed manifests and feeds
+}
+
+func newWalk(fn AddressFunc) *walk {
+	return &walk{
+		fn:      fn,
+		visited: make(map[string]struct{}),
+	}
+}
+
+// visit marks the key as visited and reports
+// if it was not visited before.
+func (w *walk) visit(key string) bool {
+	if _, ok := w.visited[key]; ok {
+		return false
+	}
+	w.visited[key] = struct{}{}
+	return true
+}
+
+// traverse traverses the root as a manifest if it looks like a manifest,
+// otherwise as a file.
+func (s *Service) traverse(ctx context.Context, w *walk, root storage.Address, depth int) error {
+	return s.traverseManifest(ctx, w, root, depth, false)
+}
+
+// traverseManifest traverses the chunks of the manifest and all content
+// referenced by its entries, if the root was not already traversed. If strict
+// is false and the root is not a manifest, only its chunks are traversed.
+func (s *Service) traverseManifest(ctx context.Context, w *walk, root storage.Address, depth int, strict bool) error {
+	if depth > maxDepth {
+		return ErrMaxDepth
+	}
+	if !w.visit("root:" + root.Hex()) {
+		return nil
+	}
+	m, err := s.readManifest(ctx, root, strict)
+	if err != nil {
+		return err
+	}
+	if err := s.traverseFile(ctx, root, w.fn); err != nil {
+		return err
+	}
+	if m == nil {
+		return nil
+	}
+	return s.traverseEntries(ctx, w, m, depth)
+}
+
+// manifest is a minimal representation of api.Manifest that is sufficient
+// for traversal.
+type manifest struct {
+	Entries []manifestEntry `json:"entries,omitempty"`
+}
+
+// manifestEntry has all fields of api.ManifestEntry so that decoding with
+// disallowed unknown fields can be used to detect manifests.
+type manifestEntry struct {
+	Hash        string          `json:"hash,omitempty"`
+	Path        string          `json:"path,omitempty"`
+	ContentType string          `json:"contentType,omitempty"`
+	Mode        int64           `json:"mode,omitempty"`
+	Size        int64           `json:"size,omitempty"`
+	ModTime     time.Time       `json:"mod_time,omitempty"`
+	Status      int             `json:"status,omitempty"`
+	Access      json.RawMessage `json:"access,omitempty"`
+	Feed        *feed.Feed      `json:"feed,omitempty"`
+}
+
+// traverseEntries traverses all content referenced by manifest entries.
+func (s *Service) traverseEntries(ctx context.Context, w *walk, m *manifest, depth int) error {
+	for _, e := range m.Entries {
+		if e.ContentType == feedContentType {
+			if err := s.traverseFeed(ctx, w, e.Feed, depth+1); err != nil {
+				return err
+			}
+			continue
+		}
+		ref, err := hex.DecodeString(e.Hash)
+		if err != nil {
+			return fmt.Errorf("manifest entry %q: invalid hash: %v", e.Path, err)
+		}
+		if len(ref) < s.hashSize {
+			return fmt.Errorf("manifest entry %q: invalid hash length %v", e.Path, len(ref))
+		}
+		switch {
+		case e.Access != nil:
+			// access controlled content can not be decrypted
+			// without credentials, only its root is known
+			if err := w.fn(chunk.Address(ref[:s.hashSize])); err != nil {
+				return err
+			}
+		case e.ContentType == manifestType:
+			if err := s.traverseManifest(ctx, w, ref, depth+1, true); err != nil {
+				return err
+			}
+		default:
+			if err := s.traverseFile(ctx, ref, w.fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// traverseFeed looks up the latest update of the feed and traverses its chunk
+// and the content referenced by it, if the update data is a swarm hash and
+// the feed was not already traversed.
+func (s *Service) traverseFeed(ctx context.Context, w *walk, f *feed.Feed, depth int) error {
+	if f == nil {
+		return errors.New("manifest feed entry without feed")
+	}
+	if s.feeds == nil {
+		return ErrNoFeedHandler
+	}
+	if depth > maxDepth {
+		return ErrMaxDepth
+	}
+	if !w.visit("feed:" + f.Hex()) {
+		return nil
+	}
+	if _, err := s.feeds.Lookup(ctx, feed.NewQueryLatest(f, lookup.NoClue)); err != nil {
+		return err
+	}
+	addr, data, err := s.feeds.GetContent(f)
+	if err != nil {
+		return err
+	}
+	if err := w.fn(addr); err != nil {
+		return err
+	}
+	if len(data) != storage.AddressLength {
+		// update data is the content itself
+		return nil
+	}
+	return s.traverse(ctx, w, storage.Address(data), depth+1)
+}
+
+// traverseFile calls fn for all chunks of the file chunk tree from
+// the root, parent chunks before their children.
+func (s *Service) traverseFile(ctx context.Context, root storage.Address, fn AddressFunc) error {
+	getter := s.getter(root)
+	refSize := len(root)
+
+	var walk func(ref storage.Reference) error
+	walk = func(ref storage.Reference) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		data, err := getter.Get(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("get chunk %x: %v", []byte(ref[:s.hashSize]), err)
+		}
+		if len(data) < 8 {
+			return errInvalidChunkData
+		}
+		if err := fn(chunk.Address(ref[:s.hashSize])); err != nil {
+			return err
+		}
+		if data.Size() <= chunk.DefaultSize {
+			// data chunk
+			return nil
+		}
+		for i := 8; i+refSize <= len(data); i += refSize {
+			if err := walk(storage.Reference(data[i : i+refSize])); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(storage.Reference(root))
+}
+
+// readManifest reads and decodes the manifest at the address. If strict is
+// false, nil manifest is returned for content that does not look like
+// a manifest, without reading it whole if it is not needed.
+func (s *Service) readManifest(ctx context.Context, addr storage.Address, strict bool) (*manifest, error) {
+	r := storage.TreeJoin(ctx, addr, s.getter(addr), 0)
+	size, err := r.Size(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get chunk %x: %v", []byte(addr[:s.hashSize]), err)
+	}
+	if size == 0 || size > manifestSizeLimit {
+		if strict {
+			return nil, fmt.Errorf("manifest %x: invalid size %v", []byte(addr[:s.hashSize]), size)
+		}
+		return nil, nil
+	}
+	if !strict {
+		first := make([]byte, 1)
+		if _, err := r.ReadAt(first, 0); err != nil && err != io.EOF {
+			return nil, err
+		}
+		if first[0] != '{' {
+			return nil, nil
+		}
+	}
+	data := make([]byte, size)
+	n, err := r.ReadAt(data, 0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if int64(n) < size {
+		return nil, fmt.Errorf("manifest %x: read %v bytes of %v", []byte(addr[:s.hashSize]), n, size)
+	}
+
+	var m manifest
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if !strict {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(&m); err != nil {
+		if strict {
+			return nil, fmt.Errorf("manifest %x: %v", []byte(addr[:s.hashSize]), err)
+		}
+		return nil, nil
+	}
+	if !strict {
+		for _, e := range m.Entries {
+			if e.Hash == "" && e.Feed == nil {
+				return nil, nil
+			}
+		}
+	}
+	return &m, nil
+}
+
+// getter returns a storage.Getter which decrypts chunks if the reference
+// contains the encryption key.
+func (s *Service) getter(ref storage.Address) storage.Getter {
+	isEncrypted := len(ref) > s.hashSize
+	return storage.NewHasherStore(s.store, s.hashFunc, isEncrypted, chunk.NewTag(0, "traversal-tag", 0, false))
+}
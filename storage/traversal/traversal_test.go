@@ -0,0 +1,364 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package traversal_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethersphere/swarm/api"
+	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/storage"
+	"github.com/ethersphere/swarm/storage/feed"
+	"github.com/ethersphere/swarm/storage/localstore"
+	"github.com/ethersphere/swarm/storage/traversal"
+	"github.com/ethersphere/swarm/testutil"
+)
+
+// TestTraverseFileAddresses validates that all chunks of raw files
+// are traversed, for both encrypted and unencrypted content.
+func TestTraverseFileAddresses(t *testing.T) {
+	for _, toEncrypt := range []bool{false, true} {
+		for _, size := range []int{1, chunk.DefaultSize, chunk.DefaultSize + 1, 1000000} {
+			te := newTestEnv(t)
+
+			data := testutil.RandomBytes(size, size)
+			addr := te.upload(t, data, toEncrypt)
+
+			// database contains only chunks of the uploaded file
+			want := te.allChunks(t)
+
+			got := te.traverse(t, te.service.TraverseFileAddresses, addr)
+			checkAddresses(t, got, want)
+
+			// raw files are detected as files
+			got = te.traverse(t, te.service.TraverseAddresses, addr)
+			checkAddresses(t, got, want)
+
+			te.close()
+		}
+	}
+}
+
+// TestTraverseManifest validates that chunks of the manifest, its
+// sub-manifests and all files referenced by it are traversed.
+func TestTraverseManifest(t *testing.T) {
+	for _, toEncrypt := range []bool{false, true} {
+		te := newTestEnv(t)
+
+		emptyManifest, err := te.api.NewManifest(context.Background(), toEncrypt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths := []string{"file1.txt", "file2.txt", "dir1/file3.txt", "dir1/file4.txt", "dir2/dir3/file5.txt", "dir2/dir4/file6.txt"}
+		root, err := te.api.UpdateManifest(context.Background(), emptyManifest, func(mw *api.ManifestWriter) error {
+			for i, p := range paths {
+				hash := te.upload(t, testutil.RandomBytes(i, 10000+i*chunk.DefaultSize), toEncrypt)
+				_, err := mw.AddEntry(context.Background(), nil, &api.ManifestEntry{
+					Hash:        hash.Hex(),
+					Path:        p,
+					ContentType: "text/plain",
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := te.allChunks(t)
+		// the initial empty manifest is not referenced from the root
+		delete(want, hex.EncodeToString(emptyManifest[:chunk.AddressLength]))
+
+		got := te.traverse(t, te.service.TraverseAddresses, root)
+		checkAddresses(t, got, want)
+
+		got = te.traverse(t, te.service.TraverseManifestAddresses, root)
+		checkAddresses(t, got, want)
+
+		te.close()
+	}
+}
+
+// TestTraverseManifestNotManifest validates that TraverseManifestAddresses
+// returns an error if the root is not a manifest.
+func TestTraverseManifestNotManifest(t *testing.T) {
+	te := newTestEnv(t)
+	defer te.close()
+
+	addr := te.upload(t, []byte("not a manifest"), false)
+
+	err := te.service.TraverseManifestAddresses(context.Background(), addr, func(chunk.Address) error { return nil })
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+// TestTraverseFeed validates that the latest feed update chunk and
+// the content it references are traversed from the feed manifest.
+func TestTraverseFeed(t *testing.T) {
+	te := newTestEnv(t)
+	defer te.close()
+
+	content := te.upload(t, testutil.RandomBytes(1, 3*chunk.DefaultSize), false)
+
+	privKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topic, err := feed.NewTopic("traversal", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := feed.NewFirstRequest(topic)
+	request.SetData(content)
+	if err := request.Sign(feed.NewGenericSigner(privKey)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := te.feeds.Update(context.Background(), request); err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := te.api.NewFeedManifest(context.Background(), &request.Feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := te.allChunks(t)
+
+	got := te.traverse(t, te.service.TraverseAddresses, root)
+	checkAddresses(t, got, want)
+
+	// without feed handler, feeds can not be traversed
+	err = traversal.New(te.db, nil).TraverseAddresses(context.Background(), root, func(chunk.Address) error { return nil })
+	if err != traversal.ErrNoFeedHandler {
+		t.Fatalf("got error %v, want %v", err, traversal.ErrNoFeedHandler)
+	}
+}
+
+// TestTraverseFeedCycle validates that traversal of a feed whose latest
+// update references its own feed manifest terminates.
+func TestTraverseFeedCycle(t *testing.T) {
+	te := newTestEnv(t)
+	defer te.close()
+
+	privKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topic, err := feed.NewTopic("traversal cycle", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := feed.NewFirstRequest(topic)
+	// user is set on signing, but it is required by the manifest before that
+	request.Feed.User = crypto.PubkeyToAddress(privKey.PublicKey)
+
+	root, err := te.api.NewFeedManifest(context.Background(), &request.Feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request.SetData(root)
+	if err := request.Sign(feed.NewGenericSigner(privKey)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := te.feeds.Update(context.Background(), request); err != nil {
+		t.Fatal(err)
+	}
+
+	want := te.allChunks(t)
+
+	got := te.traverse(t, te.service.TraverseAddresses, root)
+	checkAddresses(t, got, want)
+}
+
+// TestTraverseMaxDepth validates that traversal of manifests nested
+// deeper than allowed returns ErrMaxDepth.
+func TestTraverseMaxDepth(t *testing.T) {
+	te := newTestEnv(t)
+	defer te.close()
+
+	root := te.upload(t, []byte("data"), false)
+	for i := 0; i < 40; i++ {
+		m := fmt.Sprintf(`{"entries":[{"hash":"%s","contentType":"%s"}]}`, root.Hex(), api.ManifestType)
+		root = te.upload(t, []byte(m), false)
+	}
+
+	err := te.service.TraverseAddresses(context.Background(), root, func(chunk.Address) error { return nil })
+	if err != traversal.ErrMaxDepth {
+		t.Fatalf("got error %v, want %v", err, traversal.ErrMaxDepth)
+	}
+}
+
+// TestTraverseAddressesErrors validates that errors from the callback
+// and missing chunks stop the traversal.
+func TestTraverseAddressesErrors(t *testing.T) {
+	te := newTestEnv(t)
+	defer te.close()
+
+	addr := te.upload(t, testutil.RandomBytes(1, 10*chunk.DefaultSize), false)
+
+	errTest := errors.New("test error")
+	var count int
+	err := te.service.TraverseAddresses(context.Background(), addr, func(chunk.Address) error {
+		count++
+		if count == 3 {
+			return errTest
+		}
+		return nil
+	})
+	if err != errTest {
+		t.Fatalf("got error %v, want %v", err, errTest)
+	}
+	if count != 3 {
+		t.Fatalf("got %v callbacks, want 3", count)
+	}
+
+	missing := storage.GenerateRandomChunk(chunk.DefaultSize).Address()
+	err = te.service.TraverseAddresses(context.Background(), missing, func(chunk.Address) error { return nil })
+	if err == nil {
+		t.Fatal("expected error for missing root chunk")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = te.service.TraverseFileAddresses(ctx, addr, func(chunk.Address) error { return nil })
+	if err != context.Canceled {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+}
+
+type testEnv struct {
+	dir       string
+	db        *localstore.DB
+	fileStore *storage.FileStore
+	feeds     *feed.TestHandler
+	api       *api.API
+	service   *traversal.Service
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "swarm-traversal-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := localstore.New(dir, make([]byte, 32), nil)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	tags := chunk.NewTags()
+	fileStore := storage.NewFileStore(db, db, storage.NewFileStoreParams(), tags)
+	feeds, err := feed.NewTestHandlerWithStore(dir, db, &feed.HandlerParams{})
+	if err != nil {
+		db.Close()
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return &testEnv{
+		dir:       dir,
+		db:        db,
+		fileStore: fileStore,
+		feeds:     feeds,
+		api:       api.NewAPI(fileStore, nil, nil, feeds.Handler, nil, tags),
+		service:   traversal.New(db, feeds.Handler),
+	}
+}
+
+func (te *testEnv) close() {
+	te.db.Close()
+	os.RemoveAll(te.dir)
+}
+
+func (te *testEnv) upload(t *testing.T, data []byte, toEncrypt bool) storage.Address {
+	t.Helper()
+
+	ctx := context.Background()
+	addr, wait, err := te.fileStore.Store(ctx, bytes.NewReader(data), int64(len(data)), toEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
+
+func (te *testEnv) traverse(t *testing.T, f func(context.Context, storage.Address, traversal.AddressFunc) error, root storage.Address) map[string]struct{} {
+	t.Helper()
+
+	got := make(map[string]struct{})
+	err := f(context.Background(), root, func(addr chunk.Address) error {
+		if len(addr) != chunk.AddressLength {
+			t.Errorf("got address %x of length %v", addr, len(addr))
+		}
+		got[hex.EncodeToString(addr)] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+// allChunks returns addresses of all chunks in the database.
+func (te *testEnv) allChunks(t *testing.T) map[string]struct{} {
+	t.Helper()
+
+	addrs := make(map[string]struct{})
+	for bin := uint8(0); bin <= uint8(chunk.MaxPO); bin++ {
+		lastID, err := te.db.LastPullSubscriptionBinID(bin)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lastID == 0 {
+			continue
+		}
+		c, stop := te.db.SubscribePull(context.Background(), bin, 0, lastID)
+		for d := range c {
+			addrs[hex.EncodeToString(d.Address)] = struct{}{}
+		}
+		stop()
+	}
+	return addrs
+}
+
+func checkAddresses(t *testing.T, got, want map[string]struct{}) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Errorf("got %v addresses, want %v", len(got), len(want))
+	}
+	for a := range want {
+		if _, ok := got[a]; !ok {
+			t.Errorf("address %s not traversed", a)
+		}
+	}
+}
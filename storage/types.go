@@ -23,6 +23,7 @@ import (
 	"crypto/rand"
 	"encoding/binary"
 	"io"
+	"sync"
 
 	"github.com/ethersphere/swarm/bmt"
 	"github.com/ethersphere/swarm/chunk"
@@ -53,17 +54,31 @@ func MakeHashFunc(hash string) SwarmHasher {
 	case "SHA3":
 		return func() SwarmHash { return &HashWithLength{sha3.NewLegacyKeccak256()} }
 	case "BMT":
+		pool := bmtTreePool()
 		return func() SwarmHash {
-			hasher := sha3.NewLegacyKeccak256
-			hasherSize := hasher().Size()
-			segmentCount := chunk.DefaultSize / hasherSize
-			pool := bmt.NewTreePool(hasher, segmentCount, bmt.PoolSize)
 			return bmt.New(pool)
 		}
 	}
 	return nil
 }
 
+var (
+	bmtTreePoolOnce   sync.Once
+	bmtTreePoolShared *bmt.TreePool
+)
+
+// bmtTreePool returns a BMT tree pool shared by all BMT hashers
+// so that trees are reused instead of constructed for every hasher.
+func bmtTreePool() *bmt.TreePool {
+	bmtTreePoolOnce.Do(func() {
+		hasher := sha3.NewLegacyKeccak256
+		hasherSize := hasher().Size()
+		segmentCount := chunk.DefaultSize / hasherSize
+		bmtTreePoolShared = bmt.NewSharedTreePool(hasher, segmentCount)
+	})
+	return bmtTreePoolShared
+}
+
 type AddressCollection []Address
 
 func NewAddressCollection(l int) AddressCollection {
@@ -19,6 +19,7 @@ package swarm
 import (
 	"bytes"
 	"crypto/ecdsa"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -38,10 +39,12 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethersphere/swarm/api"
 	httpapi "github.com/ethersphere/swarm/api/http"
+	"github.com/ethersphere/swarm/api/http/auth"
 	"github.com/ethersphere/swarm/bzzeth"
 	"github.com/ethersphere/swarm/chunk"
 	"github.com/ethersphere/swarm/contracts/ens"
 	"github.com/ethersphere/swarm/fuse"
+	"github.com/ethersphere/swarm/internal/debug"
 	"github.com/ethersphere/swarm/log"
 	"github.com/ethersphere/swarm/network"
 	"github.com/ethersphere/swarm/network/retrieval"
@@ -50,18 +53,29 @@ import (
 	"github.com/ethersphere/swarm/pss"
 	pssmessage "github.com/ethersphere/swarm/pss/message"
 	"github.com/ethersphere/swarm/pushsync"
+	"github.com/ethersphere/swarm/recovery"
 	"github.com/ethersphere/swarm/state"
 	"github.com/ethersphere/swarm/storage"
 	"github.com/ethersphere/swarm/storage/feed"
 	"github.com/ethersphere/swarm/storage/localstore"
 	"github.com/ethersphere/swarm/storage/mock"
 	"github.com/ethersphere/swarm/storage/pin"
+	"github.com/ethersphere/swarm/storage/soc"
 	"github.com/ethersphere/swarm/swap"
 	"github.com/ethersphere/swarm/tracing"
 	rnsconfig "github.com/rnsdomains/rns-go-lib/config"
 	rnsresolver "github.com/rnsdomains/rns-go-lib/resolver"
 )
 
+// proxyCacheGCTargetRatio is the ratio of proxy cache capacity that is left
+// after its garbage collection. It is lower than the default localstore ratio
+// as cached chunks are not expected to be accessed again as often.
+const proxyCacheGCTargetRatio = 0.5
+
+// recoveryMessageTTL is the expire duration of chunk recovery pss messages.
+// Recovery is useful only before the retrieval fetcher times out.
+const recoveryMessageTTL = 20 * time.Second
+
 var (
 	updateGaugesPeriod = 5 * time.Second
 	startCounter       = metrics.NewRegisteredCounter("stack/start", nil)
@@ -82,16 +96,20 @@ type Swarm struct {
 	bzzEth            *bzzeth.BzzEth
 	privateKey        *ecdsa.PrivateKey
 	netStore          *storage.NetStore
+	localStore        *localstore.DB
 	sfs               *fuse.SwarmFS // need this to cleanup all the active mounts on node exit
 	ps                *pss.Pss
 	pushSync          *pushsync.Pusher
 	storer            *pushsync.Storer
+	recovery          *recovery.Recovery
+	repairer          *recovery.Repairer
 	swap              *swap.Swap
 	stateStore        *state.DBStore
 	tags              *chunk.Tags
 	accountingMetrics *protocols.AccountingMetrics
 	cleanupFuncs      []func() error
-	pinAPI            *pin.API // API object implements all pinning related commands
+	pinAPI            *pin.API    // API object implements all pinning related commands
+	apiKeys           *auth.Store // HTTP API keys, nil if not enabled
 	inspector         *api.Inspector
 
 	tracerClose io.Closer
@@ -224,21 +242,44 @@ func NewSwarm(config *api.Config, mockStore *mock.NodeStore) (self *Swarm, err e
 	)
 
 	localStore, err := localstore.New(config.ChunkDbPath, config.BaseKey, &localstore.Options{
-		MockStore:    mockStore,
-		Capacity:     config.DbCapacity,
-		Tags:         self.tags,
-		PutToGCCheck: to.IsWithinDepth,
+		MockStore:                mockStore,
+		Capacity:                 config.DbCapacity,
+		Tags:                     self.tags,
+		PutToGCCheck:             to.IsWithinDepth,
+		ResponsibilityDepth:      to.NeighbourhoodDepth,
+		SlowStart:                config.SlowStart,
+		MinFreeDiskSpace:         config.MinFreeSpace,
+		ProximityMetricsInterval: config.ProximityMetricsInterval,
 	})
 	if err != nil {
 		return nil, err
 	}
+	self.localStore = localStore
+	var chunkStore chunk.Store = localStore
+	if config.ProxyCacheCapacity > 0 {
+		// chunks retrieved outside of the area of responsibility
+		// are kept in a separate store with its own garbage collection
+		proxyCache, err := localstore.New(config.ProxyCacheDbPath, config.BaseKey, &localstore.Options{
+			Capacity:                 config.ProxyCacheCapacity,
+			GCTargetRatio:            proxyCacheGCTargetRatio,
+			MetricsPrefix:            "proxycache/",
+			ProximityMetricsInterval: config.ProximityMetricsInterval,
+		})
+		if err != nil {
+			return nil, err
+		}
+		chunkStore = storage.NewCacheTier(localStore, proxyCache, to.IsWithinDepth)
+	}
 	lstore := chunk.NewValidatorStore(
-		localStore,
+		chunkStore,
 		storage.NewContentAddressValidator(storage.MakeHashFunc(storage.DefaultHash)),
 		feedsHandler,
+		soc.NewValidator(),
 	)
 
 	self.netStore = storage.NewNetStore(lstore, bzzconfig.Address)
+	self.netStore.FetcherTimeout = config.FetcherTimeout
+	self.netStore.SearchTimeout = config.SearchTimeout
 	self.retrieval = retrieval.New(to, self.netStore, bzzconfig.Address, self.swap)
 	self.netStore.RemoteGet = self.retrieval.RequestFromPeers
 
@@ -258,7 +299,7 @@ func NewSwarm(config *api.Config, mockStore *mock.NodeStore) (self *Swarm, err e
 
 	log.Debug("Setup local storage")
 	self.bzz = network.NewBzz(bzzconfig, to, self.stateStore, stream.Spec, self.retrieval.Spec(), self.streamer.Run, self.retrieval.Run)
-	self.bzzEth = bzzeth.New(self.netStore, to)
+	self.bzzEth = bzzeth.New(self.netStore, to, bzzeth.NewHeaderStore(localStore))
 
 	// Pss = postal service over swarm (devp2p over bzz)
 	self.ps, err = pss.New(to, config.Pss)
@@ -273,18 +314,49 @@ func NewSwarm(config *api.Config, mockStore *mock.NodeStore) (self *Swarm, err e
 		// expire time for push-sync messages should be lower than regular chat-like messages to avoid network flooding
 		pubsub := pss.NewPubSub(self.ps, 20*time.Second)
 		self.pushSync = pushsync.NewPusher(localStore, pubsub, self.tags)
-		self.storer = pushsync.NewStorer(self.netStore, pubsub)
+		// light nodes do not store chunks pushed to their neighbourhood
+		if !config.LightNodeEnabled {
+			self.storer = pushsync.NewStorer(self.netStore, pubsub)
+		}
+	}
+
+	if config.RecoveryEnabled || config.RepairEnabled {
+		pubsub := pss.NewPubSub(self.ps, recoveryMessageTTL)
+		if config.RecoveryEnabled {
+			targets := make([][]byte, 0, len(config.RecoveryTargets))
+			for _, t := range config.RecoveryTargets {
+				target, err := hex.DecodeString(strings.TrimPrefix(t, "0x"))
+				if err != nil {
+					return nil, fmt.Errorf("invalid recovery target %q: %v", t, err)
+				}
+				targets = append(targets, target)
+			}
+			self.recovery = recovery.New(self.netStore, pubsub, targets, self.privateKey)
+			self.netStore.Recover = self.recovery.Recover
+		}
+		// repair nodes respond only with chunks stored locally
+		if config.RepairEnabled {
+			self.repairer = recovery.NewRepairer(chunkStore, pubsub)
+		}
 	}
 
 	self.api = api.NewAPI(self.fileStore, self.dns, self.rns, feedsHandler, self.privateKey, self.tags)
 
 	if config.EnablePinning {
-		// Instantiate the pinAPI object with the already opened localstore
-		self.pinAPI = pin.NewAPI(localStore, self.stateStore, self.config.FileStoreParams, self.tags, self.api)
+		// Instantiate the pinAPI object with the already opened localstore and proxy cache
+		self.pinAPI = pin.NewAPI(chunkStore, self.stateStore, self.config.FileStoreParams, self.tags, self.api)
+	}
+
+	if config.APIKeysEnabled {
+		self.apiKeys, err = auth.NewStore(filepath.Join(config.Path, "api-keys"), config.APIAdminKey)
+		if err != nil {
+			return nil, err
+		}
+		self.cleanupFuncs = append(self.cleanupFuncs, self.apiKeys.Close)
 	}
 	self.sfs = fuse.NewSwarmFS(self.api)
 	log.Debug("Initialized FUSE filesystem")
-	self.inspector = api.NewInspector(self.api, self.bzz.Hive, self.netStore, self.streamer, localStore)
+	self.inspector = api.NewInspector(self.api, self.bzz.Hive, self.netStore, self.streamer, localStore, self.swap)
 
 	return self, nil
 }
@@ -390,7 +462,12 @@ func (s *Swarm) Start(srv *p2p.Server) error {
 	// start swarm http proxy server
 	if s.config.Port != "" {
 		addr := net.JoinHostPort(s.config.ListenAddr, s.config.Port)
-		server := httpapi.NewServer(s.api, s.pinAPI, s.config.Cors)
+		server := httpapi.NewServer(s.api, s.pinAPI, s.apiKeys, s.config.Cors)
+		server.SetCacheOptions(httpapi.CacheOptions{
+			MaxAge:    s.config.HTTPCacheMaxAge,
+			ENSMaxAge: s.config.HTTPCacheENSMaxAge,
+		})
+		server.SetInspector(s.inspector)
 
 		if s.config.Cors != "" {
 			log.Info("Swarm HTTP proxy CORS headers", "allowedOrigins", s.config.Cors)
@@ -481,6 +558,12 @@ func (s *Swarm) Stop() error {
 	if s.storer != nil {
 		s.storer.Close()
 	}
+	if s.recovery != nil {
+		s.recovery.Close()
+	}
+	if s.repairer != nil {
+		s.repairer.Close()
+	}
 
 	if s.netStore != nil {
 		s.netStore.Close()
@@ -544,6 +627,18 @@ func (s *Swarm) APIs() []rpc.API {
 			Service:   s.inspector,
 			Public:    false,
 		},
+		{
+			Namespace: "debug",
+			Version:   "1.0",
+			Service:   localstore.NewDebugAPI(s.localStore),
+			Public:    false,
+		},
+		{
+			Namespace: "log",
+			Version:   "1.0",
+			Service:   debug.NewLogAPI(),
+			Public:    false,
+		},
 		{
 			Namespace: "swarmfs",
 			Version:   fuse.SwarmFSVersion,